@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+)
+
+// maxBatchSize ограничивает количество ключей или записей в одном пакетном запросе
+const maxBatchSize = 1000
+
+// MGet обеспечивает получение данных из кэша по нескольким ключам за один запрос.
+// Результат для каждого ключа, включая промахи, содержит статус, который вернул бы GET /api/lru/{key}.
+func (i *Implementation) MGet(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method MGet() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method MGet() done with time " + time.Since(timeStart).String())
+	}()
+
+	var rawData desc.KeysData
+	if status := readBatch(r, &rawData, func() int { return len(rawData.Keys) }); status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	if !validKeys(rawData.Keys) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	results, err := i.cacheServiceFor(r).MGet(context.Background(), rawData.Keys)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeBatch(w, results, func(err error) int {
+		switch {
+		case err == nil:
+			return http.StatusOK
+		case errors.Is(err, repository.ErrExpired):
+			return http.StatusGone
+		case errors.Is(err, repository.ErrNotFound):
+			return http.StatusNotFound
+		}
+		return http.StatusInternalServerError
+	})
+}
+
+// MPut обеспечивает запись нескольких значений в кэш за один запрос. У каждой записи свой TTL
+// и параметры, как в POST /api/lru. Результат для каждой записи содержит статус и версию.
+func (i *Implementation) MPut(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method MPut() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method MPut() done with time " + time.Since(timeStart).String())
+	}()
+
+	var rawData desc.EntriesPutData
+	if status := readBatch(r, &rawData, func() int { return len(rawData.Entries) }); status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+
+	convertedData := make([]model.EntryPutData, len(rawData.Entries))
+	for idx, entry := range rawData.Entries {
+		convertedData[idx] = converter.ToEntryPutDataFromDesc(entry)
+		if len(entry.Mode) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := i.cacheServiceFor(r).MPut(context.Background(), convertedData)
+	if errors.Is(err, model.ErrInvalidEntry) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeBatch(w, results, func(err error) int {
+		switch {
+		case err == nil:
+			return http.StatusCreated
+		case errors.Is(err, repository.ErrTooLarge):
+			return http.StatusRequestEntityTooLarge
+		case errors.Is(err, repository.ErrRejected):
+			return http.StatusInsufficientStorage
+		}
+		return http.StatusInternalServerError
+	})
+}
+
+// MDelete обеспечивает удаление данных по нескольким ключам за один запрос.
+// Результат для каждого ключа содержит статус, который вернул бы DELETE /api/lru/{key}.
+func (i *Implementation) MDelete(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method MDelete() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method MDelete() done with time " + time.Since(timeStart).String())
+	}()
+
+	var rawData desc.KeysData
+	if status := readBatch(r, &rawData, func() int { return len(rawData.Keys) }); status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	if !validKeys(rawData.Keys) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	results, err := i.cacheServiceFor(r).MDelete(context.Background(), rawData.Keys)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Как и DELETE /api/lru/{key}, удаление не возвращает значения
+	for idx := range results {
+		results[idx].Entry = model.EntryGetData{Key: results[idx].Entry.Key}
+	}
+
+	writeBatch(w, results, func(err error) int {
+		switch {
+		case err == nil:
+			return http.StatusNoContent
+		case errors.Is(err, repository.ErrExpired):
+			return http.StatusGone
+		case errors.Is(err, repository.ErrNotFound):
+			return http.StatusNotFound
+		}
+		return http.StatusInternalServerError
+	})
+}
+
+// readBatch читает тело пакетного запроса в data и возвращает статус ошибки или 200 OK.
+// size возвращает количество ключей или записей в прочитанном запросе.
+func readBatch(r *http.Request, data interface{}, size func() int) int {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return http.StatusBadRequest
+	}
+	if err := json.Unmarshal(body, data); err != nil {
+		return http.StatusBadRequest
+	}
+	if size() > maxBatchSize {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusOK
+}
+
+// validKeys проверяет, что среди ключей нет пустых
+func validKeys(keys []string) bool {
+	for _, key := range keys {
+		if len(key) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeBatch отправляет результаты пакетной операции, определяя статус каждой записи функцией status
+func writeBatch(w http.ResponseWriter, results []model.EntryResult, status func(err error) int) {
+	sendData := desc.BatchResultData{Results: make([]desc.EntryResultData, len(results))}
+	for idx, result := range results {
+		sendData.Results[idx] = converter.ToEntryResultDataFromModel(result, status(result.Err))
+	}
+
+	sendDataBytes, err := json.Marshal(sendData)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(sendDataBytes)
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestMGet(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("MGet", mock.Anything, []string{"a", "b", "c"}).Return([]model.EntryResult{
+		{Entry: model.EntryGetData{Key: "a", Value: "x", ExpiresAt: time.Unix(1700000000, 0), Version: 1}},
+		{Entry: model.EntryGetData{Key: "b"}, Err: repository.ErrNotFound},
+		{Entry: model.EntryGetData{Key: "c"}, Err: repository.ErrExpired},
+	}, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("POST", "/_mget", strings.NewReader(`{"keys":["a","b","c"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.MGet(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"results":[`+
+		`{"key":"a","status":200,"value":"x","expires_at":1700000000,"version":1},`+
+		`{"key":"b","status":404},`+
+		`{"key":"c","status":410}]}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestMPut(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("MPut", mock.Anything, []model.EntryPutData{
+		{Key: "a", Value: "x", TTL: time.Minute},
+		{Key: "b", Value: "y"},
+	}).Return([]model.EntryResult{
+		{Entry: model.EntryGetData{Key: "a", Version: 1}},
+		{Entry: model.EntryGetData{Key: "b"}, Err: repository.ErrTooLarge},
+	}, nil)
+	mockService.On("MPut", mock.Anything, []model.EntryPutData{{Key: "a", TTL: -time.Second}}).
+		Return([]model.EntryResult(nil), model.ErrInvalidEntry)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("POST", "/_mput", strings.NewReader(`{"entries":[{"key":"a","value":"x","ttl_seconds":60},{"key":"b","value":"y"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.MPut(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"results":[{"key":"a","status":201,"version":1},{"key":"b","status":413}]}`, rr.Body.String())
+
+	// Некорректная запись отклоняется сервисом
+	req, err = http.NewRequest("POST", "/_mput", strings.NewReader(`{"entries":[{"key":"a","ttl_seconds":-1}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.MPut(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Режим записи в пакете не поддерживается
+	req, err = http.NewRequest("POST", "/_mput", strings.NewReader(`{"entries":[{"key":"a","value":"x","mode":"nx"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.MPut(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMDelete(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("MDelete", mock.Anything, []string{"a", "b"}).Return([]model.EntryResult{
+		{Entry: model.EntryGetData{Key: "a", Value: "x"}},
+		{Entry: model.EntryGetData{Key: "b"}, Err: repository.ErrNotFound},
+	}, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("POST", "/_mdelete", strings.NewReader(`{"keys":["a","b"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.MDelete(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"results":[{"key":"a","status":204},{"key":"b","status":404}]}`, rr.Body.String())
+
+	// Пустой ключ
+	req, err = http.NewRequest("POST", "/_mdelete", strings.NewReader(`{"keys":[""]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.MDelete(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+)
+
+// formatETag возвращает значение заголовка ETag для версии записи
+func formatETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// parseETag возвращает версию записи из значения заголовка If-Match.
+// Принимается одна сильная или слабая метка, кавычки необязательны.
+func parseETag(etag string) (uint64, bool) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	etag = strings.Trim(etag, `"`)
+
+	version, err := strconv.ParseUint(etag, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
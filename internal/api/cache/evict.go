@@ -2,11 +2,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 )
 
 // Evict обеспечивает ручное удаление данных по ключу
@@ -23,15 +25,17 @@ func (i *Implementation) Evict(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	value, err := i.cacheService.Evict(context.Background(), key)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	_, err := i.cacheServiceFor(r).Evict(context.Background(), key)
+	switch {
+	case errors.Is(err, repository.ErrExpired):
+		w.WriteHeader(http.StatusGone)
 		return
-	}
-
-	if value == nil {
+	case errors.Is(err, repository.ErrNotFound):
 		w.WriteHeader(http.StatusNotFound)
 		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
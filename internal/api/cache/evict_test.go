@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestEvict_NullValue(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Evict", mock.Anything, "a").Return(nil, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	rr := httptest.NewRecorder()
+	handler.Evict(rr, newKeyRequest(t, "DELETE", "/a", "a", nil))
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestEvict_NotFound(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Evict", mock.Anything, "a").Return(nil, repository.ErrNotFound)
+	handler := &Implementation{cacheService: mockService}
+
+	rr := httptest.NewRecorder()
+	handler.Evict(rr, newKeyRequest(t, "DELETE", "/a", "a", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestEvict_Expired(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Evict", mock.Anything, "a").Return(nil, repository.ErrExpired)
+	handler := &Implementation{cacheService: mockService}
+
+	rr := httptest.NewRecorder()
+	handler.Evict(rr, newKeyRequest(t, "DELETE", "/a", "a", nil))
+
+	assert.Equal(t, http.StatusGone, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -2,13 +2,18 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
 )
 
-// EvictAll  обеспечивает ручную инвалидацию всего кэша
+// EvictAll  обеспечивает ручную инвалидацию всего кэша.
+//
+// Если передан параметр запроса prefix, удаляются только записи, ключи которых начинаются с prefix,
+// если tag - только записи с этим тегом; в ответе возвращается количество удаленных записей.
 func (i *Implementation) EvictAll(w http.ResponseWriter, r *http.Request) {
 	timeStart := time.Now()
 	log.Debug().Msg("API implementation method EvictAll() requested by: " + r.Method + " " + r.URL.Path)
@@ -16,7 +21,20 @@ func (i *Implementation) EvictAll(w http.ResponseWriter, r *http.Request) {
 		log.Debug().Msg("API implementation method EvictAll() done with time " + time.Since(timeStart).String())
 	}()
 
-	err := i.cacheService.EvictAll(context.Background())
+	query := r.URL.Query()
+	switch {
+	case query.Has("prefix") && query.Has("tag"):
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case query.Has("prefix"):
+		evictMatching(w, query.Get("prefix"), i.cacheServiceFor(r).EvictPrefix)
+		return
+	case query.Has("tag"):
+		evictMatching(w, query.Get("tag"), i.cacheServiceFor(r).EvictByTag)
+		return
+	}
+
+	err := i.cacheServiceFor(r).EvictAll(context.Background())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -24,3 +42,27 @@ func (i *Implementation) EvictAll(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// evictMatching обеспечивает удаление записей, подходящих под префикс или тег pattern, функцией evict.
+// Пустое значение не допускается: для очистки всего кэша используется DELETE /api/lru без параметров.
+func evictMatching(w http.ResponseWriter, pattern string, evict func(ctx context.Context, pattern string) (int, error)) {
+	if len(pattern) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	n, err := evict(context.Background(), pattern)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendDataBytes, err := json.Marshal(desc.EvictedData{Evicted: n})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(sendDataBytes)
+}
@@ -68,3 +68,55 @@ func TestEvictAll_UnknownError(t *testing.T) {
 	// Assert that the expectations were met
 	mockService.AssertExpectations(t)
 }
+
+func TestEvictAll_Prefix(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("EvictPrefix", mock.Anything, "user:1:").Return(3, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("DELETE", "/?prefix=user:1:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.EvictAll(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"evicted":3}`, rr.Body.String())
+
+	// Пустой префикс не очищает кэш
+	req, err = http.NewRequest("DELETE", "/?prefix=", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.EvictAll(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEvictAll_Tag(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("EvictByTag", mock.Anything, "product:1").Return(2, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("DELETE", "/?tag=product:1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.EvictAll(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"evicted":2}`, rr.Body.String())
+
+	// Префикс и тег одновременно не поддерживаются
+	req, err = http.NewRequest("DELETE", "/?tag=product:1&prefix=user:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.EvictAll(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
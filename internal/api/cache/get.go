@@ -3,12 +3,14 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog/log"
-	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 )
 
 // Get обеспечивает получение данных из кэша по ключу
@@ -25,22 +27,20 @@ func (i *Implementation) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	value, expiresAt, err := i.cacheService.Get(context.Background(), key)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	entry, err := i.cacheServiceFor(r).Get(context.Background(), key)
+	switch {
+	case errors.Is(err, repository.ErrExpired):
+		w.WriteHeader(http.StatusGone)
 		return
-	}
-
-	if value == nil {
+	case errors.Is(err, repository.ErrNotFound):
 		w.WriteHeader(http.StatusNotFound)
 		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
-	sendData := desc.EntryGetData{
-		Key:       key,
-		Value:     value,
-		ExpiresAt: expiresAt.Unix(),
-	}
+	sendData := converter.ToEntryGetDataFromModel(entry)
 	sendDataBytes, err := json.Marshal(sendData)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -48,6 +48,7 @@ func (i *Implementation) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", formatETag(entry.Version))
 	w.Write(sendDataBytes)
 	w.WriteHeader(http.StatusOK)
 }
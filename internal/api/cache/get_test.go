@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestGet_NullValue(t *testing.T) {
+	// Create a new mock service
+	mockService := new(MockService)
+
+	// Set expectation
+	mockService.On("Get", mock.Anything, "a").Return(model.EntryGetData{Key: "a", ExpiresAt: time.Unix(100, 0), Version: 3}, nil)
+
+	// Create the handler with the mocked service
+	handler := &Implementation{cacheService: mockService}
+
+	// Create a new HTTP request to test the handler
+	req := newKeyRequest(t, "GET", "/a", "a", nil)
+
+	// Create a ResponseRecorder to capture the response
+	rr := httptest.NewRecorder()
+
+	// Call the handler
+	handler.Get(rr, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"key":"a","value":null,"expires_at":100,"stale":false,"version":3}`, rr.Body.String())
+	assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+
+	// Assert that the expectations were met
+	mockService.AssertExpectations(t)
+}
+
+func TestGet_NotFound(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Get", mock.Anything, "a").Return(model.EntryGetData{}, repository.ErrNotFound)
+	handler := &Implementation{cacheService: mockService}
+
+	rr := httptest.NewRecorder()
+	handler.Get(rr, newKeyRequest(t, "GET", "/a", "a", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGet_Expired(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Get", mock.Anything, "a").Return(model.EntryGetData{}, repository.ErrExpired)
+	handler := &Implementation{cacheService: mockService}
+
+	rr := httptest.NewRecorder()
+	handler.Get(rr, newKeyRequest(t, "GET", "/a", "a", nil))
+
+	assert.Equal(t, http.StatusGone, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -3,15 +3,29 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
 )
 
+const (
+	defaultScanLimit = 100  // Размер страницы постраничного обхода, если параметр limit не передан
+	maxScanLimit     = 1000 // Максимальный размер страницы постраничного обхода
+)
+
 // GetAll обеспечивает получение всего наполнения кэша в виде двух слайсов: слайса ключей и слайса значений.
 // Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
+//
+// Если передан параметр запроса prefix, возвращаются только записи, ключи которых начинаются
+// с prefix, упорядоченные по ключу.
+//
+// Если передан хотя бы один из параметров cursor, limit или order, кэш обходится постранично (см. scan).
 func (i *Implementation) GetAll(w http.ResponseWriter, r *http.Request) {
 	timeStart := time.Now()
 	log.Debug().Msg("API implementation method GetAll() requested by: " + r.Method + " " + r.URL.Path)
@@ -19,7 +33,26 @@ func (i *Implementation) GetAll(w http.ResponseWriter, r *http.Request) {
 		log.Debug().Msg("API implementation method GetAll() done with time " + time.Since(timeStart).String())
 	}()
 
-	keys, values, err := i.cacheService.GetAll(context.Background())
+	var (
+		keys   []string
+		values []interface{}
+		err    error
+	)
+	query := r.URL.Query()
+	if query.Has("cursor") || query.Has("limit") || query.Has("order") {
+		if query.Has("prefix") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		i.scan(w, r)
+		return
+	}
+
+	if query.Has("prefix") {
+		keys, values, err = i.cacheServiceFor(r).ScanPrefix(context.Background(), query.Get("prefix"))
+	} else {
+		keys, values, err = i.cacheServiceFor(r).GetAll(context.Background())
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -43,3 +76,59 @@ func (i *Implementation) GetAll(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(sendDataBytes)
 }
+
+// scan возвращает страницу постраничного обхода кэша: не более limit записей (по умолчанию defaultScanLimit)
+// в порядке order ("lru" - от давно не использовавшихся, по умолчанию, или "mru"), следующих за позицией
+// cursor из next_cursor предыдущей страницы. На последней странице next_cursor отсутствует.
+// Курсор остается действительным, даже если записи вытесняются между запросами страниц.
+func (i *Implementation) scan(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := defaultScanLimit
+	if query.Has("limit") {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit <= 0 || limit > maxScanLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	var order string
+	switch query.Get("order") {
+	case "", desc.ScanOrderLRU:
+		order = model.ScanOrderLRU
+	case desc.ScanOrderMRU:
+		order = model.ScanOrderMRU
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	page, err := i.cacheServiceFor(r).Scan(context.Background(), query.Get("cursor"), limit, order)
+	switch {
+	case errors.Is(err, repository.ErrInvalidCursor):
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendData := desc.EntryScanData{
+		Keys:       page.Keys,
+		Values:     page.Values,
+		NextCursor: page.Cursor,
+	}
+	if sendData.Keys == nil {
+		sendData.Keys, sendData.Values = []string{}, []interface{}{}
+	}
+	sendDataBytes, err := json.Marshal(sendData)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(sendDataBytes)
+}
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 )
 
 func TestGetAll_NoContent(t *testing.T) {
@@ -97,3 +100,96 @@ func TestGetAll_Error(t *testing.T) {
 	// Assert that the expectations were met
 	mockService.AssertExpectations(t)
 }
+
+func TestGetAll_Prefix(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("ScanPrefix", mock.Anything, "user:1:").Return([]string{"user:1:a", "user:1:b"}, []interface{}{1, 2}, nil)
+	mockService.On("ScanPrefix", mock.Anything, "order:").Return(nil, nil, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("GET", "/?prefix=user:1:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.GetAll(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"keys":["user:1:a","user:1:b"],"values":[1,2]}`, rr.Body.String())
+
+	req, err = http.NewRequest("GET", "/?prefix=order:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.GetAll(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAll_Scan(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		cursor     string
+		limit      int
+		order      string
+		page       model.ScanPage
+		callErr    error
+		wantStatus int
+		wantBody   string
+	}{
+		{"FirstPage", "/?limit=2", "", 2, model.ScanOrderLRU,
+			model.ScanPage{Keys: []string{"a", "b"}, Values: []interface{}{1, 2}, Cursor: "next"}, nil,
+			http.StatusOK, `{"keys":["a","b"],"values":[1,2],"next_cursor":"next"}`},
+		{"LastPage", "/?cursor=next&order=mru", "next", defaultScanLimit, model.ScanOrderMRU,
+			model.ScanPage{Keys: []string{"c"}, Values: []interface{}{3}}, nil,
+			http.StatusOK, `{"keys":["c"],"values":[3]}`},
+		{"EmptyPage", "/?cursor=next", "next", defaultScanLimit, model.ScanOrderLRU,
+			model.ScanPage{}, nil,
+			http.StatusOK, `{"keys":[],"values":[]}`},
+		{"InvalidCursor", "/?cursor=bad", "bad", defaultScanLimit, model.ScanOrderLRU,
+			model.ScanPage{}, repository.ErrInvalidCursor, http.StatusBadRequest, ""},
+		{"Internal", "/?order=lru", "", defaultScanLimit, model.ScanOrderLRU,
+			model.ScanPage{}, fmt.Errorf("some error"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockService)
+			mockService.On("Scan", context.Background(), tt.cursor, tt.limit, tt.order).Return(tt.page, tt.callErr)
+
+			handler := &Implementation{cacheService: mockService}
+
+			req, err := http.NewRequest("GET", tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			handler.GetAll(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if len(tt.wantBody) > 0 {
+				assert.JSONEq(t, tt.wantBody, rr.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAll_ScanBadRequest(t *testing.T) {
+	for _, target := range []string{"/?limit=0", "/?limit=x", "/?limit=1001", "/?order=random", "/?prefix=a&limit=1"} {
+		mockService := new(MockService)
+		handler := &Implementation{cacheService: mockService}
+
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.GetAll(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+		mockService.AssertNotCalled(t, "Scan")
+	}
+}
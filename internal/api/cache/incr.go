@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+)
+
+// Incr обеспечивает атомарное увеличение числового значения записи по ключу.
+//
+// Отсутствующая запись создается со значением delta (по умолчанию 1) и TTL ttl_seconds.
+// Возвращает запись с новым значением; если текущее значение не является числом
+// или сумма переполняет int64 (для дробных - бесконечна) - 409 Conflict.
+func (i *Implementation) Incr(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method Incr() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method Incr() done with time " + time.Since(timeStart).String())
+	}()
+
+	key := chi.URLParam(r, "key")
+	if len(key) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var rawData desc.IncrData
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &rawData); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	delta, ok := parseDelta(rawData.Delta)
+	if !ok || rawData.TTLSeconds < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entry, err := i.cacheServiceFor(r).Incr(context.Background(), key, delta, time.Duration(rawData.TTLSeconds)*time.Second)
+	switch {
+	case errors.Is(err, model.ErrInvalidEntry):
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.ErrNotNumeric), errors.Is(err, repository.ErrOverflow):
+		w.WriteHeader(http.StatusConflict)
+		return
+	case errors.Is(err, repository.ErrTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, repository.ErrRejected):
+		w.WriteHeader(http.StatusInsufficientStorage)
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendDataBytes, err := json.Marshal(converter.ToEntryGetDataFromModel(entry))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", formatETag(entry.Version))
+	w.Write(sendDataBytes)
+}
+
+// parseDelta приводит приращение из запроса к int64 или, для дробных чисел, к float64.
+// Пустое приращение равно 1.
+func parseDelta(number json.Number) (interface{}, bool) {
+	if len(number) == 0 {
+		return int64(1), true
+	}
+	if n, err := number.Int64(); err == nil {
+		return n, true
+	}
+	if f, err := number.Float64(); err == nil {
+		return f, true
+	}
+	return nil, false
+}
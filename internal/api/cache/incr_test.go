@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestIncr(t *testing.T) {
+	expiresAt := time.Unix(1700000000, 0)
+
+	mockService := new(MockService)
+	mockService.On("Incr", mock.Anything, "a", int64(1), time.Duration(0)).Return(model.EntryGetData{Key: "a", Value: int64(1), ExpiresAt: expiresAt, Version: 1}, nil)
+	mockService.On("Incr", mock.Anything, "a", int64(-5), time.Minute).Return(model.EntryGetData{Key: "a", Value: int64(-4), ExpiresAt: expiresAt, Version: 2}, nil)
+	mockService.On("Incr", mock.Anything, "a", 0.5, time.Duration(0)).Return(model.EntryGetData{Key: "a", Value: -3.5, ExpiresAt: expiresAt, Version: 3}, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	tests := []struct {
+		body     string
+		expected string
+	}{
+		{``, `{"key":"a","value":1,"expires_at":1700000000,"stale":false,"version":1}`},
+		{`{"delta":-5,"ttl_seconds":60}`, `{"key":"a","value":-4,"expires_at":1700000000,"stale":false,"version":2}`},
+		{`{"delta":0.5}`, `{"key":"a","value":-3.5,"expires_at":1700000000,"stale":false,"version":3}`},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		handler.Incr(rr, newKeyRequest(t, "POST", "/a/incr", "a", strings.NewReader(tt.body)))
+		assert.Equal(t, http.StatusOK, rr.Code, tt.body)
+		assert.Equal(t, tt.expected, rr.Body.String(), tt.body)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIncr_NotNumeric(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Incr", mock.Anything, "a", int64(1), time.Duration(0)).Return(model.EntryGetData{}, repository.ErrNotNumeric)
+	handler := &Implementation{cacheService: mockService}
+
+	rr := httptest.NewRecorder()
+	handler.Incr(rr, newKeyRequest(t, "POST", "/a/incr", "a", strings.NewReader(`{"delta":1}`)))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// Некорректное приращение
+	rr = httptest.NewRecorder()
+	handler.Incr(rr, newKeyRequest(t, "POST", "/a/incr", "a", strings.NewReader(`{"delta":"abc"}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestIncr_InvalidEntry(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Incr", mock.Anything, "a", int64(1), time.Duration(0)).Return(model.EntryGetData{}, model.ErrInvalidEntry)
+	handler := &Implementation{cacheService: mockService}
+
+	// Ошибка проверки входных данных сервисом отдается как 400
+	rr := httptest.NewRecorder()
+	handler.Incr(rr, newKeyRequest(t, "POST", "/a/incr", "a", strings.NewReader(`{"delta":1}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+// namespaceCtxKey ключ контекста запроса, под которым хранится сервис кэша пространства имен
+type namespaceCtxKey struct{}
+
+// WithNamespace возвращает middleware для маршрутов /api/ns/{namespace}/lru: по параметру маршрута
+// namespace выбирается сервис кэша пространства имен, которым затем пользуются обработчики Implementation.
+// Для несуществующего пространства имен возвращается 404.
+func WithNamespace(namespaces service.NamespaceService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name := chi.URLParam(r, "namespace")
+
+			cacheService, err := namespaces.Namespace(r.Context(), name)
+			switch {
+			case errors.Is(err, service.ErrNamespaceNotFound):
+				log.Debug().Msg("запрос к несуществующему пространству имен " + name)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			case err != nil:
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), namespaceCtxKey{}, cacheService)))
+		})
+	}
+}
+
+// cacheServiceFor возвращает сервис кэша, к которому относится запрос: сервис пространства имен,
+// выбранный WithNamespace, или сервис по умолчанию
+func (i *Implementation) cacheServiceFor(r *http.Request) service.CacheService {
+	if cacheService, ok := r.Context().Value(namespaceCtxKey{}).(service.CacheService); ok {
+		return cacheService
+	}
+	return i.cacheService
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+type MockNamespaceService struct {
+	mock.Mock
+}
+
+func (m *MockNamespaceService) Namespace(ctx context.Context, name string) (service.CacheService, error) {
+	args := m.Called(ctx, name)
+	cacheService, _ := args.Get(0).(service.CacheService)
+	return cacheService, args.Error(1)
+}
+
+func (m *MockNamespaceService) Create(ctx context.Context, name string, cfg model.NamespaceConfig) (model.NamespaceInfo, error) {
+	args := m.Called(ctx, name, cfg)
+	return args.Get(0).(model.NamespaceInfo), args.Error(1)
+}
+
+func (m *MockNamespaceService) Delete(ctx context.Context, name string) error {
+	return m.Called(ctx, name).Error(0)
+}
+
+func (m *MockNamespaceService) List(ctx context.Context) ([]model.NamespaceInfo, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.NamespaceInfo), args.Error(1)
+}
+
+func (m *MockNamespaceService) Close(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockNamespaceService) SetHooks(hooks service.NamespaceHooks) {
+	m.Called(hooks)
+}
+
+func TestWithNamespace(t *testing.T) {
+	defaultService := new(MockService)
+	defaultService.On("Stats", context.Background()).Return(model.CacheStats{Len: 1, Size: 10}, nil)
+	teamService := new(MockService)
+	teamService.On("Stats", context.Background()).Return(model.CacheStats{Len: 2, Size: 100}, nil)
+
+	namespaces := new(MockNamespaceService)
+	namespaces.On("Namespace", mock.Anything, "team-a").Return(teamService, nil)
+	namespaces.On("Namespace", mock.Anything, "missing").Return(nil, service.ErrNamespaceNotFound)
+
+	handler := &Implementation{cacheService: defaultService}
+
+	r := chi.NewRouter()
+	r.Get("/api/stats", handler.Stats)
+	r.Route("/api/ns/{namespace}", func(r chi.Router) {
+		r.Use(WithNamespace(namespaces))
+		r.Get("/stats", handler.Stats)
+	})
+
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/api/stats", http.StatusOK, `{"len":1,"size":10,"bytes":0,"max_bytes":0,"admitted":0,"rejected":0}`},
+		{"/api/ns/team-a/stats", http.StatusOK, `{"len":2,"size":100,"bytes":0,"max_bytes":0,"admitted":0,"rejected":0}`},
+		{"/api/ns/missing/stats", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest("GET", tt.target, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		assert.Equal(t, tt.wantStatus, rr.Code, tt.target)
+		if len(tt.wantBody) > 0 {
+			assert.JSONEq(t, tt.wantBody, rr.Body.String(), tt.target)
+		}
+	}
+
+	defaultService.AssertNumberOfCalls(t, "Stats", 1)
+	teamService.AssertNumberOfCalls(t, "Stats", 1)
+	namespaces.AssertExpectations(t)
+}
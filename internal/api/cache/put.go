@@ -3,16 +3,25 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
 )
 
-// Put обеспечивает запись данных в кэш
+// Put обеспечивает запись данных в кэш.
+//
+// Если передан заголовок If-Match с версией записи (ETag из GET /api/lru/{key}), запись выполняется,
+// только если текущая версия совпадает, иначе возвращается 412 Precondition Failed.
+// Режим записи mode "nx" записывает значение, только если ключа нет (201 Created), а "xx" - только
+// если ключ есть (200 OK); если условие не выполнено, возвращается 409 Conflict.
+// Версия записанной записи возвращается в заголовке ETag.
 func (i *Implementation) Put(w http.ResponseWriter, r *http.Request) {
 	timeStart := time.Now()
 	log.Debug().Msg("API implementation method Put() requested by: " + r.Method + " " + r.URL.Path)
@@ -35,16 +44,55 @@ func (i *Implementation) Put(w http.ResponseWriter, r *http.Request) {
 
 	convertedData := converter.ToEntryPutDataFromDesc(rawData)
 
-	if len(convertedData.Key) == 0 || convertedData.TTL < 0 {
+	ifMatch := r.Header.Get("If-Match")
+
+	var (
+		version uint64
+		status  = http.StatusCreated
+	)
+	switch {
+	case len(ifMatch) > 0:
+		// Условная запись по версии, несовместима с режимом записи
+		expectedVersion, ok := parseETag(ifMatch)
+		if !ok || len(rawData.Mode) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		version, err = i.cacheServiceFor(r).CompareAndSwap(context.Background(), convertedData, expectedVersion)
+		status = http.StatusOK
+	case rawData.Mode == desc.PutModeIfAbsent:
+		version, err = i.cacheServiceFor(r).PutIfAbsent(context.Background(), convertedData)
+	case rawData.Mode == desc.PutModeIfPresent:
+		version, err = i.cacheServiceFor(r).Replace(context.Background(), convertedData)
+		status = http.StatusOK
+	case len(rawData.Mode) == 0:
+		version, err = i.cacheServiceFor(r).Put(context.Background(), convertedData)
+	default:
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	err = i.cacheService.Put(context.Background(), convertedData.Key, convertedData.Value, convertedData.TTL)
-	if err != nil {
+	switch {
+	case errors.Is(err, model.ErrInvalidEntry):
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.ErrVersionMismatch), len(ifMatch) > 0 && errors.Is(err, repository.ErrNotFound):
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, repository.ErrExists), errors.Is(err, repository.ErrNotFound):
+		w.WriteHeader(http.StatusConflict)
+		return
+	case errors.Is(err, repository.ErrTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, repository.ErrRejected):
+		w.WriteHeader(http.StatusInsufficientStorage)
+		return
+	case err != nil:
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("ETag", formatETag(version))
+	w.WriteHeader(status)
 }
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestPut_Created(t *testing.T) {
+	// Create a new mock service
+	mockService := new(MockService)
+
+	// Set expectation
+	mockService.On("Put", mock.Anything, model.EntryPutData{Key: "a"}).Return(uint64(1), nil)
+
+	// Create the handler with the mocked service
+	handler := &Implementation{cacheService: mockService}
+
+	// Create a new HTTP request to test the handler
+	req, err := http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a ResponseRecorder to capture the response
+	rr := httptest.NewRecorder()
+
+	// Call the handler
+	handler.Put(rr, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+
+	// Assert that the expectations were met
+	mockService.AssertExpectations(t)
+}
+
+func TestPut_Rejected(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Put", mock.Anything, model.EntryPutData{Key: "a", Value: "b"}).Return(uint64(0), repository.ErrRejected)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.Put(rr, req)
+
+	// Отклоненная фильтром допуска запись не сохранена, поэтому ETag не отдается
+	assert.Equal(t, http.StatusInsufficientStorage, rr.Code)
+	assert.Empty(t, rr.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestPut_IfMatch(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("CompareAndSwap", mock.Anything, model.EntryPutData{Key: "a", Value: "b"}, uint64(1)).Return(uint64(2), nil)
+	mockService.On("CompareAndSwap", mock.Anything, model.EntryPutData{Key: "a", Value: "c"}, uint64(1)).Return(uint64(0), repository.ErrVersionMismatch)
+	handler := &Implementation{cacheService: mockService}
+
+	// Версия совпадает
+	req, err := http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-Match", `"1"`)
+	rr := httptest.NewRecorder()
+	handler.Put(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+	// Версия изменилась
+	req, err = http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"c"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-Match", `"1"`)
+	rr = httptest.NewRecorder()
+	handler.Put(rr, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+	// Некорректная метка
+	req, err = http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"c"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-Match", `"abc"`)
+	rr = httptest.NewRecorder()
+	handler.Put(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPut_Mode(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("PutIfAbsent", mock.Anything, model.EntryPutData{Key: "a", Value: "b"}).Return(uint64(1), nil)
+	mockService.On("PutIfAbsent", mock.Anything, model.EntryPutData{Key: "a", Value: "c"}).Return(uint64(0), repository.ErrExists)
+	mockService.On("Replace", mock.Anything, model.EntryPutData{Key: "a", Value: "d"}).Return(uint64(2), nil)
+	mockService.On("Replace", mock.Anything, model.EntryPutData{Key: "b", Value: "d"}).Return(uint64(0), repository.ErrExpired)
+	handler := &Implementation{cacheService: mockService}
+
+	tests := []struct {
+		body   string
+		status int
+		etag   string
+	}{
+		{`{"key":"a","value":"b","mode":"nx"}`, http.StatusCreated, `"1"`},
+		{`{"key":"a","value":"c","mode":"nx"}`, http.StatusConflict, ""},
+		{`{"key":"a","value":"d","mode":"xx"}`, http.StatusOK, `"2"`},
+		{`{"key":"b","value":"d","mode":"xx"}`, http.StatusConflict, ""},
+		{`{"key":"a","value":"d","mode":"zz"}`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("POST", "/", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.Put(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.body)
+		assert.Equal(t, tt.etag, rr.Header().Get("ETag"), tt.body)
+	}
+
+	// Режим записи несовместим с If-Match
+	req, err := http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"b","mode":"nx"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-Match", `"1"`)
+	rr := httptest.NewRecorder()
+	handler.Put(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPut_Tags(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Put", mock.Anything, model.EntryPutData{Key: "a", Value: "b", Tags: []string{"product:1", "api"}}).Return(uint64(1), nil)
+	mockService.On("Put", mock.Anything, model.EntryPutData{Key: "a", Value: "b", Tags: []string{""}}).Return(uint64(0), model.ErrInvalidEntry)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"b","tags":["product:1","api"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.Put(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	// Пустой тег отклоняется сервисом
+	req, err = http.NewRequest("POST", "/", strings.NewReader(`{"key":"a","value":"b","tags":[""]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	handler.Put(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -2,9 +2,16 @@ package cache
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"testing"
 	"time"
 
+	"github.com/go-chi/chi"
+
 	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 )
 
 type MockService struct {
@@ -21,14 +28,63 @@ func (m *MockService) Evict(ctx context.Context, key string) (value interface{},
 	return args.Get(0), args.Error(1)
 }
 
-func (m *MockService) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	args := m.Called(ctx, key, value, ttl)
-	return args.Error(0)
+func (m *MockService) Put(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	args := m.Called(ctx, data)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockService) CompareAndSwap(ctx context.Context, data model.EntryPutData, expectedVersion uint64) (version uint64, err error) {
+	args := m.Called(ctx, data, expectedVersion)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockService) PutIfAbsent(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	args := m.Called(ctx, data)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockService) Replace(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	args := m.Called(ctx, data)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockService) Incr(ctx context.Context, key string, delta interface{}, ttl time.Duration) (model.EntryGetData, error) {
+	args := m.Called(ctx, key, delta, ttl)
+	return args.Get(0).(model.EntryGetData), args.Error(1)
+}
+
+func (m *MockService) MGet(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	args := m.Called(ctx, keys)
+	return args.Get(0).([]model.EntryResult), args.Error(1)
 }
 
-func (m *MockService) Get(ctx context.Context, key string) (value interface{}, expiresAt time.Time, err error) {
+func (m *MockService) MPut(ctx context.Context, data []model.EntryPutData) ([]model.EntryResult, error) {
+	args := m.Called(ctx, data)
+	return args.Get(0).([]model.EntryResult), args.Error(1)
+}
+
+func (m *MockService) MDelete(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	args := m.Called(ctx, keys)
+	return args.Get(0).([]model.EntryResult), args.Error(1)
+}
+
+func (m *MockService) Txn(ctx context.Context, data model.TxnData) ([]model.EntryResult, error) {
+	args := m.Called(ctx, data)
+	return args.Get(0).([]model.EntryResult), args.Error(1)
+}
+
+func (m *MockService) Get(ctx context.Context, key string) (model.EntryGetData, error) {
 	args := m.Called(ctx, key)
-	return args.Get(0), args.Get(1).(time.Time), args.Error(3)
+	return args.Get(0).(model.EntryGetData), args.Error(1)
+}
+
+func (m *MockService) GetOrLoad(ctx context.Context, key string, loader repository.Loader) (model.EntryGetData, error) {
+	args := m.Called(ctx, key, loader)
+	return args.Get(0).(model.EntryGetData), args.Error(1)
+}
+
+func (m *MockService) SetLoader(loader repository.Loader) {
+	m.Called(loader)
 }
 
 func (m *MockService) GetAll(ctx context.Context) (keys []string, values []interface{}, err error) {
@@ -49,3 +105,62 @@ func (m *MockService) GetAll(ctx context.Context) (keys []string, values []inter
 	err = args.Error(2)
 	return keys, values, err
 }
+
+func (m *MockService) Scan(ctx context.Context, cursor string, limit int, order string) (model.ScanPage, error) {
+	args := m.Called(ctx, cursor, limit, order)
+	return args.Get(0).(model.ScanPage), args.Error(1)
+}
+
+func (m *MockService) ScanPrefix(ctx context.Context, prefix string) (keys []string, values []interface{}, err error) {
+	args := m.Called(ctx, prefix)
+	keys, _ = args.Get(0).([]string)
+	values, _ = args.Get(1).([]interface{})
+	return keys, values, args.Error(2)
+}
+
+func (m *MockService) EvictPrefix(ctx context.Context, prefix string) (n int, err error) {
+	args := m.Called(ctx, prefix)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) EvictByTag(ctx context.Context, tag string) (n int, err error) {
+	args := m.Called(ctx, tag)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) Stats(ctx context.Context) (model.CacheStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(model.CacheStats), args.Error(1)
+}
+
+func (m *MockService) Snapshot(ctx context.Context, w io.Writer) (n int, err error) {
+	args := m.Called(ctx, w)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) Restore(ctx context.Context, r io.Reader) (n int, err error) {
+	args := m.Called(ctx, r)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) OpenLog(ctx context.Context, cfg model.OpLogConfig) (n int, err error) {
+	args := m.Called(ctx, cfg)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) CompactLog(ctx context.Context) (compacted bool, err error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+// newKeyRequest создает HTTP-запрос с параметром маршрута key, как его передал бы chi
+func newKeyRequest(t *testing.T, method, target, key string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("key", key)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+)
+
+// Stats обеспечивает получение текущего заполнения кэша
+func (i *Implementation) Stats(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method Stats() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method Stats() done with time " + time.Since(timeStart).String())
+	}()
+
+	stats, err := i.cacheServiceFor(r).Stats(context.Background())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendDataBytes, err := json.Marshal(converter.ToCacheStatsDataFromModel(stats))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(sendDataBytes)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+)
+
+func TestStats_OK(t *testing.T) {
+	// Create a new mock service
+	mockService := new(MockService)
+
+	// Set expectation
+	mockService.On("Stats", context.Background()).Return(model.CacheStats{Len: 1, Size: 10, Bytes: 12, MaxBytes: 100, Admitted: 3, Rejected: 4}, nil)
+
+	// Create the handler with the mocked service
+	handler := &Implementation{cacheService: mockService}
+
+	// Create a new HTTP request to test the handler
+	req, err := http.NewRequest("GET", "/api/stats", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a ResponseRecorder to capture the response
+	rr := httptest.NewRecorder()
+
+	// Call the handler
+	handler.Stats(rr, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"len":1,"size":10,"bytes":12,"max_bytes":100,"admitted":3,"rejected":4}`, rr.Body.String())
+
+	// Assert that the expectations were met
+	mockService.AssertExpectations(t)
+}
+
+func TestStats_Error(t *testing.T) {
+	// Create a new mock service
+	mockService := new(MockService)
+
+	// Set expectation
+	mockService.On("Stats", context.Background()).Return(model.CacheStats{}, fmt.Errorf("some error"))
+
+	// Create the handler with the mocked service
+	handler := &Implementation{cacheService: mockService}
+
+	// Create a new HTTP request to test the handler
+	req, err := http.NewRequest("GET", "/api/stats", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a ResponseRecorder to capture the response
+	rr := httptest.NewRecorder()
+
+	// Call the handler
+	handler.Stats(rr, req)
+
+	// Assert the response
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	// Assert that the expectations were met
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+)
+
+// Txn обеспечивает атомарное выполнение транзакции: условия проверяются, а операции выполняются
+// по порядку, все или ни одной.
+//
+// Если транзакция выполнена, возвращаются результаты операций со статусами, которые вернули бы
+// одиночные операции. Если условие не выполнено, возвращается 412 Precondition Failed с номером
+// условия, если операция завершилась ошибкой - 409 Conflict (нечисловое значение или переполнение для incr)
+// или 413 Request Entity Too Large с номером операции.
+func (i *Implementation) Txn(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method Txn() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method Txn() done with time " + time.Since(timeStart).String())
+	}()
+
+	var rawData desc.TxnData
+	if status := readBatch(r, &rawData, func() int { return len(rawData.Conditions) + len(rawData.Ops) }); status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+
+	convertedData, ok := toTxnData(rawData)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	results, err := i.cacheServiceFor(r).Txn(context.Background(), convertedData)
+	var txnErr *repository.TxnError
+	if errors.As(err, &txnErr) {
+		sendData := desc.TxnResultData{Error: txnErr.Err.Error()}
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, repository.ErrConditionFailed):
+			sendData.FailedCondition = &txnErr.Cond
+			status = http.StatusPreconditionFailed
+		case errors.Is(err, repository.ErrNotNumeric), errors.Is(err, repository.ErrOverflow):
+			sendData.FailedOp = &txnErr.Op
+			status = http.StatusConflict
+		case errors.Is(err, repository.ErrTooLarge):
+			sendData.FailedOp = &txnErr.Op
+			status = http.StatusRequestEntityTooLarge
+		default:
+			sendData.FailedOp = &txnErr.Op
+		}
+		writeTxn(w, sendData, status)
+		return
+	}
+	if errors.Is(err, model.ErrInvalidEntry) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendData := desc.TxnResultData{Results: make([]desc.EntryResultData, len(results))}
+	for idx, result := range results {
+		status := http.StatusOK
+		switch {
+		case errors.Is(result.Err, repository.ErrExpired):
+			status = http.StatusGone
+		case errors.Is(result.Err, repository.ErrNotFound):
+			status = http.StatusNotFound
+		case result.Err != nil:
+			status = http.StatusInternalServerError
+		case convertedData.Ops[idx].Op == model.TxnOpPut:
+			status = http.StatusCreated
+			result.Entry.Value = nil
+		case convertedData.Ops[idx].Op == model.TxnOpEvict:
+			// Как и DELETE /api/lru/{key}, удаление не возвращает значения
+			status = http.StatusNoContent
+			result.Entry.Value = nil
+		}
+		sendData.Results[idx] = converter.ToEntryResultDataFromModel(result, status)
+	}
+	writeTxn(w, sendData, http.StatusOK)
+}
+
+// toTxnData конвертирует транзакцию из API-слоя в Entities и проверяет ее поля
+func toTxnData(data desc.TxnData) (model.TxnData, bool) {
+	converted := model.TxnData{
+		Conditions: make([]model.TxnCondition, len(data.Conditions)),
+		Ops:        make([]model.TxnOp, len(data.Ops)),
+	}
+
+	for idx, cond := range data.Conditions {
+		switch cond.Cond {
+		case desc.TxnCondExists, desc.TxnCondAbsent, desc.TxnCondVersion:
+		default:
+			return model.TxnData{}, false
+		}
+		if len(cond.Key) == 0 {
+			return model.TxnData{}, false
+		}
+		converted.Conditions[idx] = model.TxnCondition{Cond: cond.Cond, Key: cond.Key, Version: cond.Version}
+	}
+
+	for idx, op := range data.Ops {
+		putData := converter.ToEntryPutDataFromDesc(op.EntryPutData)
+		if len(op.Mode) > 0 {
+			return model.TxnData{}, false
+		}
+
+		converted.Ops[idx] = model.TxnOp{Op: op.Op, Data: putData}
+		switch op.Op {
+		case desc.TxnOpPut, desc.TxnOpEvict:
+		case desc.TxnOpIncr:
+			delta, ok := parseDelta(op.Delta)
+			if !ok {
+				return model.TxnData{}, false
+			}
+			converted.Ops[idx].Delta = delta
+		default:
+			return model.TxnData{}, false
+		}
+	}
+
+	return converted, true
+}
+
+// writeTxn отправляет результат транзакции со статусом status
+func writeTxn(w http.ResponseWriter, data desc.TxnResultData, status int) {
+	sendDataBytes, err := json.Marshal(data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(sendDataBytes)
+}
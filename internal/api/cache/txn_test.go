@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestTxn(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Txn", mock.Anything, model.TxnData{
+		Conditions: []model.TxnCondition{{Cond: "version", Key: "user", Version: 3}},
+		Ops: []model.TxnOp{
+			{Op: "put", Data: model.EntryPutData{Key: "user", Value: "x"}},
+			{Op: "evict", Data: model.EntryPutData{Key: "index:old"}},
+			{Op: "incr", Data: model.EntryPutData{Key: "count"}, Delta: int64(1)},
+		},
+	}).Return([]model.EntryResult{
+		{Entry: model.EntryGetData{Key: "user", Value: "x", Version: 4}},
+		{Entry: model.EntryGetData{Key: "index:old"}, Err: repository.ErrNotFound},
+		{Entry: model.EntryGetData{Key: "count", Value: int64(2), Version: 5}},
+	}, nil)
+	handler := &Implementation{cacheService: mockService}
+
+	req, err := http.NewRequest("POST", "/_txn", strings.NewReader(`{
+		"conditions": [{"cond": "version", "key": "user", "version": 3}],
+		"ops": [
+			{"op": "put", "key": "user", "value": "x"},
+			{"op": "evict", "key": "index:old"},
+			{"op": "incr", "key": "count"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.Txn(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"results":[`+
+		`{"key":"user","status":201,"version":4},`+
+		`{"key":"index:old","status":404},`+
+		`{"key":"count","status":200,"value":2,"version":5}]}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestTxn_Aborted(t *testing.T) {
+	mockService := new(MockService)
+	mockService.On("Txn", mock.Anything, model.TxnData{
+		Conditions: []model.TxnCondition{{Cond: "absent", Key: "a"}},
+		Ops:        []model.TxnOp{{Op: "put", Data: model.EntryPutData{Key: "a"}}},
+	}).Return([]model.EntryResult(nil), &repository.TxnError{Cond: 0, Op: -1, Err: repository.ErrConditionFailed})
+	mockService.On("Txn", mock.Anything, model.TxnData{
+		Conditions: []model.TxnCondition{},
+		Ops:        []model.TxnOp{{Op: "incr", Data: model.EntryPutData{Key: "a"}, Delta: 0.5}},
+	}).Return([]model.EntryResult(nil), &repository.TxnError{Cond: -1, Op: 0, Err: repository.ErrNotNumeric})
+	handler := &Implementation{cacheService: mockService}
+
+	tests := []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{
+			`{"conditions":[{"cond":"absent","key":"a"}],"ops":[{"op":"put","key":"a"}]}`,
+			http.StatusPreconditionFailed,
+			`{"failed_condition":0,"error":"lru: transaction condition failed"}`,
+		},
+		{
+			`{"ops":[{"op":"incr","key":"a","delta":0.5}]}`,
+			http.StatusConflict,
+			`{"failed_op":0,"error":"repository: value is not a number"}`,
+		},
+		{`{"ops":[{"op":"rename","key":"a"}]}`, http.StatusBadRequest, ``},
+		{`{"conditions":[{"cond":"exists"}]}`, http.StatusBadRequest, ``},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("POST", "/_txn", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.Txn(rr, req)
+		assert.Equal(t, tt.status, rr.Code, tt.body)
+		assert.Equal(t, tt.expected, rr.Body.String(), tt.body)
+	}
+
+	mockService.AssertExpectations(t)
+}
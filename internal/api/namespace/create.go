@@ -0,0 +1,64 @@
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+)
+
+// Create обеспечивает создание пространства имен во время работы сервера.
+//
+// Не заданные параметры кэша наследуются от пространства имен по умолчанию. В ответе возвращаются
+// итоговые параметры (201 Created); если имя уже занято, возвращается 409 Conflict, а если имя
+// или параметры некорректны - 400 Bad Request.
+func (i *Implementation) Create(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method Create() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method Create() done with time " + time.Since(timeStart).String())
+	}()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var rawData desc.NamespaceData
+	err = json.Unmarshal(body, &rawData)
+	if err != nil || rawData.DefaultTTLSeconds < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	info, err := i.namespaceService.Create(context.Background(), rawData.Name, converter.ToNamespaceConfigFromDesc(rawData))
+	switch {
+	case errors.Is(err, service.ErrInvalidNamespace):
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrNamespaceExists):
+		w.WriteHeader(http.StatusConflict)
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendDataBytes, err := json.Marshal(converter.ToNamespaceDataFromModel(info))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(sendDataBytes)
+}
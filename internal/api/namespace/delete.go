@@ -0,0 +1,37 @@
+package namespace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+// Delete обеспечивает удаление пространства имен вместе со всеми его записями.
+// Пространство имен по умолчанию удалить нельзя (409 Conflict).
+func (i *Implementation) Delete(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method Delete() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method Delete() done with time " + time.Since(timeStart).String())
+	}()
+
+	err := i.namespaceService.Delete(context.Background(), chi.URLParam(r, "namespace"))
+	switch {
+	case errors.Is(err, service.ErrNamespaceNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrDefaultNamespace):
+		w.WriteHeader(http.StatusConflict)
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
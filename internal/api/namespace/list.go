@@ -0,0 +1,41 @@
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/converter"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+)
+
+// List обеспечивает получение всех пространств имен с параметрами их кэшей
+func (i *Implementation) List(w http.ResponseWriter, r *http.Request) {
+	timeStart := time.Now()
+	log.Debug().Msg("API implementation method List() requested by: " + r.Method + " " + r.URL.Path)
+	defer func() {
+		log.Debug().Msg("API implementation method List() done with time " + time.Since(timeStart).String())
+	}()
+
+	infos, err := i.namespaceService.List(context.Background())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sendData := desc.NamespacesData{Namespaces: make([]desc.NamespaceData, 0, len(infos))}
+	for _, info := range infos {
+		sendData.Namespaces = append(sendData.Namespaces, converter.ToNamespaceDataFromModel(info))
+	}
+
+	sendDataBytes, err := json.Marshal(sendData)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(sendDataBytes)
+}
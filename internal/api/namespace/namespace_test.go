@@ -0,0 +1,124 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+func TestList(t *testing.T) {
+	mockService := new(MockNamespaceService)
+	mockService.On("List", context.Background()).Return([]model.NamespaceInfo{
+		{Name: "default", Config: model.NamespaceConfig{Size: 10, DefaultTTL: time.Minute, Shards: 1, EvictionPolicy: "lru"}},
+		{Name: "team-a", Config: model.NamespaceConfig{Size: 100, DefaultTTL: time.Hour, Shards: 4, MaxBytes: 1024, EvictionPolicy: "lfu"}},
+	}, nil)
+
+	handler := &Implementation{namespaceService: mockService}
+
+	req, err := http.NewRequest("GET", "/api/admin/namespaces", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	handler.List(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"namespaces":[
+		{"name":"default","cache_size":10,"default_ttl_seconds":60,"cache_shards":1,"eviction_policy":"lru"},
+		{"name":"team-a","cache_size":100,"default_ttl_seconds":3600,"cache_shards":4,"cache_max_bytes":1024,"eviction_policy":"lfu"}
+	]}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		callErr    error
+		wantStatus int
+		wantBody   string
+	}{
+		{"Created", `{"name":"team-a","cache_size":100,"default_ttl_seconds":30}`, nil, http.StatusCreated,
+			`{"name":"team-a","cache_size":100,"default_ttl_seconds":30,"cache_shards":1,"eviction_policy":"lru"}`},
+		{"Exists", `{"name":"team-a","cache_size":100,"default_ttl_seconds":30}`, service.ErrNamespaceExists, http.StatusConflict, ""},
+		{"Invalid", `{"name":"team-a","cache_size":100,"default_ttl_seconds":30}`, fmt.Errorf("%w: bad size", service.ErrInvalidNamespace), http.StatusBadRequest, ""},
+		{"Internal", `{"name":"team-a","cache_size":100,"default_ttl_seconds":30}`, fmt.Errorf("some error"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNamespaceService)
+			mockService.On("Create", context.Background(), "team-a", model.NamespaceConfig{Size: 100, DefaultTTL: 30 * time.Second}).
+				Return(model.NamespaceInfo{Name: "team-a", Config: model.NamespaceConfig{Size: 100, DefaultTTL: 30 * time.Second, Shards: 1, EvictionPolicy: "lru"}}, tt.callErr)
+
+			handler := &Implementation{namespaceService: mockService}
+
+			req, err := http.NewRequest("POST", "/api/admin/namespaces", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			handler.Create(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if len(tt.wantBody) > 0 {
+				assert.JSONEq(t, tt.wantBody, rr.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreate_BadRequest(t *testing.T) {
+	for _, body := range []string{`not json`, `{"name":"team-a","default_ttl_seconds":-1}`} {
+		mockService := new(MockNamespaceService)
+		handler := &Implementation{namespaceService: mockService}
+
+		req, err := http.NewRequest("POST", "/api/admin/namespaces", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		handler.Create(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		mockService.AssertNotCalled(t, "Create")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name       string
+		callErr    error
+		wantStatus int
+	}{
+		{"Deleted", nil, http.StatusNoContent},
+		{"NotFound", service.ErrNamespaceNotFound, http.StatusNotFound},
+		{"Default", service.ErrDefaultNamespace, http.StatusConflict},
+		{"Internal", fmt.Errorf("some error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNamespaceService)
+			mockService.On("Delete", context.Background(), "team-a").Return(tt.callErr)
+
+			handler := &Implementation{namespaceService: mockService}
+
+			req := newNamespaceRequest(t, "DELETE", "/api/admin/namespaces/team-a", "team-a", nil)
+			rr := httptest.NewRecorder()
+			handler.Delete(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+// Package namespace содержит определение имплементации административного API пространств имен
+package namespace
+
+import (
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+// Implementation задает поля в имплементации административного API пространств имен
+type Implementation struct {
+	namespaceService service.NamespaceService
+}
+
+// NewImplementation создает новую имплементацию
+func NewImplementation(namespaceService service.NamespaceService) *Implementation {
+	return &Implementation{
+		namespaceService: namespaceService,
+	}
+}
@@ -0,0 +1,59 @@
+package namespace
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+type MockNamespaceService struct {
+	mock.Mock
+}
+
+func (m *MockNamespaceService) Namespace(ctx context.Context, name string) (service.CacheService, error) {
+	args := m.Called(ctx, name)
+	cacheService, _ := args.Get(0).(service.CacheService)
+	return cacheService, args.Error(1)
+}
+
+func (m *MockNamespaceService) Create(ctx context.Context, name string, cfg model.NamespaceConfig) (model.NamespaceInfo, error) {
+	args := m.Called(ctx, name, cfg)
+	return args.Get(0).(model.NamespaceInfo), args.Error(1)
+}
+
+func (m *MockNamespaceService) Delete(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockNamespaceService) List(ctx context.Context) ([]model.NamespaceInfo, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.NamespaceInfo), args.Error(1)
+}
+
+func (m *MockNamespaceService) Close(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockNamespaceService) SetHooks(hooks service.NamespaceHooks) {
+	m.Called(hooks)
+}
+
+// newNamespaceRequest создает HTTP-запрос с параметром маршрута namespace, как его передал бы chi
+func newNamespaceRequest(t *testing.T, method, target, name string, body io.Reader) *http.Request {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("namespace", name)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
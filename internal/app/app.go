@@ -13,7 +13,9 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/api/cache"
 	"github.com/vitbogit/golang-cache-lru/internal/config"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
 )
 
 const (
@@ -24,6 +26,8 @@ const (
 type App struct {
 	serviceProvider *serviceProvider // Менеджер зависимых частей приложения
 	httpServer      *http.Server     // HTTP-сервер
+	snapshotter     *snapshotter     // Запись и загрузка снимков кэша, nil - снимки отключены
+	opLogger        *opLogger        // Журналы изменений кэша, nil - журналы отключены
 }
 
 // NewApp создает новое приложение и вызывает функцию для инициализации
@@ -111,7 +115,7 @@ func (a *App) initConfigAndLogger(_ context.Context) error {
 }
 
 // initServiceProvider инициализирует service provider
-func (a *App) initServiceProvider(_ context.Context) error {
+func (a *App) initServiceProvider(ctx context.Context) error {
 	log.Debug().Msg("Initing service provider")
 
 	a.serviceProvider = newServiceProvider()
@@ -121,6 +125,24 @@ func (a *App) initServiceProvider(_ context.Context) error {
 	log.Debug().Msg(fmt.Sprintf("using App config: %+v", a.serviceProvider.AppConfig()))
 	log.Debug().Msg(fmt.Sprintf("using HTTP config: %+v", a.serviceProvider.HTTPConfig()))
 	log.Debug().Msg(fmt.Sprintf("using Cache config: %+v", a.serviceProvider.CacheConfig()))
+	log.Debug().Msg(fmt.Sprintf("using Snapshot config: %+v", a.serviceProvider.SnapshotConfig()))
+	log.Debug().Msg(fmt.Sprintf("using OpLog config: %+v", a.serviceProvider.OpLogConfig()))
+
+	// Снимки загружаются до запуска сервера, чтобы первые запросы уже попадали в прогретый кэш
+	if cfg := a.serviceProvider.SnapshotConfig(); len(cfg.Path()) > 0 {
+		a.snapshotter = newSnapshotter(cfg.Path(), cfg.Interval(), a.serviceProvider.NamespaceService())
+		a.snapshotter.restore(ctx)
+	}
+
+	// Журналы применяются поверх снимков: в них есть изменения, сделанные после записи последнего снимка
+	if cfg := a.serviceProvider.OpLogConfig(); len(cfg.Path()) > 0 {
+		a.opLogger = newOpLogger(model.OpLogConfig{
+			Path:            cfg.Path(),
+			Fsync:           cfg.Fsync(),
+			CompactMinBytes: cfg.CompactMinBytes(),
+		}, a.serviceProvider.NamespaceService())
+		a.opLogger.open(ctx)
+	}
 
 	log.Debug().Msg("Sucessfully inited service provider")
 	return nil
@@ -136,14 +158,20 @@ func (a *App) initHTTPServer(_ context.Context) error {
 		w.Write([]byte("cache service homepage!"))
 	})
 
-	r.Route("/api/lru", func(r chi.Router) {
-		r.Post("/", a.serviceProvider.CacheImpl().Put)
-
-		r.Get("/{key}", a.serviceProvider.CacheImpl().Get)
-		r.Get("/", a.serviceProvider.CacheImpl().GetAll)
+	// Пространство имен по умолчанию доступно по прежним маршрутам, остальные - по /api/ns/{namespace}.
+	// Статистика отдается рядом с маршрутами кэша, а не под ними, чтобы не пересекаться с ключами записей.
+	r.Route("/api/lru", a.cacheRoutes)
+	r.Get("/api/stats", a.serviceProvider.CacheImpl().Stats)
+	r.Route("/api/ns/{namespace}", func(r chi.Router) {
+		r.Use(cache.WithNamespace(a.serviceProvider.NamespaceService()))
+		r.Route("/lru", a.cacheRoutes)
+		r.Get("/stats", a.serviceProvider.CacheImpl().Stats)
+	})
 
-		r.Delete("/{key}", a.serviceProvider.CacheImpl().Evict)
-		r.Delete("/", a.serviceProvider.CacheImpl().EvictAll)
+	r.Route("/api/admin/namespaces", func(r chi.Router) {
+		r.Post("/", a.serviceProvider.NamespaceImpl().Create)
+		r.Get("/", a.serviceProvider.NamespaceImpl().List)
+		r.Delete("/{namespace}", a.serviceProvider.NamespaceImpl().Delete)
 	})
 
 	a.httpServer = &http.Server{
@@ -155,6 +183,22 @@ func (a *App) initHTTPServer(_ context.Context) error {
 	return nil
 }
 
+// cacheRoutes задает маршруты API кэша, общие для всех пространств имен
+func (a *App) cacheRoutes(r chi.Router) {
+	r.Post("/", a.serviceProvider.CacheImpl().Put)
+	r.Post("/_mget", a.serviceProvider.CacheImpl().MGet)
+	r.Post("/_mput", a.serviceProvider.CacheImpl().MPut)
+	r.Post("/_mdelete", a.serviceProvider.CacheImpl().MDelete)
+	r.Post("/_txn", a.serviceProvider.CacheImpl().Txn)
+	r.Post("/{key}/incr", a.serviceProvider.CacheImpl().Incr)
+
+	r.Get("/{key}", a.serviceProvider.CacheImpl().Get)
+	r.Get("/", a.serviceProvider.CacheImpl().GetAll)
+
+	r.Delete("/{key}", a.serviceProvider.CacheImpl().Evict)
+	r.Delete("/", a.serviceProvider.CacheImpl().EvictAll)
+}
+
 // runHTTPServer запускает HTTP-сервер
 func (a *App) runHTTPServer(ctx context.Context) error {
 	// Запуск сервера в горутине
@@ -165,6 +209,14 @@ func (a *App) runHTTPServer(ctx context.Context) error {
 		}
 	}()
 
+	// Периодическая запись снимков останавливается вместе с сервером
+	if a.snapshotter != nil {
+		go a.snapshotter.run(ctx)
+	}
+	if a.opLogger != nil {
+		go a.opLogger.run(ctx)
+	}
+
 	// Завершаем сервер при отмене контекста
 	<-ctx.Done()
 	log.Info().Msg("отключение HTTP сервера...")
@@ -179,5 +231,22 @@ func (a *App) runHTTPServer(ctx context.Context) error {
 		log.Info().Msg("server gracefully stopped")
 	}
 
+	// Снимок записывается после остановки сервера, чтобы в него попали все завершенные запросы
+	if a.snapshotter != nil {
+		if err := a.snapshotter.save(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("не удалось записать снимок кэша")
+		} else {
+			log.Info().Msg("снимок кэша записан")
+		}
+	}
+
+	// Кэши закрываются только после остановки сервера, чтобы обработчики успели завершить запросы.
+	// Вместе с кэшами на диск сбрасываются и закрываются их журналы изменений.
+	if err := a.serviceProvider.NamespaceService().Close(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("не удалось корректно закрыть кэш")
+	} else {
+		log.Info().Msg("кэш закрыт")
+	}
+
 	return nil
 }
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+const (
+	opLogCompactCheckInterval = 10 * time.Second // Период проверки, не пора ли сжать журналы изменений
+)
+
+// opLogger включает журналы изменений кэшей всех пространств имен и периодически сжимает их.
+// Журнал пространства имен по умолчанию пишется в файл из конфига, журналы остальных - в соседние файлы
+// с именем пространства имен перед расширением (как и снимки).
+//
+// Рядом с журналами ведется список пространств имен с параметрами их кэшей (см. manifestPath), который
+// переписывается при создании и удалении пространств имен. Поэтому при запуске журналы пространств имен,
+// созданных во время работы сервера, применяются, даже если снимки отключены.
+type opLogger struct {
+	cfg        model.OpLogConfig        // Параметры журнала пространства имен по умолчанию
+	namespaces service.NamespaceService // Пространства имен, изменения кэшей которых записываются
+
+	mu       sync.Mutex                   // Не допускает одновременной записи списка пространств имен
+	manifest map[string]manifestNamespace // Пространства имен с журналами, кроме пространства имен по умолчанию
+}
+
+// newOpLogger создает opLogger для пространств имен namespaces
+func newOpLogger(cfg model.OpLogConfig, namespaces service.NamespaceService) *opLogger {
+	return &opLogger{
+		cfg:        cfg,
+		namespaces: namespaces,
+		manifest:   make(map[string]manifestNamespace),
+	}
+}
+
+// manifestPath возвращает путь к списку пространств имен, например cache.oplog.namespaces.json для cache.oplog
+func (l *opLogger) manifestPath() string {
+	return l.cfg.Path + ".namespaces.json"
+}
+
+// open создает пространства имен из списка, записанного вместе с журналами, применяет журналы всех
+// существующих пространств имен поверх загруженных снимков и включает запись в них. Журнал, который
+// не удалось применить, не перезаписывается, а изменения его пространства имен не записываются,
+// но это не мешает запуску. Журналы пространств имен, созданных во время работы, открываются
+// при создании, а журналы удаленных - удаляются вместе с ними.
+func (l *opLogger) open(ctx context.Context) {
+	// Пространства имен из списка создаются до регистрации действий, чтобы их журналы открывались один раз
+	restoreManifestNamespaces(ctx, l.namespaces, l.manifestPath())
+
+	infos, err := l.namespaces.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("не удалось получить список пространств имен для открытия журналов изменений")
+		return
+	}
+
+	for _, info := range infos {
+		cacheService, err := l.namespaces.Namespace(ctx, info.Name)
+		if err != nil {
+			log.Error().Err(err).Msg("не удалось открыть журнал изменений " + namespacePath(l.cfg.Path, info.Name))
+			continue
+		}
+		l.openNamespace(ctx, info.Name, cacheService)
+	}
+
+	l.mu.Lock()
+	for _, info := range infos {
+		if info.Name != service.DefaultNamespace {
+			l.manifest[info.Name] = newManifestNamespace(info)
+		}
+	}
+	l.saveManifest()
+	l.mu.Unlock()
+
+	l.namespaces.SetHooks(service.NamespaceHooks{
+		OnCreate: l.createNamespace,
+		OnDelete: l.removeNamespace,
+	})
+}
+
+// openNamespace применяет журнал пространства имен name к его кэшу и включает запись в журнал
+func (l *opLogger) openNamespace(ctx context.Context, name string, cacheService service.CacheService) {
+	cfg := l.cfg
+	cfg.Path = namespacePath(l.cfg.Path, name)
+
+	n, err := cacheService.OpenLog(ctx, cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("журнал изменений " + cfg.Path + " не применен, изменения пространства имен " + name + " не записываются")
+		return
+	}
+	log.Info().Msg(fmt.Sprintf("из журнала изменений %s применено изменений: %d", cfg.Path, n))
+}
+
+// createNamespace открывает журнал созданного пространства имен info и добавляет его в список
+func (l *opLogger) createNamespace(ctx context.Context, info model.NamespaceInfo, cacheService service.CacheService) {
+	l.openNamespace(ctx, info.Name, cacheService)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.manifest[info.Name] = newManifestNamespace(info)
+	l.saveManifest()
+}
+
+// removeNamespace удаляет журнал удаленного пространства имен name (он закрывается вместе с кэшем),
+// чтобы пространство имен, созданное позже с тем же именем, не получило его записи, и убирает
+// пространство имен из списка
+func (l *opLogger) removeNamespace(_ context.Context, name string) {
+	// Журнал удаляется раньше списка: при сбое между ними пространство имен восстановится пустым,
+	// а не получит записи удаленного
+	path := namespacePath(l.cfg.Path, name)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error().Err(err).Msg("не удалось удалить журнал изменений " + path)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.manifest, name)
+	l.saveManifest()
+}
+
+// saveManifest записывает список пространств имен, упорядоченный по имени. Вызывается под блокировкой mu.
+func (l *opLogger) saveManifest() {
+	var manifest namespaceManifest
+	for _, ns := range l.manifest {
+		manifest.Namespaces = append(manifest.Namespaces, ns)
+	}
+	sort.Slice(manifest.Namespaces, func(i, j int) bool { return manifest.Namespaces[i].Name < manifest.Namespaces[j].Name })
+
+	if err := writeManifest(l.manifestPath(), manifest); err != nil {
+		log.Error().Err(err).Msg("не удалось записать список пространств имен " + l.manifestPath())
+	}
+}
+
+// compact сжимает разросшиеся журналы всех пространств имен
+func (l *opLogger) compact(ctx context.Context) {
+	infos, err := l.namespaces.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("не удалось получить список пространств имен для сжатия журналов изменений")
+		return
+	}
+
+	for _, info := range infos {
+		cacheService, err := l.namespaces.Namespace(ctx, info.Name)
+		if err != nil {
+			// Пространство имен удалено после получения списка
+			continue
+		}
+
+		// Ошибка уже залогирована сервисом
+		if compacted, _ := cacheService.CompactLog(ctx); compacted {
+			log.Debug().Msg("журнал изменений пространства имен " + info.Name + " сжат")
+		}
+	}
+}
+
+// run периодически сжимает журналы, пока не отменен ctx
+func (l *opLogger) run(ctx context.Context) {
+	ticker := time.NewTicker(opLogCompactCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.compact(ctx)
+		}
+	}
+}
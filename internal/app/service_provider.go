@@ -1,12 +1,21 @@
 package app
 
 import (
+	"context"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
 	"github.com/vitbogit/golang-cache-lru/internal/api/cache"
+	"github.com/vitbogit/golang-cache-lru/internal/api/namespace"
 	"github.com/vitbogit/golang-cache-lru/internal/config"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
 	"github.com/vitbogit/golang-cache-lru/internal/repository"
 	cacheRepository "github.com/vitbogit/golang-cache-lru/internal/repository/cache"
 	"github.com/vitbogit/golang-cache-lru/internal/service"
 	cacheService "github.com/vitbogit/golang-cache-lru/internal/service/cache"
+	namespaceService "github.com/vitbogit/golang-cache-lru/internal/service/namespace"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
 )
 
 // serviceProvider используется для корректного подтягивания зависимых частей приложения.
@@ -18,11 +27,16 @@ type serviceProvider struct {
 	cacheConfig config.CacheConfig // Конфиг кэша
 	appConfig   config.AppConfig   // Конфиг приложения (общие настройки)
 
+	snapshotConfig config.SnapshotConfig // Конфиг снимков кэша
+	opLogConfig    config.OpLogConfig    // Конфиг журнала изменений кэша
+
 	cacheRepository repository.ILRUCache // Кэш база данных
 
-	cacheService service.CacheService // Сервисный слой приложения
+	cacheService     service.CacheService     // Сервисный слой приложения
+	namespaceService service.NamespaceService // Сервисный слой пространств имен
 
-	cacheImpl *cache.Implementation // Имплементация API
+	cacheImpl     *cache.Implementation     // Имплементация API
+	namespaceImpl *namespace.Implementation // Имплементация административного API пространств имен
 }
 
 // newServiceProvider создает пустой serviceProvider
@@ -69,17 +83,109 @@ func (s *serviceProvider) AppConfig() config.AppConfig {
 	return s.appConfig
 }
 
+// SnapshotConfig возвращает конфиг снимков кэша, предварительно проверив его наличие и наличие всех
+// связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо осуществляет
+// попытку дозагрузки.
+func (s *serviceProvider) SnapshotConfig() config.SnapshotConfig {
+	if s.snapshotConfig == nil {
+		cfg := config.NewSnapshotConfig()
+
+		s.snapshotConfig = cfg
+	}
+
+	return s.snapshotConfig
+}
+
+// OpLogConfig возвращает конфиг журнала изменений кэша, предварительно проверив его наличие и наличие всех
+// связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо осуществляет
+// попытку дозагрузки.
+func (s *serviceProvider) OpLogConfig() config.OpLogConfig {
+	if s.opLogConfig == nil {
+		cfg := config.NewOpLogConfig()
+
+		s.opLogConfig = cfg
+	}
+
+	return s.opLogConfig
+}
+
 // CacheRepository возвращает БД (кэщ), предварительно проверив ее наличие и наличие всех
 // связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо осуществляет
 // попытку дозагрузки.
 func (s *serviceProvider) CacheRepository() repository.ILRUCache {
 	if s.cacheRepository == nil {
-		s.cacheRepository = cacheRepository.NewCache(s.CacheConfig().Size(), s.CacheConfig().DefaultTTL())
+		repo, err := newCacheRepository(s.CacheConfig())
+		if err != nil {
+			log.Fatal().Err(err).Msg("не удалось создать кэш")
+		}
+
+		s.cacheRepository = repo
 	}
 
 	return s.cacheRepository
 }
 
+// newCacheRepository создает кэш с параметрами из конфига cfg на выбранном в нем движке хранения записей
+func newCacheRepository(cfg config.CacheConfig) (repository.ILRUCache, error) {
+	if cfg.StorageEngine() == "arena" {
+		return cacheRepository.NewArena(cfg.Size(), cfg.DefaultTTL(), cacheRepository.ArenaOptions{
+			Shards:          cfg.Shards(),
+			MaxBytes:        cfg.MaxBytes(),
+			JanitorInterval: cfg.JanitorInterval(),
+			JanitorBudget:   cfg.JanitorBudget(),
+			PromoteOnRead:   cfg.PromoteOnRead(),
+			LoaderTimeout:   cfg.LoaderTimeout(),
+		})
+	}
+
+	opts := []lru.Option{
+		lru.WithShards(cfg.Shards()),
+		lru.WithJanitor(cfg.JanitorInterval(), cfg.JanitorBudget()),
+		lru.WithMaxCost(cfg.MaxBytes()),
+		lru.WithPolicy(lru.Policy(cfg.EvictionPolicy())),
+		lru.WithPromotion(cfg.PromoteOnRead()),
+		lru.WithLoaderTimeout(cfg.LoaderTimeout()),
+	}
+	if cfg.Admission() {
+		opts = append(opts, lru.WithAdmission())
+	}
+
+	return cacheRepository.NewCache(cfg.Size(), cfg.DefaultTTL(), opts...)
+}
+
+// namespaceConfigOf возвращает параметры кэша пространства имен из конфига cfg
+func namespaceConfigOf(cfg config.CacheConfig) model.NamespaceConfig {
+	return model.NamespaceConfig{
+		Size:           cfg.Size(),
+		DefaultTTL:     cfg.DefaultTTL(),
+		Shards:         cfg.Shards(),
+		MaxBytes:       cfg.MaxBytes(),
+		EvictionPolicy: cfg.EvictionPolicy(),
+	}
+}
+
+// newNamespaceRepository создает кэш пространства имен: не заданные в nsCfg параметры наследуются
+// от конфига кэша по умолчанию
+func (s *serviceProvider) newNamespaceRepository(nsCfg model.NamespaceConfig) (repository.ILRUCache, model.NamespaceConfig, error) {
+	cfg, err := config.NewNamespaceCacheConfig(s.CacheConfig(), config.CacheOverrides{
+		Size:           nsCfg.Size,
+		DefaultTTL:     nsCfg.DefaultTTL,
+		Shards:         nsCfg.Shards,
+		MaxBytes:       nsCfg.MaxBytes,
+		EvictionPolicy: nsCfg.EvictionPolicy,
+	})
+	if err != nil {
+		return nil, model.NamespaceConfig{}, err
+	}
+
+	repo, err := newCacheRepository(cfg)
+	if err != nil {
+		return nil, model.NamespaceConfig{}, err
+	}
+
+	return repo, namespaceConfigOf(cfg), nil
+}
+
 // CacheService возвращает сервис приложения, предварительно проверив его наличие и наличие всех
 // связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо осуществляет
 // попытку дозагрузки.
@@ -93,6 +199,38 @@ func (s *serviceProvider) CacheService() service.CacheService {
 	return s.cacheService
 }
 
+// NamespaceService возвращает сервис пространств имен, предварительно проверив его наличие и наличие всех
+// связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо осуществляет
+// попытку дозагрузки. Пространства имен, объявленные в конфиге кэша, создаются сразу.
+func (s *serviceProvider) NamespaceService() service.NamespaceService {
+	if s.namespaceService == nil {
+		nsService := namespaceService.NewService(
+			s.CacheService(),
+			s.CacheRepository(),
+			namespaceConfigOf(s.CacheConfig()),
+			s.newNamespaceRepository,
+		)
+
+		namespaces := s.CacheConfig().Namespaces()
+		names := make([]string, 0, len(namespaces))
+		for name := range namespaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			_, err := nsService.Create(context.Background(), name, namespaceConfigOf(namespaces[name]))
+			if err != nil {
+				log.Fatal().Err(err).Msg("не удалось создать пространство имен " + name)
+			}
+		}
+
+		s.namespaceService = nsService
+	}
+
+	return s.namespaceService
+}
+
 // CacheImpl возвращает имплементацию API приложения, предварительно проверив ее наличие и наличие всех
 // связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо осуществляет
 // попытку дозагрузки.
@@ -103,3 +241,14 @@ func (s *serviceProvider) CacheImpl() *cache.Implementation {
 
 	return s.cacheImpl
 }
+
+// NamespaceImpl возвращает имплементацию административного API пространств имен, предварительно проверив
+// ее наличие и наличие всех связанных с ним зависимых частей приложения, а в случае отсутствия чего-либо
+// осуществляет попытку дозагрузки.
+func (s *serviceProvider) NamespaceImpl() *namespace.Implementation {
+	if s.namespaceImpl == nil {
+		s.namespaceImpl = namespace.NewImplementation(s.NamespaceService())
+	}
+
+	return s.namespaceImpl
+}
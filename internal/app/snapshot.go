@@ -0,0 +1,321 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/service"
+)
+
+// namespaceManifest задает файл со списком пространств имен, снимки или журналы изменений которых записаны рядом
+type namespaceManifest struct {
+	Namespaces []manifestNamespace `json:"namespaces"`
+}
+
+// manifestNamespace задает пространство имен в списке и параметры его кэша
+type manifestNamespace struct {
+	Name           string        `json:"name"`
+	Size           int           `json:"cache_size"`
+	DefaultTTL     time.Duration `json:"default_ttl_ns"`
+	Shards         int           `json:"cache_shards"`
+	MaxBytes       int64         `json:"cache_max_bytes,omitempty"`
+	EvictionPolicy string        `json:"eviction_policy"`
+}
+
+// newManifestNamespace возвращает пространство имен info для записи в список
+func newManifestNamespace(info model.NamespaceInfo) manifestNamespace {
+	return manifestNamespace{
+		Name:           info.Name,
+		Size:           info.Config.Size,
+		DefaultTTL:     info.Config.DefaultTTL,
+		Shards:         info.Config.Shards,
+		MaxBytes:       info.Config.MaxBytes,
+		EvictionPolicy: info.Config.EvictionPolicy,
+	}
+}
+
+// config возвращает параметры кэша пространства имен из списка
+func (ns manifestNamespace) config() model.NamespaceConfig {
+	return model.NamespaceConfig{
+		Size:           ns.Size,
+		DefaultTTL:     ns.DefaultTTL,
+		Shards:         ns.Shards,
+		MaxBytes:       ns.MaxBytes,
+		EvictionPolicy: ns.EvictionPolicy,
+	}
+}
+
+// snapshotter периодически записывает снимки кэшей всех пространств имен на диск и восстанавливает
+// их при запуске приложения. Снимок пространства имен по умолчанию записывается в файл из конфига,
+// снимки остальных - в соседние файлы с именем пространства имен перед расширением.
+//
+// Рядом со снимками записывается список пространств имен с параметрами их кэшей (см. manifestPath),
+// поэтому при запуске восстанавливаются и пространства имен, созданные во время работы сервера.
+type snapshotter struct {
+	path       string                   // Путь к файлу снимка пространства имен по умолчанию
+	interval   time.Duration            // Период записи снимков, 0 - только при остановке
+	namespaces service.NamespaceService // Пространства имен, кэши которых сохраняются
+
+	mu sync.Mutex // Не допускает одновременной записи снимков (периодической и при остановке)
+}
+
+// newSnapshotter создает snapshotter для пространств имен namespaces
+func newSnapshotter(path string, interval time.Duration, namespaces service.NamespaceService) *snapshotter {
+	return &snapshotter{
+		path:       path,
+		interval:   interval,
+		namespaces: namespaces,
+	}
+}
+
+// pathFor возвращает путь к файлу снимка пространства имен
+func (s *snapshotter) pathFor(name string) string {
+	return namespacePath(s.path, name)
+}
+
+// namespacePath возвращает путь к файлу пространства имен name по пути path к файлу пространства имен
+// по умолчанию, например cache.team-a.ndjson для cache.ndjson
+func namespacePath(path, name string) string {
+	if name == service.DefaultNamespace {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + name + ext
+}
+
+// manifestPath возвращает путь к списку пространств имен, например cache.ndjson.namespaces.json для
+// cache.ndjson. Имена пространств имен не содержат точек, поэтому путь не совпадает с путем их снимков.
+func (s *snapshotter) manifestPath() string {
+	return s.path + ".namespaces.json"
+}
+
+// restore создает пространства имен из списка, записанного вместе со снимками, и загружает снимки всех
+// существующих пространств имен. Пространства имен, объявленные в конфиге, создаются с параметрами
+// из конфига. Отсутствующий или поврежденный снимок не мешает запуску: пространство имен остается пустым.
+func (s *snapshotter) restore(ctx context.Context) {
+	s.restoreNamespaces(ctx)
+
+	infos, err := s.namespaces.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("не удалось получить список пространств имен для загрузки снимков")
+		return
+	}
+
+	for _, info := range infos {
+		path := s.pathFor(info.Name)
+
+		n, err := s.restoreNamespace(ctx, info.Name, path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Info().Msg("снимок кэша " + path + " не найден, пространство имен " + info.Name + " запускается пустым")
+		case err != nil:
+			log.Error().Err(err).Msg("не удалось загрузить снимок кэша " + path)
+		default:
+			log.Info().Msg(fmt.Sprintf("из снимка %s загружено записей: %d", path, n))
+		}
+	}
+}
+
+// restoreNamespaces создает недостающие пространства имен из списка, записанного вместе со снимками
+func (s *snapshotter) restoreNamespaces(ctx context.Context) {
+	restoreManifestNamespaces(ctx, s.namespaces, s.manifestPath())
+}
+
+// restoreManifestNamespaces создает недостающие пространства имен namespaces из списка, записанного в файл path.
+// Отсутствующий список пропускается, а пространства имен, объявленные в конфиге, уже существуют.
+func restoreManifestNamespaces(ctx context.Context, namespaces service.NamespaceService, path string) {
+	manifest, err := readManifest(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("не удалось загрузить список пространств имен " + path)
+		return
+	}
+
+	for _, ns := range manifest.Namespaces {
+		_, err := namespaces.Create(ctx, ns.Name, ns.config())
+		switch {
+		case errors.Is(err, service.ErrNamespaceExists):
+			// Пространство имен объявлено в конфиге или уже восстановлено из другого списка
+		case err != nil:
+			log.Error().Err(err).Msg("не удалось восстановить пространство имен " + ns.Name)
+		default:
+			log.Info().Msg("восстановлено пространство имен " + ns.Name)
+		}
+	}
+}
+
+// readManifest читает список пространств имен из файла path
+func readManifest(path string) (namespaceManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return namespaceManifest{}, err
+	}
+
+	var manifest namespaceManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return namespaceManifest{}, err
+	}
+	return manifest, nil
+}
+
+// writeManifest записывает список пространств имен в файл path (см. writeFileAtomic)
+func writeManifest(path string, manifest namespaceManifest) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(manifest)
+	})
+}
+
+// restoreNamespace загружает снимок пространства имен name из файла path
+func (s *snapshotter) restoreNamespace(ctx context.Context, name, path string) (int, error) {
+	cacheService, err := s.namespaces.Namespace(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return cacheService.Restore(ctx, f)
+}
+
+// save записывает снимки всех пространств имен и их список. Ошибка одного снимка не мешает записи остальных.
+// Снимки пространств имен, удаленных с прошлой записи, удаляются.
+func (s *snapshotter) save(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos, err := s.namespaces.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs     []error
+		manifest namespaceManifest
+		saved    = make(map[string]struct{}, len(infos))
+	)
+	for _, info := range infos {
+		path := s.pathFor(info.Name)
+		saved[info.Name] = struct{}{}
+
+		n, err := s.saveNamespace(ctx, info.Name, path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("snapshot %s: %w", path, err))
+			continue
+		}
+		log.Debug().Msg(fmt.Sprintf("в снимок %s записано записей: %d", path, n))
+
+		if info.Name != service.DefaultNamespace {
+			manifest.Namespaces = append(manifest.Namespaces, newManifestNamespace(info))
+		}
+	}
+
+	previous, err := readManifest(s.manifestPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error().Err(err).Msg("не удалось загрузить прежний список пространств имен " + s.manifestPath())
+	}
+
+	if err := writeManifest(s.manifestPath(), manifest); err != nil {
+		return errors.Join(append(errs, fmt.Errorf("namespaces %s: %w", s.manifestPath(), err))...)
+	}
+
+	// Снимки удаляются только после записи нового списка, иначе при сбое пространство имен
+	// восстановилось бы пустым
+	for _, ns := range previous.Namespaces {
+		if _, ok := saved[ns.Name]; ok {
+			continue
+		}
+		if err := os.Remove(s.pathFor(ns.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// saveNamespace записывает снимок пространства имен name в файл path (см. writeFileAtomic)
+func (s *snapshotter) saveNamespace(ctx context.Context, name, path string) (n int, err error) {
+	cacheService, err := s.namespaces.Namespace(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeFileAtomic(path, func(w io.Writer) (err error) {
+		n, err = cacheService.Snapshot(ctx, w)
+		return err
+	})
+	return n, err
+}
+
+// writeFileAtomic записывает файл path функцией write. Файл сначала записывается во временный файл
+// рядом с path и только затем заменяет прежний, поэтому сбой во время записи не портит последний
+// удачно записанный файл.
+func writeFileAtomic(path string, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	if err = write(w); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// run записывает снимки с периодом interval, пока не отменен ctx
+func (s *snapshotter) run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.save(ctx); err != nil {
+				log.Error().Err(err).Msg("не удалось записать снимок кэша")
+			}
+		}
+	}
+}
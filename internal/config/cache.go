@@ -3,7 +3,9 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -14,24 +16,92 @@ const (
 	cacheSizeFlagName       = "cache-size"        // Имя флага для параметра размера кэша
 	cacheDefaultTTLEnvName  = "DEFAULT_CACHE_TTL" // Имя переменной окружения для параметра TTL по умолчанию кэша
 	cacheDefaultTTLFlagName = "default-cache-ttl" // Имя флага для параметра TTL по умолчанию кэша
+	cacheShardsEnvName      = "CACHE_SHARDS"      // Имя переменной окружения для параметра количества шардов кэша
+	cacheShardsFlagName     = "cache-shards"      // Имя флага для параметра количества шардов кэша
+
+	cacheJanitorIntervalEnvName  = "CACHE_JANITOR_INTERVAL" // Имя переменной окружения для параметра периода фоновой очистки кэша
+	cacheJanitorIntervalFlagName = "cache-janitor-interval" // Имя флага для параметра периода фоновой очистки кэша
+	cacheJanitorBudgetEnvName    = "CACHE_JANITOR_BUDGET"   // Имя переменной окружения для параметра лимита удалений за проход фоновой очистки
+	cacheJanitorBudgetFlagName   = "cache-janitor-budget"   // Имя флага для параметра лимита удалений за проход фоновой очистки
+	cacheMaxBytesEnvName         = "CACHE_MAX_BYTES"        // Имя переменной окружения для параметра лимита размера кэша в байтах
+	cacheMaxBytesFlagName        = "cache-max-bytes"        // Имя флага для параметра лимита размера кэша в байтах
+	cacheEvictionPolicyEnvName   = "CACHE_EVICTION_POLICY"  // Имя переменной окружения для параметра политики вытеснения кэша
+	cacheEvictionPolicyFlagName  = "cache-eviction-policy"  // Имя флага для параметра политики вытеснения кэша
+	cacheAdmissionEnvName        = "CACHE_ADMISSION"        // Имя переменной окружения для параметра фильтра допуска кэша
+	cacheAdmissionFlagName       = "cache-admission"        // Имя флага для параметра фильтра допуска кэша
+	cachePromoteOnReadEnvName    = "CACHE_PROMOTE_ON_READ"  // Имя переменной окружения для параметра переноса записей при чтении
+	cachePromoteOnReadFlagName   = "cache-promote-on-read"  // Имя флага для параметра переноса записей при чтении
+	cacheLoaderTimeoutEnvName    = "CACHE_LOADER_TIMEOUT"   // Имя переменной окружения для параметра таймаута загрузки записи
+	cacheLoaderTimeoutFlagName   = "cache-loader-timeout"   // Имя флага для параметра таймаута загрузки записи
+	cacheStorageEngineEnvName    = "CACHE_STORAGE_ENGINE"   // Имя переменной окружения для параметра движка хранения записей
+	cacheStorageEngineFlagName   = "cache-storage-engine"   // Имя флага для параметра движка хранения записей
+
+	defaultCacheShards          = 1                      // Количество шардов, если оно не задано ни одним способом (кэш без шардирования)
+	defaultCacheJanitorInterval = 100 * time.Millisecond // Период фоновой очистки, если он не задан ни одним способом
+	defaultCacheJanitorBudget   = 1000                   // Лимит удалений за проход фоновой очистки, если он не задан ни одним способом
+	defaultCacheEvictionPolicy  = "lru"                  // Политика вытеснения, если она не задана ни одним способом
+	defaultCachePromoteOnRead   = true                   // Перенос записей при чтении, если он не задан ни одним способом
+	defaultCacheLoaderTimeout   = 5 * time.Second        // Таймаут загрузки записи, если он не задан ни одним способом
+	defaultCacheStorageEngine   = "heap"                 // Движок хранения записей, если он не задан ни одним способом
 )
 
+// cacheEvictionPolicies перечисляет допустимые значения параметра политики вытеснения
+var cacheEvictionPolicies = []string{"lru", "lfu", "arc"}
+
+// cacheStorageEngines перечисляет допустимые значения параметра движка хранения записей
+var cacheStorageEngines = []string{"heap", "arena"}
+
 // CacheConfig описывает методы конфига кэша
 type CacheConfig interface {
-	Size() int                 // Размер кэша
-	DefaultTTL() time.Duration // TTL по умолчанию
+	Size() int                      // Размер кэша
+	DefaultTTL() time.Duration      // TTL по умолчанию
+	Shards() int                    // Количество шардов
+	JanitorInterval() time.Duration // Период фоновой очистки expired элементов
+	JanitorBudget() int             // Максимальное количество удалений за один проход фоновой очистки (на шард)
+	MaxBytes() int64                // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	EvictionPolicy() string         // Политика вытеснения ("lru", "lfu" или "arc")
+	Admission() bool                // Включен ли фильтр допуска W-TinyLFU
+	PromoteOnRead() bool            // Переносит ли чтение запись в начало списка (false - вытеснение в порядке записи)
+	LoaderTimeout() time.Duration   // Таймаут загрузки одного ключа при промахе (GetOrLoad)
+	StorageEngine() string          // Движок хранения записей ("heap" - pkg/lru, "arena" - сериализованные значения в слабах)
+
+	Namespaces() map[string]CacheConfig // Конфиги пространств имен, объявленных в конфиге (по имени)
 }
 
 // cacheConfig задает поля конфига кэша
 type cacheConfig struct {
 	size       int           // Размер кэша
 	defaultTTL time.Duration // TTL по умолчанию
+	shards     int           // Количество шардов
+
+	janitorInterval time.Duration // Период фоновой очистки expired элементов
+	janitorBudget   int           // Максимальное количество удалений за один проход фоновой очистки (на шард)
+	maxBytes        int64         // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	evictionPolicy  string        // Политика вытеснения ("lru", "lfu" или "arc")
+	admission       bool          // Включен ли фильтр допуска W-TinyLFU
+	promoteOnRead   bool          // Переносит ли чтение запись в начало списка
+	loaderTimeout   time.Duration // Таймаут загрузки одного ключа при промахе
+	storageEngine   string        // Движок хранения записей ("heap" или "arena")
+
+	namespaces map[string]CacheConfig // Конфиги пространств имен, объявленных в конфиге
 }
 
 // cacheConfigJSON задает поля конфига кэша, описанные в JSON (ограниченный набор типов)
 type cacheConfigJSON struct {
 	Size       int    `json:"cache_size"`         // Размер кэша
 	DefaultTTL string ` json:"default_cache_ttl"` // TTL по умолчанию (строка)
+	Shards     int    `json:"cache_shards"`       // Количество шардов
+
+	JanitorInterval string `json:"cache_janitor_interval"` // Период фоновой очистки expired элементов (строка)
+	JanitorBudget   int    `json:"cache_janitor_budget"`   // Максимальное количество удалений за один проход фоновой очистки
+	MaxBytes        int64  `json:"cache_max_bytes"`        // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	EvictionPolicy  string `json:"eviction_policy"`        // Политика вытеснения ("lru", "lfu" или "arc")
+	Admission       bool   `json:"cache_admission"`        // Включен ли фильтр допуска W-TinyLFU
+	PromoteOnRead   *bool  `json:"cache_promote_on_read"`  // Переносит ли чтение запись в начало списка (nil - не задано)
+	LoaderTimeout   string `json:"cache_loader_timeout"`   // Таймаут загрузки одного ключа при промахе (строка)
+	StorageEngine   string `json:"storage_engine"`         // Движок хранения записей ("heap" или "arena")
+
+	Namespaces map[string]cacheNamespaceJSON `json:"cache_namespaces"` // Пространства имен со своими параметрами кэша
 }
 
 // CacheDefaultValues загружает значения по умолчанию для кэша из JSON-файла
@@ -63,10 +133,28 @@ func NewCacheConfig() CacheConfig {
 	// flag value
 	sizeFlag := flags.cacheSize
 	defaultTTLFlag := flags.cacheDefaultTTL
+	shardsFlag := flags.cacheShards
+	janitorIntervalFlag := flags.cacheJanitorInterval
+	janitorBudgetFlag := flags.cacheJanitorBudget
+	maxBytesFlag := flags.cacheMaxBytes
+	evictionPolicyFlag := flags.cacheEvictionPolicy
+	admissionFlag := flags.cacheAdmission
+	promoteOnReadFlag := flags.cachePromoteOnRead
+	loaderTimeoutFlag := flags.cacheLoaderTimeout
+	storageEngineFlag := flags.cacheStorageEngine
 
 	// env value
 	sizeEnv := os.Getenv(cacheSizeEnvName)
 	defaultTTLEnv := os.Getenv(cacheDefaultTTLEnvName)
+	shardsEnv := os.Getenv(cacheShardsEnvName)
+	janitorIntervalEnv := os.Getenv(cacheJanitorIntervalEnvName)
+	janitorBudgetEnv := os.Getenv(cacheJanitorBudgetEnvName)
+	maxBytesEnv := os.Getenv(cacheMaxBytesEnvName)
+	evictionPolicyEnv := os.Getenv(cacheEvictionPolicyEnvName)
+	admissionEnv := os.Getenv(cacheAdmissionEnvName)
+	promoteOnReadEnv := os.Getenv(cachePromoteOnReadEnvName)
+	loaderTimeoutEnv := os.Getenv(cacheLoaderTimeoutEnvName)
+	storageEngineEnv := os.Getenv(cacheStorageEngineEnvName)
 
 	// default values
 	defaultValues := CacheDefaultValues()
@@ -112,10 +200,207 @@ func NewCacheConfig() CacheConfig {
 		log.Fatal().Msg("некорректный формат параметра TTL по умолчанию в кэше, default TTL должен быть > 0")
 	}
 
-	return &cacheConfig{
-		size:       size,
-		defaultTTL: defaultTTL,
+	// Трехступенчатый выбор количества шардов
+	var shards int
+	switch {
+	case shardsFlag != 0:
+		shards = shardsFlag
+	case len(shardsEnv) > 0:
+		shards, err = strconv.Atoi(shardsEnv)
+		if err != nil {
+			log.Fatal().Msg("некорректный формат количества шардов кэша (считан из переменной среды)")
+		}
+	case defaultValues.Shards != 0:
+		shards = defaultValues.Shards
+	default:
+		shards = defaultCacheShards
+	}
+
+	if shards <= 0 {
+		log.Fatal().Msg("некорректный формат количества шардов кэша, shards должен быть > 0")
+	}
+	if shards > size {
+		log.Fatal().Msg("некорректный формат количества шардов кэша, shards не может превышать размер кэша")
+	}
+
+	// Трехступенчатый выбор периода фоновой очистки
+	var janitorInterval time.Duration
+	switch {
+	case len(janitorIntervalFlag) > 0:
+		janitorInterval, err = time.ParseDuration(janitorIntervalFlag)
+	case len(janitorIntervalEnv) > 0:
+		janitorInterval, err = time.ParseDuration(janitorIntervalEnv)
+	case len(defaultValues.JanitorInterval) > 0:
+		janitorInterval, err = time.ParseDuration(defaultValues.JanitorInterval)
+	default:
+		janitorInterval = defaultCacheJanitorInterval
+	}
+	if err != nil {
+		log.Fatal().Msg("некорректный формат параметра период фоновой очистки кэша, должен являться временем")
+	}
+
+	if janitorInterval <= 0 {
+		log.Fatal().Msg("некорректный формат параметра период фоновой очистки кэша, janitor interval должен быть > 0")
+	}
+
+	// Трехступенчатый выбор лимита удалений за проход фоновой очистки
+	var janitorBudget int
+	switch {
+	case janitorBudgetFlag != 0:
+		janitorBudget = janitorBudgetFlag
+	case len(janitorBudgetEnv) > 0:
+		janitorBudget, err = strconv.Atoi(janitorBudgetEnv)
+		if err != nil {
+			log.Fatal().Msg("некорректный формат лимита удалений за проход фоновой очистки кэша (считан из переменной среды)")
+		}
+	case defaultValues.JanitorBudget != 0:
+		janitorBudget = defaultValues.JanitorBudget
+	default:
+		janitorBudget = defaultCacheJanitorBudget
+	}
+
+	if janitorBudget <= 0 {
+		log.Fatal().Msg("некорректный формат лимита удалений за проход фоновой очистки кэша, janitor budget должен быть > 0")
+	}
+
+	// Трехступенчатый выбор лимита размера кэша в байтах (не заданный лимит означает его отсутствие)
+	var maxBytes int64
+	switch {
+	case maxBytesFlag != 0:
+		maxBytes = maxBytesFlag
+	case len(maxBytesEnv) > 0:
+		maxBytes, err = strconv.ParseInt(maxBytesEnv, 10, 64)
+		if err != nil {
+			log.Fatal().Msg("некорректный формат лимита размера кэша в байтах (считан из переменной среды)")
+		}
+	default:
+		maxBytes = defaultValues.MaxBytes
+	}
+
+	if maxBytes < 0 {
+		log.Fatal().Msg("некорректный формат лимита размера кэша в байтах, max bytes должен быть >= 0")
+	}
+	if maxBytes > 0 && maxBytes < int64(shards) {
+		log.Fatal().Msg("некорректный формат лимита размера кэша в байтах, max bytes не может быть меньше количества шардов")
+	}
+
+	// Трехступенчатый выбор политики вытеснения
+	var evictionPolicy string
+	switch {
+	case len(evictionPolicyFlag) > 0:
+		evictionPolicy = evictionPolicyFlag
+	case len(evictionPolicyEnv) > 0:
+		evictionPolicy = evictionPolicyEnv
+	case len(defaultValues.EvictionPolicy) > 0:
+		evictionPolicy = defaultValues.EvictionPolicy
+	default:
+		evictionPolicy = defaultCacheEvictionPolicy
+	}
+
+	if !slices.Contains(cacheEvictionPolicies, evictionPolicy) {
+		log.Fatal().Msg("некорректный формат политики вытеснения кэша, допустимые значения: " + strings.Join(cacheEvictionPolicies, ", "))
+	}
+
+	// Трехступенчатый выбор включения фильтра допуска (флаг и переменная окружения задаются строкой)
+	var admission bool
+	switch {
+	case len(admissionFlag) > 0:
+		admission, err = strconv.ParseBool(admissionFlag)
+	case len(admissionEnv) > 0:
+		admission, err = strconv.ParseBool(admissionEnv)
+	default:
+		admission = defaultValues.Admission
+	}
+	if err != nil {
+		log.Fatal().Msg("некорректный формат параметра фильтр допуска кэша, должен являться true или false")
+	}
+
+	// Трехступенчатый выбор переноса записей при чтении (флаг и переменная окружения задаются строкой)
+	var promoteOnRead bool
+	switch {
+	case len(promoteOnReadFlag) > 0:
+		promoteOnRead, err = strconv.ParseBool(promoteOnReadFlag)
+	case len(promoteOnReadEnv) > 0:
+		promoteOnRead, err = strconv.ParseBool(promoteOnReadEnv)
+	case defaultValues.PromoteOnRead != nil:
+		promoteOnRead = *defaultValues.PromoteOnRead
+	default:
+		promoteOnRead = defaultCachePromoteOnRead
+	}
+	if err != nil {
+		log.Fatal().Msg("некорректный формат параметра перенос записей при чтении, должен являться true или false")
+	}
+
+	// Трехступенчатый выбор таймаута загрузки записи
+	var loaderTimeout time.Duration
+	switch {
+	case len(loaderTimeoutFlag) > 0:
+		loaderTimeout, err = time.ParseDuration(loaderTimeoutFlag)
+	case len(loaderTimeoutEnv) > 0:
+		loaderTimeout, err = time.ParseDuration(loaderTimeoutEnv)
+	case len(defaultValues.LoaderTimeout) > 0:
+		loaderTimeout, err = time.ParseDuration(defaultValues.LoaderTimeout)
+	default:
+		loaderTimeout = defaultCacheLoaderTimeout
+	}
+	if err != nil {
+		log.Fatal().Msg("некорректный формат параметра таймаут загрузки записи кэша, должен являться временем")
+	}
+
+	if loaderTimeout < 0 {
+		log.Fatal().Msg("некорректный формат параметра таймаут загрузки записи кэша, loader timeout должен быть >= 0")
+	}
+
+	// Трехступенчатый выбор движка хранения записей
+	var storageEngine string
+	switch {
+	case len(storageEngineFlag) > 0:
+		storageEngine = storageEngineFlag
+	case len(storageEngineEnv) > 0:
+		storageEngine = storageEngineEnv
+	case len(defaultValues.StorageEngine) > 0:
+		storageEngine = defaultValues.StorageEngine
+	default:
+		storageEngine = defaultCacheStorageEngine
+	}
+
+	if !slices.Contains(cacheStorageEngines, storageEngine) {
+		log.Fatal().Msg("некорректный формат движка хранения записей кэша, допустимые значения: " + strings.Join(cacheStorageEngines, ", "))
+	}
+	if storageEngine == "arena" && (evictionPolicy != "lru" || admission) {
+		log.Fatal().Msg("движок хранения записей arena поддерживает только политику вытеснения lru без фильтра допуска")
+	}
+
+	cfg := &cacheConfig{
+		size:            size,
+		defaultTTL:      defaultTTL,
+		shards:          shards,
+		janitorInterval: janitorInterval,
+		janitorBudget:   janitorBudget,
+		maxBytes:        maxBytes,
+		evictionPolicy:  evictionPolicy,
+		admission:       admission,
+		promoteOnRead:   promoteOnRead,
+		loaderTimeout:   loaderTimeout,
+		storageEngine:   storageEngine,
+	}
+
+	// Пространства имен задаются только в JSON-файле и наследуют не заданные параметры
+	cfg.namespaces = make(map[string]CacheConfig, len(defaultValues.Namespaces))
+	for name, nsValues := range defaultValues.Namespaces {
+		overrides, err := nsValues.overrides()
+		if err != nil {
+			log.Fatal().Err(err).Msg("некорректный формат параметров пространства имен кэша " + name)
+		}
+
+		nsCfg, err := NewNamespaceCacheConfig(cfg, overrides)
+		if err != nil {
+			log.Fatal().Err(err).Msg("некорректный формат параметров пространства имен кэша " + name)
+		}
+		cfg.namespaces[name] = nsCfg
 	}
+
+	return cfg
 }
 
 // Size возвращает параметр размер кэша из конфига
@@ -127,3 +412,53 @@ func (cfg *cacheConfig) Size() int {
 func (cfg *cacheConfig) DefaultTTL() time.Duration {
 	return cfg.defaultTTL
 }
+
+// Shards возвращает параметр количество шардов кэша из конфига
+func (cfg *cacheConfig) Shards() int {
+	return cfg.shards
+}
+
+// JanitorInterval возвращает параметр период фоновой очистки кэша из конфига
+func (cfg *cacheConfig) JanitorInterval() time.Duration {
+	return cfg.janitorInterval
+}
+
+// JanitorBudget возвращает параметр лимит удалений за проход фоновой очистки кэша из конфига
+func (cfg *cacheConfig) JanitorBudget() int {
+	return cfg.janitorBudget
+}
+
+// MaxBytes возвращает параметр лимит размера кэша в байтах из конфига
+func (cfg *cacheConfig) MaxBytes() int64 {
+	return cfg.maxBytes
+}
+
+// EvictionPolicy возвращает параметр политика вытеснения кэша из конфига
+func (cfg *cacheConfig) EvictionPolicy() string {
+	return cfg.evictionPolicy
+}
+
+// Admission возвращает параметр включения фильтра допуска кэша из конфига
+func (cfg *cacheConfig) Admission() bool {
+	return cfg.admission
+}
+
+// PromoteOnRead возвращает параметр переноса записей при чтении из конфига
+func (cfg *cacheConfig) PromoteOnRead() bool {
+	return cfg.promoteOnRead
+}
+
+// LoaderTimeout возвращает параметр таймаут загрузки записи кэша из конфига
+func (cfg *cacheConfig) LoaderTimeout() time.Duration {
+	return cfg.loaderTimeout
+}
+
+// Namespaces возвращает конфиги пространств имен из конфига
+func (cfg *cacheConfig) Namespaces() map[string]CacheConfig {
+	return cfg.namespaces
+}
+
+// StorageEngine возвращает параметр движок хранения записей кэша из конфига
+func (cfg *cacheConfig) StorageEngine() string {
+	return cfg.storageEngine
+}
@@ -20,10 +20,12 @@ import (
 )
 
 const (
-	appCfgDefaultValuesPath   = "configs/app.json"   // Путь к значения по умолчанию для настроек приложения (общих настроек)
-	cacheCfgDefaultValuesPath = "configs/cache.json" // Путь к значения по умолчанию для настроек непосредственно кэша
-	httpCfgDefaultValuesPath  = "configs/http.json"  // Путь к значения по умолчанию для настроек непосредственно сервера приложения
-	cfgEnvPath                = ".env"               // Путь к конфигурационному файлу среды
+	appCfgDefaultValuesPath      = "configs/app.json"      // Путь к значения по умолчанию для настроек приложения (общих настроек)
+	cacheCfgDefaultValuesPath    = "configs/cache.json"    // Путь к значения по умолчанию для настроек непосредственно кэша
+	httpCfgDefaultValuesPath     = "configs/http.json"     // Путь к значения по умолчанию для настроек непосредственно сервера приложения
+	snapshotCfgDefaultValuesPath = "configs/snapshot.json" // Путь к значения по умолчанию для настроек снимков кэша
+	opLogCfgDefaultValuesPath    = "configs/oplog.json"    // Путь к значения по умолчанию для настроек журнала изменений кэша
+	cfgEnvPath                   = ".env"                  // Путь к конфигурационному файлу среды
 )
 
 // LoadEnv оборачивает функцию Load из godotenv, которая читает конфигурационный файл среды, обработкой ошибок
@@ -46,10 +48,27 @@ var flags Flags
 type Flags struct {
 	cacheSize       int    // Размер кэша
 	cacheDefaultTTL string // TTL по умолчанию в кэше
+	cacheShards     int    // Количество шардов кэша
+
+	cacheJanitorInterval string // Период фоновой очистки кэша
+	cacheJanitorBudget   int    // Лимит удалений за проход фоновой очистки кэша
+	cacheMaxBytes        int64  // Лимит размера кэша в байтах
+	cacheEvictionPolicy  string // Политика вытеснения кэша
+	cacheAdmission       string // Включение фильтра допуска кэша ("true" или "false")
+	cachePromoteOnRead   string // Перенос записей при чтении ("true" или "false")
+	cacheLoaderTimeout   string // Таймаут загрузки записи кэша
+	cacheStorageEngine   string // Движок хранения записей кэша
 
 	httpHostPort string // Хост-порт HTTP-сервера
 
 	logLevel string // Уровень логирования
+
+	snapshotPath     string // Путь к файлу снимка кэша
+	snapshotInterval string // Период записи снимка кэша
+
+	opLogPath            string // Путь к файлу журнала изменений кэша
+	opLogFsync           string // Политика сброса журнала изменений на диск
+	opLogCompactMinBytes int64  // Минимальный размер сжимаемого журнала изменений
 }
 
 // GetLogFlag возвращает флаг с уровнем логирования
@@ -61,18 +80,49 @@ func GetLogFlag() string {
 func LoadFlags() {
 	size := flag.Int(cacheSizeFlagName, 0, "an int")
 	defaultTTL := flag.String(cacheDefaultTTLFlagName, "", "a string")
+	shards := flag.Int(cacheShardsFlagName, 0, "an int")
+	janitorInterval := flag.String(cacheJanitorIntervalFlagName, "", "a string")
+	janitorBudget := flag.Int(cacheJanitorBudgetFlagName, 0, "an int")
+	maxBytes := flag.Int64(cacheMaxBytesFlagName, 0, "an int64")
+	evictionPolicy := flag.String(cacheEvictionPolicyFlagName, "", "a string")
+	admission := flag.String(cacheAdmissionFlagName, "", "a string")
+	promoteOnRead := flag.String(cachePromoteOnReadFlagName, "", "a string")
+	loaderTimeout := flag.String(cacheLoaderTimeoutFlagName, "", "a string")
+	storageEngine := flag.String(cacheStorageEngineFlagName, "", "a string")
 
 	hostPort := flag.String(httpHostPortFlagName, "", "a string")
 
 	logLevel := flag.String(AppLogLevelFlagName, "", "a string")
 
+	snapshotPath := flag.String(snapshotPathFlagName, "", "a string")
+	snapshotInterval := flag.String(snapshotIntervalFlagName, "", "a string")
+
+	opLogPath := flag.String(opLogPathFlagName, "", "a string")
+	opLogFsync := flag.String(opLogFsyncFlagName, "", "a string")
+	opLogCompactMinBytes := flag.Int64(opLogCompactMinBytesFlagName, 0, "an int64")
+
 	flag.Parse()
 
 	flags = Flags{
 		cacheSize:       *size,
 		cacheDefaultTTL: *defaultTTL,
-		httpHostPort:    *hostPort,
-		logLevel:        *logLevel,
+		cacheShards:     *shards,
+
+		cacheJanitorInterval: *janitorInterval,
+		cacheJanitorBudget:   *janitorBudget,
+		cacheMaxBytes:        *maxBytes,
+		cacheEvictionPolicy:  *evictionPolicy,
+		cacheAdmission:       *admission,
+		cachePromoteOnRead:   *promoteOnRead,
+		cacheLoaderTimeout:   *loaderTimeout,
+		cacheStorageEngine:   *storageEngine,
+		httpHostPort:         *hostPort,
+		logLevel:             *logLevel,
+		snapshotPath:         *snapshotPath,
+		snapshotInterval:     *snapshotInterval,
+		opLogPath:            *opLogPath,
+		opLogFsync:           *opLogFsync,
+		opLogCompactMinBytes: *opLogCompactMinBytes,
 	}
 }
 
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CacheOverrides задает параметры кэша пространства имен, отличающиеся от параметров кэша по умолчанию.
+// Нулевое значение параметра означает, что он наследуется от кэша по умолчанию.
+type CacheOverrides struct {
+	Size           int           // Размер кэша
+	DefaultTTL     time.Duration // TTL по умолчанию
+	Shards         int           // Количество шардов
+	MaxBytes       int64         // Лимит суммарного размера записей в байтах
+	EvictionPolicy string        // Политика вытеснения ("lru", "lfu" или "arc")
+}
+
+// cacheNamespaceJSON задает параметры пространства имен, описанные в JSON (ограниченный набор типов)
+type cacheNamespaceJSON struct {
+	Size           int    `json:"cache_size"`        // Размер кэша
+	DefaultTTL     string `json:"default_cache_ttl"` // TTL по умолчанию (строка)
+	Shards         int    `json:"cache_shards"`      // Количество шардов
+	MaxBytes       int64  `json:"cache_max_bytes"`   // Лимит суммарного размера записей в байтах
+	EvictionPolicy string `json:"eviction_policy"`   // Политика вытеснения ("lru", "lfu" или "arc")
+}
+
+// overrides преобразует параметры пространства имен из JSON в CacheOverrides
+func (ns cacheNamespaceJSON) overrides() (CacheOverrides, error) {
+	overrides := CacheOverrides{
+		Size:           ns.Size,
+		Shards:         ns.Shards,
+		MaxBytes:       ns.MaxBytes,
+		EvictionPolicy: ns.EvictionPolicy,
+	}
+
+	if len(ns.DefaultTTL) > 0 {
+		defaultTTL, err := time.ParseDuration(ns.DefaultTTL)
+		if err != nil {
+			return CacheOverrides{}, fmt.Errorf("config: invalid default_cache_ttl: %w", err)
+		}
+		overrides.DefaultTTL = defaultTTL
+	}
+
+	return overrides, nil
+}
+
+// NewNamespaceCacheConfig собирает конфиг кэша пространства имен: параметры из overrides,
+// а не заданные в overrides - из конфига base. Параметры фоновой очистки, фильтра допуска,
+// переноса при чтении, загрузки и движок хранения записей всегда наследуются от base.
+//
+// В отличие от NewCacheConfig, некорректные параметры не завершают приложение, а возвращаются
+// ошибкой, так как пространства имен могут создаваться во время работы сервера.
+func NewNamespaceCacheConfig(base CacheConfig, overrides CacheOverrides) (CacheConfig, error) {
+	cfg := &cacheConfig{
+		size:            base.Size(),
+		defaultTTL:      base.DefaultTTL(),
+		shards:          base.Shards(),
+		janitorInterval: base.JanitorInterval(),
+		janitorBudget:   base.JanitorBudget(),
+		maxBytes:        base.MaxBytes(),
+		evictionPolicy:  base.EvictionPolicy(),
+		admission:       base.Admission(),
+		promoteOnRead:   base.PromoteOnRead(),
+		loaderTimeout:   base.LoaderTimeout(),
+		storageEngine:   base.StorageEngine(),
+	}
+
+	if overrides.Size != 0 {
+		cfg.size = overrides.Size
+	}
+	if overrides.DefaultTTL != 0 {
+		cfg.defaultTTL = overrides.DefaultTTL
+	}
+	if overrides.Shards != 0 {
+		cfg.shards = overrides.Shards
+	}
+	if overrides.MaxBytes != 0 {
+		cfg.maxBytes = overrides.MaxBytes
+	}
+	if len(overrides.EvictionPolicy) > 0 {
+		cfg.evictionPolicy = overrides.EvictionPolicy
+	}
+
+	// Шардов не может быть больше, чем записей, поэтому унаследованное количество шардов
+	// уменьшается вместе с размером кэша
+	if overrides.Shards == 0 && cfg.shards > cfg.size && cfg.size > 0 {
+		cfg.shards = cfg.size
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate проверяет параметры кэша пространства имен так же, как NewCacheConfig
+func (cfg *cacheConfig) validate() error {
+	switch {
+	case cfg.size <= 0:
+		return errors.New("config: cache_size must be > 0")
+	case cfg.defaultTTL <= 0:
+		return errors.New("config: default_cache_ttl must be > 0")
+	case cfg.shards <= 0:
+		return errors.New("config: cache_shards must be > 0")
+	case cfg.shards > cfg.size:
+		return errors.New("config: cache_shards must not exceed cache_size")
+	case cfg.maxBytes < 0:
+		return errors.New("config: cache_max_bytes must be >= 0")
+	case cfg.maxBytes > 0 && cfg.maxBytes < int64(cfg.shards):
+		return errors.New("config: cache_max_bytes must not be less than cache_shards")
+	case !slices.Contains(cacheEvictionPolicies, cfg.evictionPolicy):
+		return errors.New("config: eviction_policy must be one of " + strings.Join(cacheEvictionPolicies, ", "))
+	case cfg.storageEngine == "arena" && cfg.evictionPolicy != "lru":
+		return errors.New("config: storage_engine arena supports only eviction_policy lru")
+	}
+	return nil
+}
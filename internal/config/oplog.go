@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	opLogPathEnvName             = "OPLOG_PATH"              // Имя переменной окружения для параметра путь к файлу журнала изменений
+	opLogPathFlagName            = "oplog-path"              // Имя флага для параметра путь к файлу журнала изменений
+	opLogFsyncEnvName            = "OPLOG_FSYNC"             // Имя переменной окружения для параметра политика сброса журнала на диск
+	opLogFsyncFlagName           = "oplog-fsync"             // Имя флага для параметра политика сброса журнала на диск
+	opLogCompactMinBytesEnvName  = "OPLOG_COMPACT_MIN_BYTES" // Имя переменной окружения для параметра минимальный размер сжимаемого журнала
+	opLogCompactMinBytesFlagName = "oplog-compact-min-bytes" // Имя флага для параметра минимальный размер сжимаемого журнала
+
+	defaultOpLogFsync = "everysec" // Политика сброса журнала на диск по умолчанию
+)
+
+// OpLogConfig описывает методы конфига журнала изменений кэша
+type OpLogConfig interface {
+	Path() string           // Путь к файлу журнала, пустая строка - журнал отключен
+	Fsync() string          // Политика сброса журнала на диск ("always", "everysec" или "never")
+	CompactMinBytes() int64 // Минимальный размер журнала, с которого он сжимается
+}
+
+// opLogConfig задает поля конфига журнала изменений кэша
+type opLogConfig struct {
+	path            string // Путь к файлу журнала
+	fsync           string // Политика сброса журнала на диск
+	compactMinBytes int64  // Минимальный размер сжимаемого журнала
+}
+
+// opLogConfigJSON задает поля конфига журнала изменений кэша, описанные в JSON (ограниченный набор типов)
+type opLogConfigJSON struct {
+	Path            string `json:"oplog_path"`              // Путь к файлу журнала
+	Fsync           string `json:"oplog_fsync"`             // Политика сброса журнала на диск
+	CompactMinBytes int64  `json:"oplog_compact_min_bytes"` // Минимальный размер сжимаемого журнала
+}
+
+// OpLogDefaultValues загружает значения по умолчанию для журнала изменений кэша из JSON-файла
+func OpLogDefaultValues() opLogConfigJSON {
+	defaultValuesFile, err := LoadJSON(opLogCfgDefaultValuesPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("ошибка при чтении файла конфигурации журнала изменений со значениями по умолчанию")
+	}
+
+	var defaultValues opLogConfigJSON
+	err = json.Unmarshal(defaultValuesFile, &defaultValues)
+	if err != nil {
+		log.Fatal().Err(err).Msg("ошибка при обработке файла конфигурации журнала изменений со значениями по умолчанию")
+	}
+
+	return defaultValues
+}
+
+// NewOpLogConfig собирает актуальный конфиг журнала изменений кэша по трехступенчатому принципу
+//
+// - Если для параметра определен флаг запуска, используется он
+//
+// - Если флаг не определен, используется переменная окружения
+//
+// - Если не определены ни флаг, ни переменная окружения, используется значение по умолчанию
+func NewOpLogConfig() OpLogConfig {
+	var err error
+
+	// flag value
+	pathFlag := flags.opLogPath
+	fsyncFlag := flags.opLogFsync
+	compactMinBytesFlag := flags.opLogCompactMinBytes
+
+	// env value
+	pathEnv := os.Getenv(opLogPathEnvName)
+	fsyncEnv := os.Getenv(opLogFsyncEnvName)
+	compactMinBytesEnv := os.Getenv(opLogCompactMinBytesEnvName)
+
+	// default values
+	defaultValues := OpLogDefaultValues()
+
+	// Трехступенчатый выбор пути к файлу журнала (не заданный путь отключает журнал)
+	var path string
+	switch {
+	case len(pathFlag) > 0:
+		path = pathFlag
+	case len(pathEnv) > 0:
+		path = pathEnv
+	default:
+		path = defaultValues.Path
+	}
+
+	// Трехступенчатый выбор политики сброса журнала на диск
+	var fsync string
+	switch {
+	case len(fsyncFlag) > 0:
+		fsync = fsyncFlag
+	case len(fsyncEnv) > 0:
+		fsync = fsyncEnv
+	case len(defaultValues.Fsync) > 0:
+		fsync = defaultValues.Fsync
+	default:
+		fsync = defaultOpLogFsync
+	}
+
+	switch fsync {
+	case "always", "everysec", "never":
+	default:
+		log.Fatal().Msg("некорректный формат политики сброса журнала изменений на диск, допустимы always, everysec и never")
+	}
+
+	// Трехступенчатый выбор минимального размера сжимаемого журнала
+	var compactMinBytes int64
+	switch {
+	case compactMinBytesFlag != 0:
+		compactMinBytes = compactMinBytesFlag
+	case len(compactMinBytesEnv) > 0:
+		compactMinBytes, err = strconv.ParseInt(compactMinBytesEnv, 10, 64)
+		if err != nil {
+			log.Fatal().Msg("некорректный формат минимального размера сжимаемого журнала изменений (считан из переменной среды)")
+		}
+	default:
+		compactMinBytes = defaultValues.CompactMinBytes
+	}
+
+	if compactMinBytes < 0 {
+		log.Fatal().Msg("некорректный формат минимального размера сжимаемого журнала изменений, compact min bytes должен быть >= 0")
+	}
+
+	return &opLogConfig{
+		path:            path,
+		fsync:           fsync,
+		compactMinBytes: compactMinBytes,
+	}
+}
+
+// Path возвращает параметр путь к файлу журнала изменений из конфига
+func (cfg *opLogConfig) Path() string {
+	return cfg.path
+}
+
+// Fsync возвращает параметр политика сброса журнала изменений на диск из конфига
+func (cfg *opLogConfig) Fsync() string {
+	return cfg.fsync
+}
+
+// CompactMinBytes возвращает параметр минимальный размер сжимаемого журнала изменений из конфига
+func (cfg *opLogConfig) CompactMinBytes() int64 {
+	return cfg.compactMinBytes
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	snapshotPathEnvName      = "SNAPSHOT_PATH"     // Имя переменной окружения для параметра путь к файлу снимка кэша
+	snapshotPathFlagName     = "snapshot-path"     // Имя флага для параметра путь к файлу снимка кэша
+	snapshotIntervalEnvName  = "SNAPSHOT_INTERVAL" // Имя переменной окружения для параметра период записи снимка кэша
+	snapshotIntervalFlagName = "snapshot-interval" // Имя флага для параметра период записи снимка кэша
+)
+
+// SnapshotConfig описывает методы конфига снимков кэша
+type SnapshotConfig interface {
+	Path() string            // Путь к файлу снимка, пустая строка - снимки отключены
+	Interval() time.Duration // Период записи снимка, 0 - снимок записывается только при остановке приложения
+}
+
+// snapshotConfig задает поля конфига снимков кэша
+type snapshotConfig struct {
+	path     string        // Путь к файлу снимка
+	interval time.Duration // Период записи снимка
+}
+
+// snapshotConfigJSON задает поля конфига снимков кэша, описанные в JSON (ограниченный набор типов)
+type snapshotConfigJSON struct {
+	Path     string `json:"snapshot_path"`     // Путь к файлу снимка
+	Interval string `json:"snapshot_interval"` // Период записи снимка (строка)
+}
+
+// SnapshotDefaultValues загружает значения по умолчанию для снимков кэша из JSON-файла
+func SnapshotDefaultValues() snapshotConfigJSON {
+	defaultValuesFile, err := LoadJSON(snapshotCfgDefaultValuesPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("ошибка при чтении файла конфигурации снимков кэша со значениями по умолчанию")
+	}
+
+	var defaultValues snapshotConfigJSON
+	err = json.Unmarshal(defaultValuesFile, &defaultValues)
+	if err != nil {
+		log.Fatal().Err(err).Msg("ошибка при обработке файла конфигурации снимков кэша со значениями по умолчанию")
+	}
+
+	return defaultValues
+}
+
+// NewSnapshotConfig собирает актуальный конфиг снимков кэша по трехступенчатому принципу
+//
+// - Если для параметра определен флаг запуска, используется он
+//
+// - Если флаг не определен, используется переменная окружения
+//
+// - Если не определены ни флаг, ни переменная окружения, используется значение по умолчанию
+func NewSnapshotConfig() SnapshotConfig {
+	var err error
+
+	// flag value
+	pathFlag := flags.snapshotPath
+	intervalFlag := flags.snapshotInterval
+
+	// env value
+	pathEnv := os.Getenv(snapshotPathEnvName)
+	intervalEnv := os.Getenv(snapshotIntervalEnvName)
+
+	// default values
+	defaultValues := SnapshotDefaultValues()
+
+	// Трехступенчатый выбор пути к файлу снимка (не заданный путь отключает снимки)
+	var path string
+	switch {
+	case len(pathFlag) > 0:
+		path = pathFlag
+	case len(pathEnv) > 0:
+		path = pathEnv
+	default:
+		path = defaultValues.Path
+	}
+
+	// Трехступенчатый выбор периода записи снимка (не заданный период означает запись только при остановке)
+	var interval time.Duration
+	switch {
+	case len(intervalFlag) > 0:
+		interval, err = time.ParseDuration(intervalFlag)
+	case len(intervalEnv) > 0:
+		interval, err = time.ParseDuration(intervalEnv)
+	case len(defaultValues.Interval) > 0:
+		interval, err = time.ParseDuration(defaultValues.Interval)
+	}
+	if err != nil {
+		log.Fatal().Msg("некорректный формат параметра период записи снимка кэша, должен являться временем")
+	}
+
+	if interval < 0 {
+		log.Fatal().Msg("некорректный формат параметра период записи снимка кэша, snapshot interval должен быть >= 0")
+	}
+
+	return &snapshotConfig{
+		path:     path,
+		interval: interval,
+	}
+}
+
+// Path возвращает параметр путь к файлу снимка кэша из конфига
+func (cfg *snapshotConfig) Path() string {
+	return cfg.path
+}
+
+// Interval возвращает параметр период записи снимка кэша из конфига
+func (cfg *snapshotConfig) Interval() time.Duration {
+	return cfg.interval
+}
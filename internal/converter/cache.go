@@ -13,8 +13,52 @@ import (
 // ToEntryPutDataFromDesc конвертирует поля для создания новой записи в кэше из API-слоя в Entities
 func ToEntryPutDataFromDesc(info desc.EntryPutData) model.EntryPutData {
 	return model.EntryPutData{
-		Key:   info.Key,
-		Value: info.Value,
-		TTL:   time.Second * time.Duration(info.TTLSeconds),
+		Key:     info.Key,
+		Value:   info.Value,
+		TTL:     time.Second * time.Duration(info.TTLSeconds),
+		Sliding: info.Sliding,
+
+		StaleTTL:     time.Second * time.Duration(info.StaleTTLSeconds),
+		RefreshAhead: time.Second * time.Duration(info.RefreshAheadSeconds),
+		Tags:         info.Tags,
+	}
+}
+
+// ToEntryGetDataFromModel конвертирует запись, полученную из кэша, из Entities в API-слой
+func ToEntryGetDataFromModel(entry model.EntryGetData) desc.EntryGetData {
+	return desc.EntryGetData{
+		Key:       entry.Key,
+		Value:     entry.Value,
+		ExpiresAt: entry.ExpiresAt.Unix(),
+		Stale:     entry.Stale,
+		Version:   entry.Version,
+	}
+}
+
+// ToEntryResultDataFromModel конвертирует результат пакетной операции над записью из Entities в API-слой.
+// status - HTTP-код, соответствующий результату.
+func ToEntryResultDataFromModel(result model.EntryResult, status int) desc.EntryResultData {
+	data := desc.EntryResultData{
+		Key:     result.Entry.Key,
+		Status:  status,
+		Value:   result.Entry.Value,
+		Stale:   result.Entry.Stale,
+		Version: result.Entry.Version,
+	}
+	if !result.Entry.ExpiresAt.IsZero() {
+		data.ExpiresAt = result.Entry.ExpiresAt.Unix()
+	}
+	return data
+}
+
+// ToCacheStatsDataFromModel конвертирует заполнение кэша из Entities в API-слой
+func ToCacheStatsDataFromModel(stats model.CacheStats) desc.CacheStatsData {
+	return desc.CacheStatsData{
+		Len:      stats.Len,
+		Size:     stats.Size,
+		Bytes:    stats.Bytes,
+		MaxBytes: stats.MaxBytes,
+		Admitted: stats.Admitted,
+		Rejected: stats.Rejected,
 	}
 }
@@ -2,6 +2,7 @@ package converter
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vitbogit/golang-cache-lru/internal/model"
@@ -27,6 +28,32 @@ func TestToEntryPutDataFromDesc(t *testing.T) {
 		},
 		"they should be equal")
 
+	assert.Equal(t,
+		ToEntryPutDataFromDesc(desc.EntryPutData{
+			Key:        "session",
+			Value:      "some value",
+			TTLSeconds: 60,
+			Sliding:    true,
+		}),
+		model.EntryPutData{
+			Key:     "session",
+			Value:   "some value",
+			TTL:     time.Minute,
+			Sliding: true,
+		},
+		"they should be equal")
+
+	assert.Equal(t,
+		ToEntryPutDataFromDesc(desc.EntryPutData{
+			Key:  "product:1:page",
+			Tags: []string{"product:1"},
+		}),
+		model.EntryPutData{
+			Key:  "product:1:page",
+			Tags: []string{"product:1"},
+		},
+		"they should be equal")
+
 	assert.NotEqual(t,
 		ToEntryPutDataFromDesc(desc.EntryPutData{
 			Key:        "some key",
@@ -40,3 +67,98 @@ func TestToEntryPutDataFromDesc(t *testing.T) {
 		},
 		"they should be equal")
 }
+
+func TestToCacheStatsDataFromModel(t *testing.T) {
+	assert.Equal(t,
+		ToCacheStatsDataFromModel(model.CacheStats{
+			Len:      1,
+			Size:     10,
+			Bytes:    100,
+			MaxBytes: 1000,
+			Admitted: 5,
+			Rejected: 7,
+		}),
+		desc.CacheStatsData{
+			Len:      1,
+			Size:     10,
+			Bytes:    100,
+			MaxBytes: 1000,
+			Admitted: 5,
+			Rejected: 7,
+		},
+		"they should be equal")
+}
+
+func TestToEntryGetDataFromModel(t *testing.T) {
+	expiresAt := time.Unix(1700000000, 0)
+
+	assert.Equal(t,
+		ToEntryGetDataFromModel(model.EntryGetData{
+			Key:       "some key",
+			Value:     "some value",
+			ExpiresAt: expiresAt,
+			Stale:     true,
+		}),
+		desc.EntryGetData{
+			Key:       "some key",
+			Value:     "some value",
+			ExpiresAt: 1700000000,
+			Stale:     true,
+		},
+		"they should be equal")
+}
+
+func TestToEntryResultDataFromModel(t *testing.T) {
+	assert.Equal(t,
+		ToEntryResultDataFromModel(model.EntryResult{
+			Entry: model.EntryGetData{
+				Key:       "some key",
+				Value:     "some value",
+				ExpiresAt: time.Unix(1700000000, 0),
+				Version:   3,
+			},
+		}, 200),
+		desc.EntryResultData{
+			Key:       "some key",
+			Status:    200,
+			Value:     "some value",
+			ExpiresAt: 1700000000,
+			Version:   3,
+		},
+		"they should be equal")
+
+	// Запись без даты истечения
+	assert.Equal(t,
+		ToEntryResultDataFromModel(model.EntryResult{Entry: model.EntryGetData{Key: "some key"}}, 404),
+		desc.EntryResultData{Key: "some key", Status: 404},
+		"they should be equal")
+}
+
+func TestToNamespaceConfigFromDesc(t *testing.T) {
+	assert.Equal(t,
+		model.NamespaceConfig{},
+		ToNamespaceConfigFromDesc(desc.NamespaceData{Name: "team-a"}),
+		"they should be equal")
+
+	assert.Equal(t,
+		model.NamespaceConfig{Size: 100, DefaultTTL: 30 * time.Second, Shards: 4, MaxBytes: 1024, EvictionPolicy: "arc"},
+		ToNamespaceConfigFromDesc(desc.NamespaceData{
+			Name:              "team-a",
+			Size:              100,
+			DefaultTTLSeconds: 30,
+			Shards:            4,
+			MaxBytes:          1024,
+			EvictionPolicy:    "arc",
+		}),
+		"they should be equal")
+}
+
+func TestToNamespaceDataFromModel(t *testing.T) {
+	assert.Equal(t,
+		desc.NamespaceData{Name: "team-a", Size: 100, DefaultTTLSeconds: 90, Shards: 2, EvictionPolicy: "lru"},
+		ToNamespaceDataFromModel(model.NamespaceInfo{
+			Name:   "team-a",
+			Config: model.NamespaceConfig{Size: 100, DefaultTTL: 90 * time.Second, Shards: 2, EvictionPolicy: "lru"},
+		}),
+		"they should be equal")
+}
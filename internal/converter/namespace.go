@@ -0,0 +1,31 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	desc "github.com/vitbogit/golang-cache-lru/pkg/cache_v1"
+)
+
+// ToNamespaceConfigFromDesc конвертирует параметры создаваемого пространства имен из API-слоя в Entities
+func ToNamespaceConfigFromDesc(info desc.NamespaceData) model.NamespaceConfig {
+	return model.NamespaceConfig{
+		Size:           info.Size,
+		DefaultTTL:     time.Second * time.Duration(info.DefaultTTLSeconds),
+		Shards:         info.Shards,
+		MaxBytes:       info.MaxBytes,
+		EvictionPolicy: info.EvictionPolicy,
+	}
+}
+
+// ToNamespaceDataFromModel конвертирует пространство имен из Entities в API-слой
+func ToNamespaceDataFromModel(info model.NamespaceInfo) desc.NamespaceData {
+	return desc.NamespaceData{
+		Name:              info.Name,
+		Size:              info.Config.Size,
+		DefaultTTLSeconds: int(info.Config.DefaultTTL / time.Second),
+		Shards:            info.Config.Shards,
+		MaxBytes:          info.Config.MaxBytes,
+		EvictionPolicy:    info.Config.EvictionPolicy,
+	}
+}
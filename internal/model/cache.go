@@ -3,11 +3,136 @@
 // некоторые промежуточные структуры и конвертеры для них могут быть опущены.
 package model
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidEntry возвращается при записи значения с некорректными полями (см. EntryPutData.Validate)
+var ErrInvalidEntry = errors.New("model: invalid entry data")
 
 // EntryPutData представляет поля для записи значения в кэш на уровне Entities
 type EntryPutData struct {
-	Key   string
-	Value interface{}
-	TTL   time.Duration
+	Key          string
+	Value        interface{}
+	TTL          time.Duration
+	Sliding      bool          // Продлевать TTL при каждом чтении
+	StaleTTL     time.Duration // Время после истечения, в течение которого запись отдается как устаревшая
+	RefreshAhead time.Duration // Время до истечения, начиная с которого запись обновляется в фоне
+	Tags         []string      // Теги для группового удаления записей (EvictByTag)
+}
+
+// Validate проверяет поля для записи значения в кэш: ключ не пустой, TTL, StaleTTL и RefreshAhead
+// не отрицательные, среди тегов нет пустых. Для некорректных полей возвращает ErrInvalidEntry.
+func (d EntryPutData) Validate() error {
+	if len(d.Key) == 0 || d.TTL < 0 || d.StaleTTL < 0 || d.RefreshAhead < 0 {
+		return ErrInvalidEntry
+	}
+	for _, tag := range d.Tags {
+		if len(tag) == 0 {
+			return ErrInvalidEntry
+		}
+	}
+	return nil
+}
+
+// EntryGetData представляет запись, полученную из кэша, на уровне Entities
+type EntryGetData struct {
+	Key       string
+	Value     interface{}
+	ExpiresAt time.Time
+	Stale     bool   // Запись истекла и отдается как устаревшая
+	Version   uint64 // Версия записи, растет при каждой записи значения
+}
+
+// EntryResult представляет результат операции над одной записью в пакетных операциях на уровне Entities
+type EntryResult struct {
+	Entry EntryGetData
+	Err   error // Ошибка операции над записью (например, repository.ErrNotFound), nil - успех
+}
+
+// Типы операций транзакции
+const (
+	TxnOpPut   = "put"   // Запись значения
+	TxnOpEvict = "evict" // Удаление записи
+	TxnOpIncr  = "incr"  // Увеличение числового значения
+)
+
+// Типы условий транзакции
+const (
+	TxnCondExists  = "exists"  // Запись есть и не истекла
+	TxnCondAbsent  = "absent"  // Записи нет или она истекла
+	TxnCondVersion = "version" // Версия записи равна ожидаемой
+)
+
+// TxnOp представляет операцию транзакции на уровне Entities
+type TxnOp struct {
+	Op    string       // Тип операции: TxnOpPut, TxnOpEvict или TxnOpIncr
+	Data  EntryPutData // Ключ операции; значение, TTL и параметры записи для put; TTL создаваемой записи для incr
+	Delta interface{}  // Приращение для incr (int64 или float64)
+}
+
+// TxnCondition представляет условие транзакции на уровне Entities
+type TxnCondition struct {
+	Cond    string // Тип условия: TxnCondExists, TxnCondAbsent или TxnCondVersion
+	Key     string
+	Version uint64 // Ожидаемая версия для TxnCondVersion
+}
+
+// TxnData представляет транзакцию на уровне Entities: условия и упорядоченный список операций
+type TxnData struct {
+	Conditions []TxnCondition
+	Ops        []TxnOp
+}
+
+// Порядки обхода записей в Scan
+const (
+	ScanOrderLRU = "lru" // От давно не использовавшихся записей к недавно использованным
+	ScanOrderMRU = "mru" // От недавно использованных записей к давно не использовавшимся
+)
+
+// ScanPage представляет страницу постраничного обхода кэша на уровне Entities
+type ScanPage struct {
+	Keys   []string
+	Values []interface{} // Значения на позициях соответствующих ключей
+	Cursor string        // Курсор для получения следующей страницы, пустая строка - обход завершен
+}
+
+// CacheStats представляет текущее заполнение кэша на уровне Entities
+type CacheStats struct {
+	Len      int    // Количество записей
+	Size     int    // Максимальное количество записей
+	Bytes    int64  // Оценка суммарного размера записей в байтах (0, если размер не отслеживается)
+	MaxBytes int64  // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	Admitted uint64 // Количество новых записей, допущенных фильтром допуска в заполненный кэш
+	Rejected uint64 // Количество новых записей, отклоненных фильтром допуска
+}
+
+// NamespaceConfig представляет параметры кэша пространства имен на уровне Entities.
+// При создании пространства имен нулевые значения означают наследование параметров кэша по умолчанию.
+type NamespaceConfig struct {
+	Size           int           // Максимальное количество записей
+	DefaultTTL     time.Duration // TTL по умолчанию
+	Shards         int           // Количество шардов
+	MaxBytes       int64         // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	EvictionPolicy string        // Политика вытеснения ("lru", "lfu" или "arc")
+}
+
+// NamespaceInfo представляет пространство имен (именованный кэш) на уровне Entities
+type NamespaceInfo struct {
+	Name   string
+	Config NamespaceConfig // Итоговые параметры кэша пространства имен
+}
+
+const (
+	FsyncAlways   = "always"   // Журнал сбрасывается на диск после каждого изменения
+	FsyncEverySec = "everysec" // Журнал сбрасывается на диск раз в секунду
+	FsyncNever    = "never"    // Сброс журнала на диск остается на усмотрение ОС
+)
+
+// OpLogConfig представляет параметры журнала изменений кэша на уровне Entities
+type OpLogConfig struct {
+	Path            string // Путь к файлу журнала
+	Fsync           string // Политика сброса на диск (FsyncAlways, FsyncEverySec или FsyncNever)
+	CompactMinBytes int64  // Минимальный размер журнала, с которого он сжимается
 }
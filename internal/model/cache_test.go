@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryPutData_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		data EntryPutData
+		err  error
+	}{
+		{"valid", EntryPutData{Key: "a", Value: 1, TTL: time.Minute, Tags: []string{"t"}}, nil},
+		{"default ttl", EntryPutData{Key: "a"}, nil},
+		{"empty key", EntryPutData{Value: 1}, ErrInvalidEntry},
+		{"negative ttl", EntryPutData{Key: "a", TTL: -time.Second}, ErrInvalidEntry},
+		{"negative stale ttl", EntryPutData{Key: "a", StaleTTL: -time.Second}, ErrInvalidEntry},
+		{"negative refresh ahead", EntryPutData{Key: "a", RefreshAhead: -time.Second}, ErrInvalidEntry},
+		{"empty tag", EntryPutData{Key: "a", Tags: []string{"t", ""}}, ErrInvalidEntry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.data.Validate(), tt.err)
+		})
+	}
+}
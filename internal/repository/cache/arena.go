@@ -0,0 +1,567 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+var _ def.ILRUCache = (*Arena)(nil)
+
+// ArenaOptions задает параметры Arena
+type ArenaOptions struct {
+	Shards          int           // Количество шардов
+	MaxBytes        int64         // Лимит суммарного размера чанков, занятых записями, в байтах, 0 - без ограничения
+	JanitorInterval time.Duration // Период фоновой очистки истекших записей
+	JanitorBudget   int           // Максимальное количество удалений из шарда за один проход очистки
+	PromoteOnRead   bool          // Учитывать ли чтение в порядке использования
+	LoaderTimeout   time.Duration // Таймаут загрузки значения, 0 - без ограничения
+}
+
+// Arena имплементирует потокобезопасный LRU-кэш с поддержкой TTL, хранящий записи вне кучи объектов Go.
+//
+// Ключ, значение (в JSON) и теги записи хранятся в чанках больших заранее выделенных слабов,
+// индекс ключей - map[uint64]uint32 (хэш ключа - первый слот цепочки слотов с этим хэшем), а порядок
+// использования - кольцо номеров слотов. Так же, номерами слотов, устроены дерево ключей для операций
+// по префиксу, индекс тегов и очередь сроков для фоновой очистки (см. arena_index.go). Ни индексы,
+// ни слоты не содержат указателей, поэтому сборщик мусора не обходит записи кэша, сколько бы их ни было. Платой за это служат сериализация значения при каждой записи
+// и десериализация при каждом чтении, а также приближенный LRU: чтение только отмечает запись,
+// и отмеченная запись получает второй шанс при вытеснении (см. arenaShard).
+//
+// Значения восстанавливаются из JSON так же, как из снимка (см. decodeSnapshotValue).
+type Arena struct {
+	shards     []*arenaShard
+	seed       maphash.Seed
+	size       int
+	maxBytes   int64
+	defaultTTL time.Duration
+
+	loads         arenaLoads
+	loader        atomic.Pointer[def.Loader]
+	loaderTimeout time.Duration
+
+	oplog atomic.Pointer[opLog] // журнал изменений, nil - не включен (см. OpenLog)
+
+	closed    atomic.Bool
+	closeOnce sync.Once
+	done      chan struct{} // закрывается в Close для остановки фоновой очистки
+	stopped   chan struct{} // закрывается фоновой очисткой при завершении
+}
+
+// NewArena создает новый кэш размера size с TTL по умолчанию defaultTTL и параметрами opts
+func NewArena(size int, defaultTTL time.Duration, opts ArenaOptions) (*Arena, error) {
+	switch {
+	case size <= 0:
+		return nil, fmt.Errorf("%w: size must be > 0", lru.ErrInvalidOption)
+	case defaultTTL <= 0:
+		return nil, fmt.Errorf("%w: default TTL for cache must be > 0", lru.ErrInvalidOption)
+	case opts.Shards <= 0:
+		return nil, fmt.Errorf("%w: shards must be > 0", lru.ErrInvalidOption)
+	case opts.Shards > size:
+		return nil, fmt.Errorf("%w: shards can not exceed size", lru.ErrInvalidOption)
+	case opts.JanitorInterval <= 0:
+		return nil, fmt.Errorf("%w: janitor interval must be > 0", lru.ErrInvalidOption)
+	case opts.JanitorBudget <= 0:
+		return nil, fmt.Errorf("%w: janitor budget must be > 0", lru.ErrInvalidOption)
+	case opts.MaxBytes < 0:
+		return nil, fmt.Errorf("%w: max bytes can not be negative", lru.ErrInvalidOption)
+	case opts.LoaderTimeout < 0:
+		return nil, fmt.Errorf("%w: loader timeout can not be negative", lru.ErrInvalidOption)
+	}
+
+	a := &Arena{
+		shards:     make([]*arenaShard, opts.Shards),
+		seed:       maphash.MakeSeed(),
+		size:       size,
+		maxBytes:   opts.MaxBytes,
+		defaultTTL: defaultTTL,
+
+		loads:         arenaLoads{calls: make(map[string]*arenaLoad)},
+		loaderTimeout: opts.LoaderTimeout,
+
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	// Размер и лимит памяти делятся между шардами так же, как в pkg/lru: в сумме лимиты шардов
+	// равны лимиту кэша
+	for i := range a.shards {
+		shardSize := size / opts.Shards
+		if i < size%opts.Shards {
+			shardSize++
+		}
+		shardBytes := opts.MaxBytes / int64(opts.Shards)
+		if int64(i) < opts.MaxBytes%int64(opts.Shards) {
+			shardBytes++
+		}
+		a.shards[i] = newArenaShard(shardSize, shardBytes, opts.PromoteOnRead, a.seed)
+	}
+
+	go a.runJanitor(opts.JanitorInterval, opts.JanitorBudget)
+
+	return a, nil
+}
+
+// runJanitor раз в interval удаляет истекшие записи, не более budget из каждого шарда
+func (a *Arena) runJanitor(interval time.Duration, budget int) {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-ticker.C:
+			for _, s := range a.shards {
+				s.mu.Lock()
+				s.deleteDead(budget, time.Now().UnixNano())
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Close останавливает фоновую очистку кэша, после чего операции над ним возвращают ErrClosed.
+// Журнал изменений, если он включен, сбрасывается на диск и закрывается.
+func (a *Arena) Close(ctx context.Context) error {
+	a.closed.Store(true)
+	a.closeOnce.Do(func() {
+		close(a.done)
+	})
+
+	var err error
+	select {
+	case <-a.stopped:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return errors.Join(err, a.oplog.Load().closeIfOpen())
+}
+
+// Stats получение текущего заполнения кэша. Bytes - суммарный размер чанков, занятых записями.
+func (a *Arena) Stats(ctx context.Context) (model.CacheStats, error) {
+	if a.closed.Load() {
+		return model.CacheStats{}, def.ErrClosed
+	}
+
+	a.rlockAll()
+	defer a.runlockAll()
+
+	stats := model.CacheStats{
+		Size:     a.size,
+		MaxBytes: a.maxBytes,
+	}
+	for _, s := range a.shards {
+		stats.Len += s.len()
+		stats.Bytes += s.slabs.used
+	}
+	return stats, nil
+}
+
+// Put запись данных в кэш. Возвращает версию записанной записи.
+func (a *Arena) Put(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	defer a.awaitLog()
+
+	entry, err := a.put(data, nil)
+	return entry.Version, err
+}
+
+// CompareAndSwap запись данных в кэш, только если текущая версия записи равна expectedVersion.
+// Возвращает версию записанной записи или ErrVersionMismatch, ErrNotFound, ErrExpired.
+func (a *Arena) CompareAndSwap(ctx context.Context, data model.EntryPutData, expectedVersion uint64) (version uint64, err error) {
+	defer a.awaitLog()
+
+	entry, err := a.put(data, func(slot *arenaSlot, err error, now int64) error {
+		if err != nil {
+			return err
+		}
+		if slot.version != expectedVersion {
+			return def.ErrVersionMismatch
+		}
+		return nil
+	})
+	return entry.Version, err
+}
+
+// PutIfAbsent запись данных в кэш, только если записи с таким ключом нет (или она истекла).
+// Возвращает версию записанной записи или ErrExists.
+func (a *Arena) PutIfAbsent(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	defer a.awaitLog()
+
+	entry, err := a.put(data, func(slot *arenaSlot, err error, now int64) error {
+		if err == nil && !slot.expired(now) {
+			return def.ErrExists
+		}
+		return nil
+	})
+	return entry.Version, err
+}
+
+// Replace запись данных в кэш, только если запись с таким ключом уже есть.
+// Возвращает версию записанной записи или ErrNotFound, ErrExpired.
+func (a *Arena) Replace(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	defer a.awaitLog()
+
+	entry, err := a.put(data, func(slot *arenaSlot, err error, now int64) error {
+		if err == nil && slot.expired(now) {
+			return def.ErrExpired
+		}
+		return err
+	})
+	return entry.Version, err
+}
+
+// arenaPrecondition проверяет текущую запись перед условной записью. slot и err - результат
+// поиска записи в шарде (см. arenaShard.get); ненулевая ошибка отменяет запись.
+type arenaPrecondition func(slot *arenaSlot, err error, now int64) error
+
+// put записывает данные в кэш, если выполняется условие cond (nil - запись безусловная),
+// и возвращает дату истечения и версию записанной записи
+func (a *Arena) put(data model.EntryPutData, cond arenaPrecondition) (model.EntryGetData, error) {
+	if a.closed.Load() {
+		return model.EntryGetData{}, def.ErrClosed
+	}
+	// Сериализация выполняется до lock
+	ent, err := a.newEntry(data)
+	if err != nil {
+		return model.EntryGetData{}, err
+	}
+
+	hash, s := a.locate(data.Key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	if cond != nil {
+		idx, err := s.get(data.Key, hash, now)
+		if err := cond(&s.slots[idx], err, now); err != nil {
+			return model.EntryGetData{}, err
+		}
+	}
+
+	ent.expiresAt = now + int64(ent.ttl)
+	idx, err := s.store(ent, hash)
+	if err != nil {
+		return model.EntryGetData{}, err
+	}
+	a.notifyPut(s, idx)
+
+	return model.EntryGetData{
+		Key:       data.Key,
+		ExpiresAt: unixTime(s.slots[idx].expiresAt),
+		Version:   s.slots[idx].version,
+	}, nil
+}
+
+// newEntry сериализует значение и параметры записи. Дата истечения задается под блокировкой шарда.
+func (a *Arena) newEntry(data model.EntryPutData) (arenaEntry, error) {
+	if err := checkArenaSettings(data.TTL, data.StaleTTL, data.RefreshAhead, data.Tags); err != nil {
+		return arenaEntry{}, err
+	}
+	value, err := json.Marshal(data.Value)
+	if err != nil {
+		return arenaEntry{}, fmt.Errorf("key %q: %w", data.Key, err)
+	}
+
+	ttl := data.TTL
+	if ttl == 0 {
+		ttl = a.defaultTTL
+	}
+
+	return arenaEntry{
+		key:          data.Key,
+		value:        value,
+		tags:         encodeArenaTags(uniqueTags(data.Tags)),
+		ttl:          ttl,
+		sliding:      data.Sliding,
+		staleTTL:     data.StaleTTL,
+		refreshAhead: data.RefreshAhead,
+	}, nil
+}
+
+// checkArenaSettings проверяет параметры записи так же, как pkg/lru: для отрицательных сроков
+// возвращает lru.ErrInvalidTTL, для пустого тега - lru.ErrInvalidOption
+func checkArenaSettings(ttl, staleTTL, refreshAhead time.Duration, tags []string) error {
+	if ttl < 0 || staleTTL < 0 || refreshAhead < 0 {
+		return lru.ErrInvalidTTL
+	}
+	for _, tag := range tags {
+		if len(tag) == 0 {
+			return fmt.Errorf("%w: tag can not be empty", lru.ErrInvalidOption)
+		}
+	}
+	return nil
+}
+
+// Get получение данных из кэша по ключу. Для отсутствующих записей возвращает ErrNotFound,
+// для истекших - ErrExpired.
+//
+// Шард блокируется на чтение, а запись только отмечается как прочитанная (см. arenaShard).
+// Исключение - записи со скользящим истечением: их TTL продлевается сразу, для чего шард
+// блокируется на запись.
+func (a *Arena) Get(ctx context.Context, key string) (model.EntryGetData, error) {
+	if a.closed.Load() {
+		return model.EntryGetData{}, def.ErrClosed
+	}
+
+	hash, s := a.locate(key)
+	s.mu.RLock()
+
+	now := time.Now().UnixNano()
+	idx, err := s.get(key, hash, now)
+	if err != nil {
+		s.mu.RUnlock()
+		return model.EntryGetData{}, err
+	}
+	if slot := &s.slots[idx]; slot.sliding && !slot.expired(now) {
+		s.mu.RUnlock()
+		return a.getSliding(s, key, hash)
+	}
+
+	raw, entry := s.read(idx, now)
+	refresh, settings := s.refreshSettings(idx, now)
+	s.access(idx)
+	s.mu.RUnlock()
+
+	if refresh {
+		a.refresh(key, settings)
+	}
+	return decodeEntry(raw, entry)
+}
+
+// getSliding читает запись со скользящим истечением под блокировкой шарда на запись
+// и продлевает ее TTL
+func (a *Arena) getSliding(s *arenaShard, key string, hash uint64) (model.EntryGetData, error) {
+	s.mu.Lock()
+
+	// Запись могла измениться, пока шард не был заблокирован
+	now := time.Now().UnixNano()
+	idx, err := s.get(key, hash, now)
+	if err != nil {
+		s.mu.Unlock()
+		return model.EntryGetData{}, err
+	}
+
+	if slot := &s.slots[idx]; !slot.expired(now) && slot.ttl > 0 {
+		slot.expiresAt = now + int64(slot.ttl)
+		s.schedule(idx)
+	}
+	if s.promote {
+		s.moveToFront(idx)
+	}
+
+	raw, entry := s.read(idx, now)
+	refresh, settings := s.refreshSettings(idx, now)
+	s.mu.Unlock()
+
+	if refresh {
+		a.refresh(key, settings)
+	}
+	return decodeEntry(raw, entry)
+}
+
+// decodeEntry декодирует значение записи, прочитанной arenaShard.read
+func decodeEntry(raw []byte, entry model.EntryGetData) (model.EntryGetData, error) {
+	value, err := decodeSnapshotValue(raw)
+	if err != nil {
+		return model.EntryGetData{}, fmt.Errorf("key %q: %w", entry.Key, err)
+	}
+	entry.Value = value
+	return entry, nil
+}
+
+// Evict ручное удаление данных по ключу. Для отсутствующих записей возвращает ErrNotFound,
+// для истекших - ErrExpired.
+func (a *Arena) Evict(ctx context.Context, key string) (value interface{}, err error) {
+	defer a.awaitLog()
+
+	return a.evict(key)
+}
+
+// evict удаляет запись по ключу, не дожидаясь сброса журнала изменений на диск (см. Evict)
+func (a *Arena) evict(key string) (value interface{}, err error) {
+	if a.closed.Load() {
+		return nil, def.ErrClosed
+	}
+
+	hash, s := a.locate(key)
+	s.mu.Lock()
+
+	idx := s.lookup(key, hash)
+	if idx == 0 {
+		s.mu.Unlock()
+		return nil, def.ErrNotFound
+	}
+
+	dead := s.slots[idx].dead(time.Now().UnixNano())
+	raw := bytes.Clone(s.value(idx))
+	s.remove(idx)
+	a.notifyEvict(key)
+	s.mu.Unlock()
+
+	if dead {
+		return nil, def.ErrExpired
+	}
+	return decodeSnapshotValue(raw)
+}
+
+// EvictAll ручная инвалидация всего кэша. Выделенные слабы сохраняются для новых записей.
+func (a *Arena) EvictAll(ctx context.Context) error {
+	defer a.awaitLog()
+
+	if a.closed.Load() {
+		return def.ErrClosed
+	}
+
+	a.lockAll()
+	defer a.unlockAll()
+
+	for _, s := range a.shards {
+		s.clear()
+	}
+	a.notifyClear()
+
+	return nil
+}
+
+// GetAll получение всего наполнения кэша в виде двух слайсов: слайса ключей и слайса значений.
+// Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
+//
+// Все шарды блокируются на чтение на время сбора, внутри каждого шарда пары идут от старейшей к новейшей.
+func (a *Arena) GetAll(ctx context.Context) (keys []string, values []interface{}, err error) {
+	if a.closed.Load() {
+		return nil, nil, def.ErrClosed
+	}
+
+	var raws [][]byte
+	a.rlockAll()
+	now := time.Now().UnixNano()
+	for _, s := range a.shards {
+		for idx := s.slots[0].prev; idx != 0; idx = s.slots[idx].prev {
+			if s.slots[idx].expired(now) {
+				continue
+			}
+			keys = append(keys, string(s.key(idx)))
+			raws = append(raws, bytes.Clone(s.value(idx)))
+		}
+	}
+	a.runlockAll()
+
+	if values, err = decodeValues(keys, raws); err != nil {
+		return nil, nil, err
+	}
+	return keys, values, nil
+}
+
+// decodeValues декодирует значения записей с ключами keys
+func decodeValues(keys []string, raws [][]byte) ([]interface{}, error) {
+	values := make([]interface{}, len(raws))
+	for i, raw := range raws {
+		value, err := decodeSnapshotValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", keys[i], err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// locate возвращает хэш ключа и шард, в котором хранится запись
+func (a *Arena) locate(key string) (uint64, *arenaShard) {
+	hash := maphash.String(a.seed, key)
+	return hash, a.shards[hash%uint64(len(a.shards))]
+}
+
+// lockAll блокирует все шарды на запись в порядке номеров
+func (a *Arena) lockAll() {
+	for _, s := range a.shards {
+		s.mu.Lock()
+	}
+}
+
+// unlockAll снимает блокировку, взятую lockAll
+func (a *Arena) unlockAll() {
+	for i := len(a.shards) - 1; i >= 0; i-- {
+		a.shards[i].mu.Unlock()
+	}
+}
+
+// rlockAll блокирует все шарды на чтение в порядке номеров
+func (a *Arena) rlockAll() {
+	for _, s := range a.shards {
+		s.mu.RLock()
+	}
+}
+
+// runlockAll снимает блокировку, взятую rlockAll
+func (a *Arena) runlockAll() {
+	for i := len(a.shards) - 1; i >= 0; i-- {
+		a.shards[i].mu.RUnlock()
+	}
+}
+
+// notifyPut дописывает в журнал изменений состояние записи слота idx, если журнал включен.
+// Вызывается под блокировкой шарда на запись, как и остальные notify.
+func (a *Arena) notifyPut(s *arenaShard, idx uint32) {
+	if l := a.oplog.Load(); l != nil {
+		line := s.snapshotRecord(idx)
+		l.write(opLogRecord{Op: opPut, Record: &line})
+	}
+}
+
+// notifyEvict дописывает в журнал изменений удаление записи с ключом key
+func (a *Arena) notifyEvict(key string) {
+	if l := a.oplog.Load(); l != nil {
+		l.write(opLogRecord{Op: opEvict, Key: key})
+	}
+}
+
+// awaitLog дожидается сброса изменений операции в журнал на диск при политике FsyncAlways (см. opLog.wait)
+func (a *Arena) awaitLog() {
+	a.oplog.Load().wait()
+}
+
+// notifyClear дописывает в журнал изменений очистку кэша
+func (a *Arena) notifyClear() {
+	if l := a.oplog.Load(); l != nil {
+		l.write(opLogRecord{Op: opEvictAll})
+	}
+}
+
+// uniqueTags возвращает теги без повторов в порядке первого появления
+func uniqueTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	unique := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		unique = append(unique, tag)
+	}
+	return unique
+}
+
+// unixTime возвращает дату, заданную в UnixNano; 0 соответствует нулевой дате
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
@@ -0,0 +1,383 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// MGet получение данных из кэша по нескольким ключам. Шард блокируется для каждого ключа отдельно.
+// Результаты располагаются на позициях соответствующих ключей, для промахов содержат ErrNotFound или ErrExpired.
+func (a *Arena) MGet(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	if a.closed.Load() {
+		return nil, def.ErrClosed
+	}
+
+	results := make([]model.EntryResult, len(keys))
+	for i, key := range keys {
+		results[i].Entry, results[i].Err = a.Get(ctx, key)
+		results[i].Entry.Key = key
+	}
+
+	return results, nil
+}
+
+// MPut запись нескольких значений в кэш. Шард блокируется для каждой записи отдельно.
+// Результаты содержат версии и даты истечения записанных записей или ошибки записи (например, ErrTooLarge).
+func (a *Arena) MPut(ctx context.Context, data []model.EntryPutData) ([]model.EntryResult, error) {
+	defer a.awaitLog()
+
+	if a.closed.Load() {
+		return nil, def.ErrClosed
+	}
+
+	results := make([]model.EntryResult, len(data))
+	for i, d := range data {
+		results[i].Entry, results[i].Err = a.put(d, nil)
+		results[i].Entry.Key = d.Key
+	}
+
+	return results, nil
+}
+
+// MDelete удаление данных по нескольким ключам. Шард блокируется для каждого ключа отдельно.
+// Результаты содержат удаленные значения или ErrNotFound, ErrExpired.
+func (a *Arena) MDelete(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	defer a.awaitLog()
+
+	if a.closed.Load() {
+		return nil, def.ErrClosed
+	}
+
+	results := make([]model.EntryResult, len(keys))
+	for i, key := range keys {
+		results[i].Entry.Key = key
+		results[i].Entry.Value, results[i].Err = a.evict(key)
+	}
+
+	return results, nil
+}
+
+// Incr атомарное увеличение числового значения записи на delta (int64 или float64).
+// Отсутствующая или истекшая запись создается с TTL ttl и значением delta, у существующей
+// сохраняются дата истечения и параметры. Для нечисловых значений возвращает ErrNotNumeric,
+// при переполнении - ErrOverflow.
+func (a *Arena) Incr(ctx context.Context, key string, delta interface{}, ttl time.Duration) (model.EntryGetData, error) {
+	defer a.awaitLog()
+
+	if _, ok := toNumber(delta); !ok {
+		return model.EntryGetData{}, fmt.Errorf("%w: delta %T", def.ErrNotNumeric, delta)
+	}
+	if a.closed.Load() {
+		return model.EntryGetData{}, def.ErrClosed
+	}
+
+	if ttl == 0 {
+		ttl = a.defaultTTL
+	}
+
+	hash, s := a.locate(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	ent := arenaEntry{key: key, ttl: ttl, expiresAt: now + int64(ttl)}
+	var current interface{} = int64(0)
+	if idx, err := s.get(key, hash, now); err == nil && !s.slots[idx].expired(now) {
+		ent = s.entry(idx)
+		if current, err = decodeSnapshotValue(ent.value); err != nil {
+			return model.EntryGetData{}, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+
+	value, err := addNumbers(current, delta)
+	if err != nil {
+		return model.EntryGetData{}, err
+	}
+	if ent.value, err = json.Marshal(value); err != nil {
+		return model.EntryGetData{}, err
+	}
+
+	idx, err := s.store(ent, hash)
+	if err != nil {
+		return model.EntryGetData{}, err
+	}
+	a.notifyPut(s, idx)
+
+	return model.EntryGetData{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: unixTime(s.slots[idx].expiresAt),
+		Version:   s.slots[idx].version,
+	}, nil
+}
+
+// arenaTxnView описывает состояние ключа с учетом уже обработанных операций транзакции
+type arenaTxnView struct {
+	value  interface{}
+	exists bool
+}
+
+// Txn атомарное выполнение транзакции: условия проверяются и операции выполняются по порядку
+// под блокировкой всех затронутых шардов, изменения применяются, только если все условия выполнены
+// и ни одна операция не завершилась ошибкой. Иначе возвращается *TxnError.
+func (a *Arena) Txn(ctx context.Context, data model.TxnData) ([]model.EntryResult, error) {
+	defer a.awaitLog()
+
+	for i, cond := range data.Conditions {
+		if len(cond.Key) == 0 {
+			return nil, fmt.Errorf("условие %d: некорректные входные данные", i)
+		}
+		switch cond.Cond {
+		case model.TxnCondExists, model.TxnCondAbsent, model.TxnCondVersion:
+		default:
+			return nil, fmt.Errorf("условие %d: неизвестный тип условия %q", i, cond.Cond)
+		}
+	}
+	for i, op := range data.Ops {
+		switch op.Op {
+		case model.TxnOpPut, model.TxnOpEvict:
+		case model.TxnOpIncr:
+			if _, ok := toNumber(op.Delta); !ok {
+				return nil, &def.TxnError{Cond: -1, Op: i, Err: fmt.Errorf("%w: delta %T", def.ErrNotNumeric, op.Delta)}
+			}
+		default:
+			return nil, fmt.Errorf("операция %d: неизвестный тип операции %q", i, op.Op)
+		}
+	}
+	if a.closed.Load() {
+		return nil, def.ErrClosed
+	}
+
+	// Блокировка затронутых шардов в порядке номеров, как в lockAll
+	touched := make(map[int]struct{})
+	for _, cond := range data.Conditions {
+		touched[a.shardIndex(cond.Key)] = struct{}{}
+	}
+	for _, op := range data.Ops {
+		touched[a.shardIndex(op.Data.Key)] = struct{}{}
+	}
+	shardIdxs := make([]int, 0, len(touched))
+	for shardIdx := range touched {
+		shardIdxs = append(shardIdxs, shardIdx)
+	}
+	sort.Ints(shardIdxs)
+	for _, shardIdx := range shardIdxs {
+		a.shards[shardIdx].mu.Lock()
+	}
+	defer func() {
+		for i := len(shardIdxs) - 1; i >= 0; i-- {
+			a.shards[shardIdxs[i]].mu.Unlock()
+		}
+	}()
+
+	now := time.Now().UnixNano()
+
+	// alive возвращает слот записи, если она есть и не истекла
+	alive := func(key string) (*arenaShard, uint64, uint32) {
+		hash, s := a.locate(key)
+		idx, err := s.get(key, hash, now)
+		if err != nil || s.slots[idx].expired(now) {
+			return s, hash, 0
+		}
+		return s, hash, idx
+	}
+
+	// Проверка условий
+	for i, cond := range data.Conditions {
+		s, _, idx := alive(cond.Key)
+		var ok bool
+		switch cond.Cond {
+		case model.TxnCondExists:
+			ok = idx != 0
+		case model.TxnCondAbsent:
+			ok = idx == 0
+		case model.TxnCondVersion:
+			ok = idx != 0 && s.slots[idx].version == cond.Version
+		}
+		if !ok {
+			return nil, &def.TxnError{Cond: i, Op: -1, Err: def.ErrConditionFailed}
+		}
+	}
+
+	// Вычисление операций без изменения кэша
+	entries, values := make([]arenaEntry, len(data.Ops)), make([]interface{}, len(data.Ops))
+	views := make(map[string]arenaTxnView)
+	view := func(key string) (arenaTxnView, error) {
+		if v, ok := views[key]; ok {
+			return v, nil
+		}
+		s, _, idx := alive(key)
+		if idx == 0 {
+			return arenaTxnView{}, nil
+		}
+		value, err := decodeSnapshotValue(s.value(idx))
+		return arenaTxnView{value: value, exists: true}, err
+	}
+	for i, op := range data.Ops {
+		key := op.Data.Key
+		if op.Op == model.TxnOpEvict {
+			views[key] = arenaTxnView{}
+			continue
+		}
+
+		value := op.Data.Value
+		if op.Op == model.TxnOpIncr {
+			current, err := view(key)
+			if err != nil {
+				return nil, &def.TxnError{Cond: -1, Op: i, Err: err}
+			}
+			if !current.exists {
+				current.value = int64(0)
+			}
+			if value, err = addNumbers(current.value, op.Delta); err != nil {
+				return nil, &def.TxnError{Cond: -1, Op: i, Err: err}
+			}
+		}
+
+		putData := op.Data
+		putData.Value = value
+		ent, err := a.newEntry(putData)
+		if err != nil {
+			return nil, &def.TxnError{Cond: -1, Op: i, Err: err}
+		}
+		if _, s := a.locate(key); !s.fits(ent) {
+			return nil, &def.TxnError{Cond: -1, Op: i, Err: def.ErrTooLarge}
+		}
+		entries[i], values[i] = ent, value
+		views[key] = arenaTxnView{value: value, exists: true}
+	}
+
+	// Применение операций. Состояние затронутых ключей до транзакции запоминается, чтобы откатить ее,
+	// если запись все же не удалась, а в журнал изменений попадают только итоговые состояния ключей.
+	undo := arenaTxnUndo{saved: make(map[string]struct{})}
+	results := make([]model.EntryResult, len(data.Ops))
+	for i, op := range data.Ops {
+		key := op.Data.Key
+		results[i].Entry.Key = key
+		hash, s := a.locate(key)
+		undo.save(s, key, hash)
+
+		if op.Op == model.TxnOpEvict {
+			idx := s.lookup(key, hash)
+			switch {
+			case idx == 0:
+				results[i].Err = def.ErrNotFound
+				continue
+			case s.slots[idx].dead(now):
+				results[i].Err = def.ErrExpired
+			default:
+				results[i].Entry.Value, results[i].Err = decodeSnapshotValue(s.value(idx))
+			}
+			s.remove(idx)
+			continue
+		}
+
+		ent := entries[i]
+		ent.expiresAt = now + int64(ent.ttl)
+		// Как и Incr, обновление существующей записи сохраняет ее дату истечения и параметры
+		if _, _, idx := alive(key); op.Op == model.TxnOpIncr && idx != 0 {
+			value := ent.value
+			ent = s.entry(idx)
+			ent.value = value
+		}
+
+		idx, err := s.store(ent, hash)
+		if err != nil {
+			err = errors.Join(err, undo.rollback(a))
+			return nil, &def.TxnError{Cond: -1, Op: i, Err: err}
+		}
+		results[i].Entry.Value = values[i]
+		results[i].Entry.ExpiresAt = unixTime(s.slots[idx].expiresAt)
+		results[i].Entry.Version = s.slots[idx].version
+	}
+
+	undo.commit(a)
+	return results, nil
+}
+
+// arenaTxnKey описывает состояние ключа до транзакции
+type arenaTxnKey struct {
+	s       *arenaShard
+	key     string
+	hash    uint64
+	existed bool
+	entry   arenaEntry
+	version uint64
+}
+
+// arenaTxnUndo запоминает состояние ключей, затронутых транзакцией, в порядке первого обращения
+type arenaTxnUndo struct {
+	keys  []arenaTxnKey
+	saved map[string]struct{}
+}
+
+// save запоминает состояние ключа key, если оно еще не запомнено
+func (u *arenaTxnUndo) save(s *arenaShard, key string, hash uint64) {
+	if _, ok := u.saved[key]; ok {
+		return
+	}
+	u.saved[key] = struct{}{}
+
+	k := arenaTxnKey{s: s, key: key, hash: hash}
+	if idx := s.lookup(key, hash); idx != 0 {
+		k.existed, k.entry, k.version = true, s.entry(idx), s.slots[idx].version
+	}
+	u.keys = append(u.keys, k)
+}
+
+// rollback возвращает затронутые ключи в состояние до транзакции. Записи, вытесненные транзакцией
+// ради места, не восстанавливаются, как и при любой другой записи в кэш. Если прежнюю запись
+// восстановить не удалось, ее удаление дописывается в журнал изменений, чтобы журнал совпадал
+// с содержимым кэша, а ошибка возвращается.
+func (u *arenaTxnUndo) rollback(a *Arena) error {
+	for _, k := range u.keys {
+		if idx := k.s.lookup(k.key, k.hash); idx != 0 {
+			k.s.remove(idx)
+		}
+	}
+
+	var errs []error
+	for _, k := range u.keys {
+		if !k.existed {
+			continue
+		}
+		idx, err := k.s.store(k.entry, k.hash)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback key %q: %w", k.key, err))
+			continue
+		}
+		k.s.slots[idx].version = k.version
+	}
+	// Восстановленную запись могла вытеснить ради места другая восстановленная запись
+	for _, k := range u.keys {
+		if k.existed && k.s.lookup(k.key, k.hash) == 0 {
+			a.notifyEvict(k.key)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// commit дописывает в журнал изменений итоговое состояние ключей, затронутых транзакцией
+func (u *arenaTxnUndo) commit(a *Arena) {
+	for _, k := range u.keys {
+		switch idx := k.s.lookup(k.key, k.hash); {
+		case idx != 0:
+			a.notifyPut(k.s, idx)
+		case k.existed:
+			a.notifyEvict(k.key)
+		}
+	}
+}
+
+// shardIndex возвращает номер шарда, в котором хранится запись с ключом key
+func (a *Arena) shardIndex(key string) int {
+	hash, _ := a.locate(key)
+	return int(hash % uint64(len(a.shards)))
+}
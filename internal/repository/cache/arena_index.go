@@ -0,0 +1,308 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/maphash"
+	"math/rand/v2"
+)
+
+// Индексы шарда Arena, как и кольцо порядка использования, связывают слоты номерами, а не указателями:
+//   - цепочки слотов с одинаковым хэшем ключа (arenaSlot.chain) начинаются в index, ключи внутри
+//     цепочки сравниваются побайтно, поэтому разные ключи с одним хэшем хранятся одновременно;
+//   - декартово дерево ключей (arenaSlot.left, right и priority) с корнем в root упорядочивает
+//     ключи для поиска и удаления по префиксу;
+//   - узлы тегов (arenaTagNode) связывают записи с их тегами, цепочки узлов с одинаковым хэшем
+//     тега начинаются в tagIndex;
+//   - куча expiry упорядочивает номера слотов истекающих записей по сроку удаления, чтобы фоновая
+//     очистка не просматривала записи, срок которых не прошел.
+//
+// Памяти слабов индексы не требуют, но ключи и теги при обходе читаются из чанков записей.
+
+// arenaTagNode связывает запись с одним из ее тегов. Узлы с одинаковым хэшем тега образуют
+// двусвязную цепочку, а узлы одной записи - список, начинающийся в arenaSlot.tagNode.
+type arenaTagNode struct {
+	hash       uint64 // хэш тега
+	slot       uint32 // слот записи с тегом
+	prev, next uint32 // соседи в цепочке узлов с тем же хэшем тега
+	sibling    uint32 // следующий узел тегов той же записи
+}
+
+// indexInsert добавляет слот idx в цепочку слотов с хэшем его ключа
+func (s *arenaShard) indexInsert(idx uint32) {
+	slot := &s.slots[idx]
+	slot.chain = s.index[slot.hash]
+	s.index[slot.hash] = idx
+}
+
+// indexRemove убирает слот idx из цепочки слотов с хэшем его ключа
+func (s *arenaShard) indexRemove(idx uint32) {
+	slot := &s.slots[idx]
+	switch head := s.index[slot.hash]; {
+	case head == idx && slot.chain == 0:
+		delete(s.index, slot.hash)
+	case head == idx:
+		s.index[slot.hash] = slot.chain
+	default:
+		prev := head
+		for s.slots[prev].chain != idx {
+			prev = s.slots[prev].chain
+		}
+		s.slots[prev].chain = slot.chain
+	}
+	slot.chain = 0
+}
+
+// treapInsert добавляет слот idx в поддерево ключей с корнем root и возвращает новый корень поддерева
+func (s *arenaShard) treapInsert(root, idx uint32) uint32 {
+	if root == 0 {
+		s.slots[idx].priority = rand.Uint32()
+		return idx
+	}
+
+	node := &s.slots[root]
+	if bytes.Compare(s.key(idx), s.key(root)) < 0 {
+		node.left = s.treapInsert(node.left, idx)
+		if s.slots[node.left].priority > node.priority {
+			return s.rotateRight(root)
+		}
+	} else {
+		node.right = s.treapInsert(node.right, idx)
+		if s.slots[node.right].priority > node.priority {
+			return s.rotateLeft(root)
+		}
+	}
+	return root
+}
+
+// treapRemove убирает слот idx из поддерева ключей с корнем root и возвращает новый корень поддерева
+func (s *arenaShard) treapRemove(root, idx uint32) uint32 {
+	if root == 0 {
+		return 0
+	}
+	if root == idx {
+		slot := &s.slots[idx]
+		merged := s.treapMerge(slot.left, slot.right)
+		slot.left, slot.right = 0, 0
+		return merged
+	}
+
+	node := &s.slots[root]
+	if bytes.Compare(s.key(idx), s.key(root)) < 0 {
+		node.left = s.treapRemove(node.left, idx)
+	} else {
+		node.right = s.treapRemove(node.right, idx)
+	}
+	return root
+}
+
+// treapMerge объединяет поддеревья left и right (все ключи left меньше ключей right)
+func (s *arenaShard) treapMerge(left, right uint32) uint32 {
+	switch {
+	case left == 0:
+		return right
+	case right == 0:
+		return left
+	case s.slots[left].priority > s.slots[right].priority:
+		s.slots[left].right = s.treapMerge(s.slots[left].right, right)
+		return left
+	default:
+		s.slots[right].left = s.treapMerge(left, s.slots[right].left)
+		return right
+	}
+}
+
+// rotateRight поднимает левого потомка root на его место и возвращает его
+func (s *arenaShard) rotateRight(root uint32) uint32 {
+	left := s.slots[root].left
+	s.slots[root].left = s.slots[left].right
+	s.slots[left].right = root
+	return left
+}
+
+// rotateLeft поднимает правого потомка root на его место и возвращает его
+func (s *arenaShard) rotateLeft(root uint32) uint32 {
+	right := s.slots[root].right
+	s.slots[root].right = s.slots[right].left
+	s.slots[right].left = root
+	return right
+}
+
+// ascend вызывает fn для слотов записей, ключи которых начинаются с prefix, в порядке ключей,
+// пока fn возвращает true
+func (s *arenaShard) ascend(prefix []byte, fn func(idx uint32) bool) {
+	var stack []uint32
+	idx := s.root
+	for {
+		// Спуск к наименьшему ключу не меньше prefix: поддеревья с меньшими ключами пропускаются
+		for idx != 0 {
+			if bytes.Compare(s.key(idx), prefix) < 0 {
+				idx = s.slots[idx].right
+				continue
+			}
+			stack = append(stack, idx)
+			idx = s.slots[idx].left
+		}
+		if len(stack) == 0 {
+			return
+		}
+
+		idx = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !bytes.HasPrefix(s.key(idx), prefix) || !fn(idx) {
+			return
+		}
+		idx = s.slots[idx].right
+	}
+}
+
+// tag добавляет запись слота idx в цепочки узлов ее тегов
+func (s *arenaShard) tag(idx uint32) {
+	buf := s.tags(idx)
+	for len(buf) > 0 {
+		n, size := binary.Uvarint(buf)
+		hash := maphash.Bytes(s.seed, buf[size:size+int(n)])
+		buf = buf[size+int(n):]
+
+		node := s.newTagNode()
+		next := s.tagIndex[hash]
+		s.tagNodes[node] = arenaTagNode{hash: hash, slot: idx, next: next, sibling: s.slots[idx].tagNode}
+		if next != 0 {
+			s.tagNodes[next].prev = node
+		}
+		s.tagIndex[hash] = node
+		s.slots[idx].tagNode = node
+	}
+}
+
+// untag убирает запись слота idx из цепочек узлов ее тегов
+func (s *arenaShard) untag(idx uint32) {
+	for n := s.slots[idx].tagNode; n != 0; {
+		node := s.tagNodes[n]
+		switch {
+		case node.prev != 0:
+			s.tagNodes[node.prev].next = node.next
+		case node.next != 0:
+			s.tagIndex[node.hash] = node.next
+		default:
+			delete(s.tagIndex, node.hash)
+		}
+		if node.next != 0 {
+			s.tagNodes[node.next].prev = node.prev
+		}
+
+		s.tagNodes[n] = arenaTagNode{}
+		s.freeTagNodes = append(s.freeTagNodes, n)
+		n = node.sibling
+	}
+	s.slots[idx].tagNode = 0
+}
+
+// newTagNode возвращает номер свободного узла тегов
+func (s *arenaShard) newTagNode() uint32 {
+	if n := len(s.freeTagNodes); n > 0 {
+		node := s.freeTagNodes[n-1]
+		s.freeTagNodes = s.freeTagNodes[:n-1]
+		return node
+	}
+	s.tagNodes = append(s.tagNodes, arenaTagNode{})
+	return uint32(len(s.tagNodes) - 1)
+}
+
+// tagged дописывает в matched номера слотов записей с тегом tag
+func (s *arenaShard) tagged(tag string, matched []uint32) []uint32 {
+	for n := s.tagIndex[maphash.String(s.seed, tag)]; n != 0; n = s.tagNodes[n].next {
+		idx := s.tagNodes[n].slot
+		// Узлы записи добавляются в цепочки подряд, поэтому запись с двумя тегами одного хэша
+		// встречается в цепочке два раза подряд
+		if len(matched) > 0 && matched[len(matched)-1] == idx || !hasArenaTag(s.tags(idx), tag) {
+			continue
+		}
+		matched = append(matched, idx)
+	}
+	return matched
+}
+
+// schedule ставит слот idx в кучу сроков удаления по текущей дате истечения записи
+// или убирает его оттуда, если запись не истекает
+func (s *arenaShard) schedule(idx uint32) {
+	slot := &s.slots[idx]
+	switch {
+	case slot.expiresAt == 0:
+		s.unschedule(idx)
+	case slot.heapPos == 0:
+		s.expiry = append(s.expiry, idx)
+		slot.heapPos = uint32(len(s.expiry))
+		s.siftUp(len(s.expiry) - 1)
+	default:
+		s.heapFix(int(slot.heapPos - 1))
+	}
+}
+
+// unschedule убирает слот idx из кучи сроков удаления, если он там есть
+func (s *arenaShard) unschedule(idx uint32) {
+	if s.slots[idx].heapPos == 0 {
+		return
+	}
+
+	pos, last := int(s.slots[idx].heapPos-1), len(s.expiry)-1
+	if pos != last {
+		s.heapSwap(pos, last)
+	}
+	s.expiry = s.expiry[:last]
+	s.slots[idx].heapPos = 0
+	if pos != last {
+		s.heapFix(pos)
+	}
+}
+
+// heapLess сообщает, наступает ли срок удаления записи на позиции i кучи раньше, чем на позиции j
+func (s *arenaShard) heapLess(i, j int) bool {
+	return s.slots[s.expiry[i]].deadline() < s.slots[s.expiry[j]].deadline()
+}
+
+// heapSwap меняет местами позиции i и j кучи
+func (s *arenaShard) heapSwap(i, j int) {
+	s.expiry[i], s.expiry[j] = s.expiry[j], s.expiry[i]
+	s.slots[s.expiry[i]].heapPos = uint32(i + 1)
+	s.slots[s.expiry[j]].heapPos = uint32(j + 1)
+}
+
+// heapFix восстанавливает порядок кучи после изменения срока записи на позиции pos
+func (s *arenaShard) heapFix(pos int) {
+	if !s.siftDown(pos) {
+		s.siftUp(pos)
+	}
+}
+
+// siftUp поднимает запись на позиции j к корню кучи
+func (s *arenaShard) siftUp(j int) {
+	for j > 0 {
+		i := (j - 1) / 2
+		if !s.heapLess(j, i) {
+			return
+		}
+		s.heapSwap(i, j)
+		j = i
+	}
+}
+
+// siftDown опускает запись на позиции i0 к листьям кучи и сообщает, сдвинулась ли она
+func (s *arenaShard) siftDown(i0 int) bool {
+	i, n := i0, len(s.expiry)
+	for {
+		j := 2*i + 1
+		if j >= n {
+			break
+		}
+		if j2 := j + 1; j2 < n && s.heapLess(j2, j) {
+			j = j2
+		}
+		if !s.heapLess(j, i) {
+			break
+		}
+		s.heapSwap(i, j)
+		i = j
+	}
+	return i > i0
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// arenaLoad описывает выполняющуюся загрузку одного ключа
+type arenaLoad struct {
+	done     chan struct{} // закрывается по завершении загрузки
+	deadline time.Time     // крайний срок загрузки, нулевой - без ограничения
+	entry    model.EntryGetData
+	err      error
+}
+
+// arenaLoads объединяет одновременные загрузки одного ключа в одну
+type arenaLoads struct {
+	mu    sync.Mutex
+	calls map[string]*arenaLoad
+}
+
+// GetOrLoad получение данных из кэша по ключу, а при промахе - загрузка через loader и запись в кэш.
+// Одновременные промахи по одному ключу ожидают одну загрузку, а по истечении таймаута загрузки
+// освобождаются с context.DeadlineExceeded (так же, как lru.Cache.GetOrLoad).
+func (a *Arena) GetOrLoad(ctx context.Context, key string, loader def.Loader) (model.EntryGetData, error) {
+	defer a.awaitLog()
+
+	entry, err := a.Get(ctx, key)
+	if err == nil {
+		return model.EntryGetData{Key: key, Value: entry.Value, ExpiresAt: entry.ExpiresAt}, nil
+	}
+	if !errors.Is(err, def.ErrNotFound) {
+		return model.EntryGetData{}, err
+	}
+
+	call, started := a.loads.join(key, a.loaderTimeout)
+	if started {
+		go a.load(context.WithoutCancel(ctx), key, loader, call, model.EntryPutData{}, true)
+	}
+
+	var timeout <-chan time.Time
+	if !call.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(call.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return model.EntryGetData{}, call.err
+		}
+		return call.entry, nil
+	case <-ctx.Done():
+		return model.EntryGetData{}, ctx.Err()
+	case <-timeout:
+		a.loads.forget(key, call)
+		return model.EntryGetData{}, context.DeadlineExceeded
+	}
+}
+
+// SetLoader регистрация загрузчика для фонового обновления записей (refresh-ahead и stale_ttl)
+func (a *Arena) SetLoader(loader def.Loader) {
+	if loader == nil {
+		a.loader.Store(nil)
+		return
+	}
+	a.loader.Store(&loader)
+}
+
+// refresh запускает фоновое обновление ключа зарегистрированным загрузчиком с сохранением
+// параметров записи settings, если загрузчик задан и ключ еще не загружается
+func (a *Arena) refresh(key string, settings model.EntryPutData) {
+	loader := a.loader.Load()
+	if loader == nil || a.closed.Load() {
+		return
+	}
+
+	if call, started := a.loads.join(key, a.loaderTimeout); started {
+		go a.load(context.Background(), key, *loader, call, settings, false)
+	}
+}
+
+// join возвращает загрузку ключа, регистрируя новую с таймаутом timeout (0 - без ограничения),
+// если ключ еще не загружается. started сообщает, что загрузка зарегистрирована этим вызовом
+// и ее нужно запустить.
+func (g *arenaLoads) join(key string, timeout time.Duration) (call *arenaLoad, started bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		return call, false
+	}
+
+	call = &arenaLoad{done: make(chan struct{})}
+	if timeout > 0 {
+		call.deadline = time.Now().Add(timeout)
+	}
+	g.calls[key] = call
+	return call, true
+}
+
+// forget удаляет загрузку call из группы, если ключ key все еще загружается ею
+func (g *arenaLoads) forget(key string, call *arenaLoad) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// load выполняет загрузку ключа, записывает значение в кэш с параметрами settings и сохраняет
+// результат в call. Если установлен recheck, перед загрузкой проверяется, не появилось ли
+// значение в кэше.
+func (a *Arena) load(ctx context.Context, key string, loader def.Loader, call *arenaLoad, settings model.EntryPutData, recheck bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("%w: %v", lru.ErrLoaderPanic, r)
+		}
+
+		// Загрузка удаляется из группы после записи в кэш, чтобы следующие вызовы
+		// получили уже закэшированное значение
+		a.loads.forget(key, call)
+		close(call.done)
+	}()
+
+	// Значение могло быть записано, пока загрузка не была зарегистрирована
+	if recheck {
+		if entry, err := a.Get(ctx, key); !errors.Is(err, def.ErrNotFound) {
+			call.entry, call.err = model.EntryGetData{Key: key, Value: entry.Value, ExpiresAt: entry.ExpiresAt}, err
+			return
+		}
+	}
+
+	if !call.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, call.deadline)
+		defer cancel()
+	}
+
+	value, ttl, err := loader(ctx, key)
+	if err != nil {
+		call.err = err
+		return
+	}
+
+	settings.Key, settings.Value, settings.TTL = key, value, ttl
+	entry, err := a.put(settings, nil)
+	call.entry = model.EntryGetData{Key: key, Value: value, ExpiresAt: entry.ExpiresAt}
+	if !errors.Is(err, def.ErrTooLarge) {
+		call.err = err
+	}
+}
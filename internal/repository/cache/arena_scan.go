@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// Scan постраничный обход кэша. Курсор кодируется так же, как у LRU (см. encodeCursor),
+// и так же остается действительным при вытеснении записей.
+func (a *Arena) Scan(ctx context.Context, cursor string, limit int, order string) (model.ScanPage, error) {
+	if order != model.ScanOrderLRU && order != model.ScanOrderMRU {
+		return model.ScanPage{}, def.ErrInvalidCursor
+	}
+
+	from, err := decodeCursor(cursor)
+	if err != nil {
+		return model.ScanPage{}, err
+	}
+
+	switch {
+	case a.closed.Load():
+		return model.ScanPage{}, def.ErrClosed
+	case limit <= 0:
+		return model.ScanPage{}, fmt.Errorf("%w: limit must be > 0", lru.ErrInvalidOption)
+	case from.Shard >= len(a.shards):
+		return model.ScanPage{}, def.ErrInvalidCursor
+	case from.Done():
+		return model.ScanPage{}, nil
+	}
+
+	keys := make([]string, 0, limit)
+	raws := make([][]byte, 0, limit)
+	mru := order == model.ScanOrderMRU
+
+	next := from
+	for next.Shard < len(a.shards) {
+		s := a.shards[next.Shard]
+		var lastHash uint64
+		if next.Stamp > 0 {
+			lastHash, _ = a.locate(next.Key)
+		}
+
+		s.mu.RLock()
+		var last uint32
+		keys, raws, last = s.scan(next, lastHash, limit-len(keys), mru, keys, raws, time.Now().UnixNano())
+		if last != 0 {
+			next.Stamp, next.Key = s.slots[last].stamp, string(s.key(last))
+		}
+		s.mu.RUnlock()
+
+		if len(keys) == limit {
+			break
+		}
+		next = lru.Cursor[string]{Shard: next.Shard + 1}
+	}
+	if next.Shard == len(a.shards) {
+		next.Shard = -1
+	}
+
+	values, err := decodeValues(keys, raws)
+	if err != nil {
+		return model.ScanPage{}, err
+	}
+	return model.ScanPage{
+		Keys:   keys,
+		Values: values,
+		Cursor: encodeCursor(next),
+	}, nil
+}
+
+// scan дописывает в слайсы не более limit не истекших на момент now записей шарда, следующих
+// в кольце за позицией cursor (lastHash - хэш ключа курсора): к началу кольца при обходе от давно
+// не использовавшихся записей, к концу - при обходе mru. Возвращает номер слота последней
+// просмотренной записи (0, если ни одной записи не просмотрено).
+func (s *arenaShard) scan(cursor lru.Cursor[string], lastHash uint64, limit int, mru bool, keys []string, raws [][]byte, now int64) ([]string, [][]byte, uint32) {
+	// Отметки растут от конца кольца к началу
+	step := func(idx uint32) uint32 { return s.slots[idx].prev }
+	after := func(idx uint32) bool { return s.slots[idx].stamp > cursor.Stamp }
+	if mru {
+		step = func(idx uint32) uint32 { return s.slots[idx].next }
+		after = func(idx uint32) bool { return s.slots[idx].stamp < cursor.Stamp }
+	}
+
+	idx := step(0)
+	if cursor.Stamp > 0 {
+		if last := s.lookup(cursor.Key, lastHash); last != 0 && s.slots[last].stamp == cursor.Stamp {
+			// Последняя отданная запись не перемещалась: обход продолжается сразу за ней
+			idx = step(last)
+		} else {
+			// Иначе пропускаются записи до отметки курсора
+			for idx != 0 && !after(idx) {
+				idx = step(idx)
+			}
+		}
+	}
+
+	var last uint32
+	for ; idx != 0 && limit > 0; idx = step(idx) {
+		last = idx
+		if s.slots[idx].expired(now) {
+			continue
+		}
+		keys = append(keys, string(s.key(idx)))
+		raws = append(raws, bytes.Clone(s.value(idx)))
+		limit--
+	}
+	return keys, raws, last
+}
+
+// ScanPrefix получение записей, ключи которых начинаются с prefix, в виде двух слайсов, как в GetAll.
+// Ключи упорядочены лексикографически. Записи каждого шарда берутся из его дерева ключей по порядку,
+// поэтому просматриваются только подходящие записи.
+func (a *Arena) ScanPrefix(ctx context.Context, prefix string) (keys []string, values []interface{}, err error) {
+	if a.closed.Load() {
+		return nil, nil, def.ErrClosed
+	}
+
+	type pair struct {
+		key string
+		raw []byte
+	}
+	var pairs []pair
+
+	rawPrefix := []byte(prefix)
+	for _, s := range a.shards {
+		s.mu.RLock()
+		now := time.Now().UnixNano()
+		s.ascend(rawPrefix, func(idx uint32) bool {
+			if !s.slots[idx].expired(now) {
+				pairs = append(pairs, pair{key: string(s.key(idx)), raw: bytes.Clone(s.value(idx))})
+			}
+			return true
+		})
+		s.mu.RUnlock()
+	}
+
+	// Ключи упорядочены внутри шарда, но не между шардами
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+
+	keys = make([]string, len(pairs))
+	raws := make([][]byte, len(pairs))
+	for i, p := range pairs {
+		keys[i], raws[i] = p.key, p.raw
+	}
+	if values, err = decodeValues(keys, raws); err != nil {
+		return nil, nil, err
+	}
+	return keys, values, nil
+}
+
+// EvictPrefix удаление всех записей, ключи которых начинаются с prefix. Возвращает количество удаленных
+// не истекших записей. Шарды блокируются по очереди, каждый один раз; записи берутся из дерева ключей.
+func (a *Arena) EvictPrefix(ctx context.Context, prefix string) (n int, err error) {
+	rawPrefix := []byte(prefix)
+	return a.evictMatching(func(s *arenaShard, matched []uint32) []uint32 {
+		s.ascend(rawPrefix, func(idx uint32) bool {
+			matched = append(matched, idx)
+			return true
+		})
+		return matched
+	})
+}
+
+// EvictByTag удаление всех записей, помеченных тегом tag. Возвращает количество удаленных не истекших записей.
+// Записи берутся из индекса тегов.
+func (a *Arena) EvictByTag(ctx context.Context, tag string) (n int, err error) {
+	if len(tag) == 0 {
+		return 0, fmt.Errorf("%w: tag can not be empty", lru.ErrInvalidOption)
+	}
+	return a.evictMatching(func(s *arenaShard, matched []uint32) []uint32 {
+		return s.tagged(tag, matched)
+	})
+}
+
+// evictMatching удаляет записи, номера слотов которых match дописывает в matched, и возвращает
+// количество удаленных не истекших записей
+func (a *Arena) evictMatching(match func(s *arenaShard, matched []uint32) []uint32) (n int, err error) {
+	defer a.awaitLog()
+
+	if a.closed.Load() {
+		return 0, def.ErrClosed
+	}
+
+	var matched []uint32
+	for _, s := range a.shards {
+		s.mu.Lock()
+		now := time.Now().UnixNano()
+
+		// Индексы меняются при удалении, поэтому записи сначала собираются
+		matched = match(s, matched[:0])
+		for _, idx := range matched {
+			if !s.slots[idx].dead(now) {
+				n++
+			}
+			key := string(s.key(idx))
+			s.remove(idx)
+			a.notifyEvict(key)
+		}
+		s.mu.Unlock()
+	}
+
+	return n, nil
+}
@@ -0,0 +1,470 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// arenaSlot описывает запись шарда Arena. Слот не содержит указателей: ключ, значение и теги записи
+// хранятся в чанке слаба, а соседи по кольцу порядка использования и по индексам (см. arena_index.go)
+// задаются номерами слотов. Слоты не копируются, к ним обращаются по номеру.
+type arenaSlot struct {
+	hash       uint64 // хэш ключа, под которым слот записан в индексе
+	chain      uint32 // следующий слот с тем же хэшем ключа
+	prev, next uint32 // соседи в кольце порядка использования
+	used       bool   // слот занят записью
+	referenced atomic.Bool
+
+	left, right uint32 // потомки в декартовом дереве ключей
+	priority    uint32 // приоритет в декартовом дереве ключей
+	tagNode     uint32 // первый узел тегов записи, 0 - тегов нет
+	heapPos     uint32 // позиция в куче сроков удаления плюс один, 0 - слот не в куче
+
+	stamp   uint64 // отметка порядка использования, растет при каждом переносе в начало кольца
+	version uint64 // версия записи, растет при каждой записи значения
+
+	expiresAt    int64 // дата истечения (UnixNano), 0 - запись не истекает
+	ttl          time.Duration
+	sliding      bool
+	staleTTL     time.Duration
+	refreshAhead time.Duration
+
+	chunk    arenaChunk // чанк с ключом, значением и тегами записи
+	keyLen   uint32
+	valueLen uint32
+	tagsLen  uint32
+}
+
+// expired сообщает, истекла ли запись на момент now (UnixNano)
+func (slot *arenaSlot) expired(now int64) bool {
+	return slot.expiresAt != 0 && now > slot.expiresAt
+}
+
+// dead сообщает, прошел ли на момент now срок записи с учетом StaleTTL
+func (slot *arenaSlot) dead(now int64) bool {
+	return slot.expiresAt != 0 && now > slot.expiresAt+int64(slot.staleTTL)
+}
+
+// deadline возвращает момент, после которого запись можно удалить (UnixNano), с учетом StaleTTL
+func (slot *arenaSlot) deadline() int64 {
+	return slot.expiresAt + int64(slot.staleTTL)
+}
+
+// refreshDue сообщает, пора ли на момент now обновить запись: она истекла или до истечения
+// осталось меньше RefreshAhead
+func (slot *arenaSlot) refreshDue(now int64) bool {
+	return slot.expiresAt != 0 && now >= slot.expiresAt-int64(slot.refreshAhead)
+}
+
+// arenaEntry задает запись для сохранения в шард: сериализованные значение и теги и параметры записи
+type arenaEntry struct {
+	key          string
+	value        []byte // значение в JSON
+	tags         []byte // теги, закодированные encodeArenaTags
+	expiresAt    int64
+	ttl          time.Duration
+	sliding      bool
+	staleTTL     time.Duration
+	refreshAhead time.Duration
+}
+
+// arenaShard задает независимую часть Arena со своей блокировкой. Все методы arenaShard
+// подразумевают, что lock уже вызван (на чтение для чтения записей, на запись для изменений).
+//
+// Порядок использования ведется кольцом слотов с головой в слоте 0: начало кольца (next головы) -
+// недавно использованные записи, конец (prev головы) - давно не использовавшиеся. Чтение под
+// блокировкой на чтение не переносит запись, а только ставит ей отметку referenced; отмеченная
+// запись, дошедшая до конца кольца, получает второй шанс и переносится в начало вместо вытеснения.
+type arenaShard struct {
+	mu sync.RWMutex
+
+	slots     []arenaSlot       // слоты, слот 0 - голова кольца
+	freeSlots []uint32          // номера свободных слотов
+	index     map[uint64]uint32 // хэш ключа - первый слот цепочки с этим хэшем
+	slabs     arenaSlabs
+
+	root         uint32            // корень декартова дерева ключей
+	tagNodes     []arenaTagNode    // узлы тегов, узел 0 не используется
+	freeTagNodes []uint32          // номера свободных узлов тегов
+	tagIndex     map[uint64]uint32 // хэш тега - первый узел цепочки с этим хэшем
+	expiry       []uint32          // куча слотов истекающих записей по сроку удаления
+	seed         maphash.Seed      // затравка хэшей тегов
+
+	stamp   uint64 // последняя выданная отметка порядка использования
+	version uint64 // последняя выданная версия записи
+	promote bool   // учитывать ли чтение в порядке использования
+}
+
+// newArenaShard создает шард на size записей с лимитом памяти maxBytes байт (0 - без ограничения)
+func newArenaShard(size int, maxBytes int64, promote bool, seed maphash.Seed) *arenaShard {
+	s := &arenaShard{
+		slots:     make([]arenaSlot, size+1),
+		freeSlots: make([]uint32, 0, size),
+		index:     make(map[uint64]uint32, size),
+		slabs:     newArenaSlabs(maxBytes),
+		tagNodes:  make([]arenaTagNode, 1),
+		tagIndex:  make(map[uint64]uint32),
+		seed:      seed,
+		promote:   promote,
+	}
+	for i := size; i > 0; i-- {
+		s.freeSlots = append(s.freeSlots, uint32(i))
+	}
+	return s
+}
+
+// len возвращает количество записей шарда
+func (s *arenaShard) len() int {
+	return len(s.slots) - 1 - len(s.freeSlots)
+}
+
+// lookup возвращает номер слота записи с ключом key и хэшем hash, 0 - записи нет
+func (s *arenaShard) lookup(key string, hash uint64) uint32 {
+	for idx := s.index[hash]; idx != 0; idx = s.slots[idx].chain {
+		if string(s.key(idx)) == key {
+			return idx
+		}
+	}
+	return 0
+}
+
+// get возвращает номер слота записи, которую на момент now еще можно отдать (в том числе как устаревшую).
+// Если записи нет, возвращает ErrNotFound, а если она истекла, но еще не удалена - ErrExpired.
+func (s *arenaShard) get(key string, hash uint64, now int64) (uint32, error) {
+	idx := s.lookup(key, hash)
+	if idx == 0 {
+		return 0, lru.ErrNotFound
+	}
+	if s.slots[idx].dead(now) {
+		return 0, lru.ErrExpired
+	}
+	return idx, nil
+}
+
+// key возвращает ключ записи слота idx (срез памяти слаба)
+func (s *arenaShard) key(idx uint32) []byte {
+	slot := &s.slots[idx]
+	return s.slabs.bytes(slot.chunk, slot.keyLen)
+}
+
+// value возвращает значение записи слота idx в JSON (срез памяти слаба)
+func (s *arenaShard) value(idx uint32) []byte {
+	slot := &s.slots[idx]
+	return s.slabs.bytes(slot.chunk, slot.keyLen+slot.valueLen)[slot.keyLen:]
+}
+
+// tags возвращает закодированные теги записи слота idx (срез памяти слаба)
+func (s *arenaShard) tags(idx uint32) []byte {
+	slot := &s.slots[idx]
+	return s.slabs.bytes(slot.chunk, slot.keyLen+slot.valueLen+slot.tagsLen)[slot.keyLen+slot.valueLen:]
+}
+
+// store записывает запись ent с хэшем ключа hash, при необходимости вытесняя давно не использовавшиеся
+// записи, и возвращает номер ее слота. Возвращает ErrTooLarge, если запись не помещается в слаб
+// или в лимит памяти шарда.
+func (s *arenaShard) store(ent arenaEntry, hash uint64) (uint32, error) {
+	size := len(ent.key) + len(ent.value) + len(ent.tags)
+	class, ok := s.classFor(size)
+	if !ok {
+		return 0, lru.ErrTooLarge
+	}
+
+	idx := s.lookup(ent.key, hash)
+	if idx != 0 && s.slabs.class[s.slots[idx].chunk.slab] != class {
+		// Запись переезжает в чанк другого класса, а прежний освобождается до выделения нового
+		s.remove(idx)
+		idx = 0
+	}
+
+	if idx == 0 {
+		if len(s.freeSlots) == 0 {
+			s.remove(s.victim())
+		}
+		chunk, err := s.alloc(class)
+		if err != nil {
+			return 0, err
+		}
+
+		idx = s.freeSlots[len(s.freeSlots)-1]
+		s.freeSlots = s.freeSlots[:len(s.freeSlots)-1]
+		s.slots[idx].used = true
+		s.slots[idx].hash = hash
+		s.slots[idx].chunk = chunk
+		s.write(idx, ent)
+		s.indexInsert(idx)
+		s.root = s.treapInsert(s.root, idx)
+		s.pushFront(idx)
+	} else {
+		// Ключ не меняется, поэтому запись остается на своих местах в индексах ключей
+		s.untag(idx)
+		s.write(idx, ent)
+		s.moveToFront(idx)
+	}
+	s.tag(idx)
+
+	slot := &s.slots[idx]
+	s.version++
+	slot.version = s.version
+	slot.expiresAt = ent.expiresAt
+	slot.ttl = ent.ttl
+	slot.sliding = ent.sliding
+	slot.staleTTL = ent.staleTTL
+	slot.refreshAhead = ent.refreshAhead
+	slot.referenced.Store(false)
+	s.schedule(idx)
+
+	return idx, nil
+}
+
+// fits сообщает, помещается ли запись ent в слаб и в лимит памяти шарда
+func (s *arenaShard) fits(ent arenaEntry) bool {
+	_, ok := s.classFor(len(ent.key) + len(ent.value) + len(ent.tags))
+	return ok
+}
+
+// classFor возвращает класс чанка для записи размера size, если запись помещается в слаб
+// и в лимит памяти шарда
+func (s *arenaShard) classFor(size int) (uint8, bool) {
+	class, ok := arenaClassFor(size)
+	return class, ok && s.slabs.fits(class)
+}
+
+// write копирует ключ, значение и теги записи ent в чанк слота idx
+func (s *arenaShard) write(idx uint32, ent arenaEntry) {
+	slot := &s.slots[idx]
+	slot.keyLen, slot.valueLen, slot.tagsLen = uint32(len(ent.key)), uint32(len(ent.value)), uint32(len(ent.tags))
+	buf := s.slabs.bytes(slot.chunk, slot.keyLen+slot.valueLen+slot.tagsLen)
+	copy(buf, ent.key)
+	copy(buf[len(ent.key):], ent.value)
+	copy(buf[len(ent.key)+len(ent.value):], ent.tags)
+}
+
+// alloc выделяет чанк класса class. Пока чанк не помещается в лимит памяти, вытесняются давно
+// не использовавшиеся записи. Если лимит не исчерпан, но свободных чанков класса нет и слабов больше
+// не выделить, вытесняется давно не использовавшаяся запись того же класса; только если записей
+// класса нет, слабы перераспределяются между классами: записи слаба, выбранного arenaSlabs.donor,
+// вытесняются, и опустевший слаб отдается классу class.
+func (s *arenaShard) alloc(class uint8) (arenaChunk, error) {
+	for {
+		if chunk, ok := s.slabs.alloc(class); ok {
+			return chunk, nil
+		}
+
+		if !s.slabs.full(class) {
+			if victim := s.classVictim(class); victim != 0 {
+				s.remove(victim)
+				continue
+			}
+			if slab, ok := s.slabs.donor(class); ok {
+				s.evictSlab(slab)
+				continue
+			}
+		}
+
+		victim := s.victim()
+		if victim == 0 {
+			return arenaChunk{}, lru.ErrTooLarge
+		}
+		s.remove(victim)
+	}
+}
+
+// classVictim выбирает давно не использовавшуюся запись, чанк которой относится к классу class.
+// Возвращает 0, если записей класса нет.
+func (s *arenaShard) classVictim(class uint8) uint32 {
+	for idx := s.slots[0].prev; idx != 0; idx = s.slots[idx].prev {
+		if s.slabs.class[s.slots[idx].chunk.slab] == class {
+			return idx
+		}
+	}
+	return 0
+}
+
+// evictSlab вытесняет все записи, чанки которых лежат в слабе slab
+func (s *arenaShard) evictSlab(slab uint32) {
+	for idx := range s.slots[1:] {
+		if slot := &s.slots[idx+1]; slot.used && slot.chunk.slab == slab {
+			s.remove(uint32(idx + 1))
+		}
+	}
+}
+
+// victim выбирает запись для вытеснения: давно не использовавшуюся запись без отметки чтения.
+// Отмеченные записи переносятся в начало кольца. Возвращает 0, если вытеснять нечего.
+func (s *arenaShard) victim() uint32 {
+	// Каждая запись получает второй шанс не более одного раза за вызов
+	for i := s.len(); i >= 0; i-- {
+		idx := s.slots[0].prev
+		if idx == 0 {
+			return 0
+		}
+		if i > 0 && s.slots[idx].referenced.Swap(false) {
+			s.moveToFront(idx)
+			continue
+		}
+		return idx
+	}
+	return 0
+}
+
+// remove удаляет запись слота idx
+func (s *arenaShard) remove(idx uint32) {
+	// Ключ и теги читаются из чанка, поэтому запись убирается из индексов до его освобождения
+	s.unlink(idx)
+	s.indexRemove(idx)
+	s.root = s.treapRemove(s.root, idx)
+	s.untag(idx)
+	s.unschedule(idx)
+	s.slabs.release(s.slots[idx].chunk)
+
+	slot := &s.slots[idx]
+	slot.used = false
+	slot.keyLen, slot.valueLen, slot.tagsLen = 0, 0, 0
+	s.freeSlots = append(s.freeSlots, idx)
+}
+
+// entry возвращает копию записи слота idx
+func (s *arenaShard) entry(idx uint32) arenaEntry {
+	slot := &s.slots[idx]
+	return arenaEntry{
+		key:          string(s.key(idx)),
+		value:        bytes.Clone(s.value(idx)),
+		tags:         bytes.Clone(s.tags(idx)),
+		expiresAt:    slot.expiresAt,
+		ttl:          slot.ttl,
+		sliding:      slot.sliding,
+		staleTTL:     slot.staleTTL,
+		refreshAhead: slot.refreshAhead,
+	}
+}
+
+// read возвращает копию значения записи слота idx в JSON и ее параметры на момент now
+func (s *arenaShard) read(idx uint32, now int64) ([]byte, model.EntryGetData) {
+	slot := &s.slots[idx]
+	return bytes.Clone(s.value(idx)), model.EntryGetData{
+		Key:       string(s.key(idx)),
+		ExpiresAt: unixTime(slot.expiresAt),
+		Stale:     slot.expired(now),
+		Version:   slot.version,
+	}
+}
+
+// refreshSettings сообщает, пора ли на момент now обновить запись слота idx, и если пора,
+// возвращает параметры, с которыми обновленное значение нужно записать
+func (s *arenaShard) refreshSettings(idx uint32, now int64) (bool, model.EntryPutData) {
+	slot := &s.slots[idx]
+	if !slot.refreshDue(now) {
+		return false, model.EntryPutData{}
+	}
+	return true, model.EntryPutData{
+		Sliding:      slot.sliding,
+		StaleTTL:     slot.staleTTL,
+		RefreshAhead: slot.refreshAhead,
+		Tags:         decodeArenaTags(s.tags(idx)),
+	}
+}
+
+// clear удаляет все записи шарда
+func (s *arenaShard) clear() {
+	s.freeSlots = s.freeSlots[:0]
+	for i := len(s.slots) - 1; i > 0; i-- {
+		s.slots[i] = arenaSlot{}
+		s.freeSlots = append(s.freeSlots, uint32(i))
+	}
+	s.slots[0].prev, s.slots[0].next = 0, 0
+	clear(s.index)
+	s.slabs.reset()
+
+	s.root = 0
+	s.tagNodes = s.tagNodes[:1]
+	s.freeTagNodes = s.freeTagNodes[:0]
+	clear(s.tagIndex)
+	s.expiry = s.expiry[:0]
+}
+
+// access учитывает чтение записи слота idx. Выполняется и под блокировкой на чтение.
+func (s *arenaShard) access(idx uint32) {
+	if s.promote && !s.slots[idx].referenced.Load() {
+		s.slots[idx].referenced.Store(true)
+	}
+}
+
+// pushFront добавляет слот idx в начало кольца
+func (s *arenaShard) pushFront(idx uint32) {
+	head := &s.slots[0]
+	slot := &s.slots[idx]
+	slot.prev, slot.next = 0, head.next
+	s.slots[head.next].prev = idx
+	head.next = idx
+
+	s.stamp++
+	slot.stamp = s.stamp
+}
+
+// moveToFront переносит слот idx в начало кольца
+func (s *arenaShard) moveToFront(idx uint32) {
+	s.unlink(idx)
+	s.pushFront(idx)
+}
+
+// unlink убирает слот idx из кольца
+func (s *arenaShard) unlink(idx uint32) {
+	slot := &s.slots[idx]
+	s.slots[slot.prev].next = slot.next
+	s.slots[slot.next].prev = slot.prev
+	slot.prev, slot.next = 0, 0
+}
+
+// deleteDead удаляет не более budget записей, срок которых с учетом StaleTTL прошел к моменту now.
+// Записи берутся из кучи сроков удаления, поэтому проход не затрагивает записи, срок которых не прошел.
+func (s *arenaShard) deleteDead(budget int, now int64) {
+	for ; budget > 0 && len(s.expiry) > 0 && s.slots[s.expiry[0]].dead(now); budget-- {
+		s.remove(s.expiry[0])
+	}
+}
+
+// encodeArenaTags кодирует теги в последовательность длина-значение
+func encodeArenaTags(tags []string) []byte {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, tag := range tags {
+		buf = binary.AppendUvarint(buf, uint64(len(tag)))
+		buf = append(buf, tag...)
+	}
+	return buf
+}
+
+// decodeArenaTags декодирует теги, закодированные encodeArenaTags
+func decodeArenaTags(buf []byte) []string {
+	var tags []string
+	for len(buf) > 0 {
+		n, size := binary.Uvarint(buf)
+		buf = buf[size:]
+		tags = append(tags, string(buf[:n]))
+		buf = buf[n:]
+	}
+	return tags
+}
+
+// hasArenaTag сообщает, содержат ли закодированные теги тег tag
+func hasArenaTag(buf []byte, tag string) bool {
+	for len(buf) > 0 {
+		n, size := binary.Uvarint(buf)
+		buf = buf[size:]
+		if string(buf[:n]) == tag {
+			return true
+		}
+		buf = buf[n:]
+	}
+	return false
+}
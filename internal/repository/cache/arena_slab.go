@@ -0,0 +1,191 @@
+package cache
+
+import "sort"
+
+const (
+	arenaSlabSize    = 1 << 20 // Размер слаба в байтах, он же максимальный размер записи (ключ, значение и теги)
+	arenaMinChunk    = 64      // Размер чанка наименьшего класса
+	arenaChunkGrowth = 1.25    // Во сколько раз чанк следующего класса больше предыдущего
+	arenaChunkAlign  = 8       // Выравнивание размера чанка
+)
+
+// arenaClasses содержит размеры чанков классов по возрастанию. Последний класс занимает слаб целиком.
+var arenaClasses = func() []uint32 {
+	var classes []uint32
+	for size := float64(arenaMinChunk); size < arenaSlabSize/2; size *= arenaChunkGrowth {
+		chunk := (uint32(size) + arenaChunkAlign - 1) / arenaChunkAlign * arenaChunkAlign
+		if len(classes) == 0 || chunk > classes[len(classes)-1] {
+			classes = append(classes, chunk)
+		}
+	}
+	return append(classes, arenaSlabSize)
+}()
+
+// arenaClassFor возвращает наименьший класс, в чанк которого помещается запись размера size
+func arenaClassFor(size int) (class uint8, ok bool) {
+	i := sort.Search(len(arenaClasses), func(i int) bool { return int(arenaClasses[i]) >= size })
+	if i == len(arenaClasses) {
+		return 0, false
+	}
+	return uint8(i), true
+}
+
+// arenaChunk задает адрес чанка: номер слаба и смещение чанка в нем
+type arenaChunk struct {
+	slab   uint32
+	offset uint32
+}
+
+// arenaSlabs распределяет память шарда Arena. Память выделяется слабами по arenaSlabSize байт,
+// каждый слаб делится на чанки одного класса размера; запись занимает чанк наименьшего подходящего
+// класса. Слаб закрепляется за классом, пока в нем есть занятые чанки, а опустевший слаб может быть
+// отдан другому классу. Слабы - единственные указатели в структуре, остальное состояние - числа,
+// поэтому сборщику мусора почти нечего сканировать.
+//
+// Лимит памяти задается в байтах и ограничивает суммарный размер занятых чанков. Чтобы записи одного
+// класса не вытесняли записи других, пока лимит не исчерпан, слабов выделяется столько, сколько нужно
+// для лимита, и еще по одному на класс: выделенная память превышает лимит не больше чем на слаб для
+// каждого используемого класса.
+type arenaSlabs struct {
+	slabs    [][]byte       // слабы
+	class    []uint8        // класс, за которым закреплен слаб
+	live     []uint32       // количество занятых чанков слаба
+	free     [][]arenaChunk // свободные чанки по классам
+	maxSlabs int            // наибольшее количество слабов, 0 - без ограничения
+	maxUsed  int64          // лимит суммарного размера занятых чанков, 0 - без ограничения
+	used     int64          // суммарный размер занятых чанков
+}
+
+// newArenaSlabs создает распределитель памяти с лимитом maxBytes байт (0 - без ограничения)
+func newArenaSlabs(maxBytes int64) arenaSlabs {
+	return arenaSlabs{
+		free:     make([][]arenaChunk, len(arenaClasses)),
+		maxSlabs: maxArenaSlabs(maxBytes),
+		maxUsed:  maxBytes,
+	}
+}
+
+// maxArenaSlabs возвращает наибольшее количество слабов для лимита maxBytes байт (0 - без ограничения):
+// слабы, вмещающие лимит, и по неполному слабу на каждый класс
+func maxArenaSlabs(maxBytes int64) int {
+	if maxBytes == 0 {
+		return 0
+	}
+	return int((maxBytes+arenaSlabSize-1)/arenaSlabSize) + len(arenaClasses)
+}
+
+// fits сообщает, помещается ли чанк класса class в лимит памяти хотя бы в пустом шарде
+func (a *arenaSlabs) fits(class uint8) bool {
+	return a.maxUsed == 0 || int64(arenaClasses[class]) <= a.maxUsed
+}
+
+// full сообщает, что чанк класса class не помещается в лимит памяти, пока не освобождены другие чанки
+func (a *arenaSlabs) full(class uint8) bool {
+	return a.maxUsed > 0 && a.used+int64(arenaClasses[class]) > a.maxUsed
+}
+
+// alloc выделяет чанк класса class: свободный чанк класса, чанк опустевшего слаба другого класса
+// или нового слаба. Если лимит памяти исчерпан или у класса нет свободных чанков, а слабов больше
+// не выделить, возвращает false, и место нужно освободить вытеснением (см. arenaShard.alloc).
+func (a *arenaSlabs) alloc(class uint8) (arenaChunk, bool) {
+	if a.full(class) || len(a.free[class]) == 0 && !a.reassign(class) && !a.grow(class) {
+		return arenaChunk{}, false
+	}
+
+	free := a.free[class]
+	chunk := free[len(free)-1]
+	a.free[class] = free[:len(free)-1]
+
+	a.live[chunk.slab]++
+	a.used += int64(arenaClasses[class])
+	return chunk, true
+}
+
+// release освобождает чанк
+func (a *arenaSlabs) release(chunk arenaChunk) {
+	class := a.class[chunk.slab]
+	a.free[class] = append(a.free[class], chunk)
+	a.live[chunk.slab]--
+	a.used -= int64(arenaClasses[class])
+}
+
+// bytes возвращает первые n байт чанка
+func (a *arenaSlabs) bytes(chunk arenaChunk, n uint32) []byte {
+	return a.slabs[chunk.slab][chunk.offset : chunk.offset+n]
+}
+
+// grow выделяет новый слаб для класса class, если лимит слабов не достигнут
+func (a *arenaSlabs) grow(class uint8) bool {
+	if a.maxSlabs > 0 && len(a.slabs) >= a.maxSlabs {
+		return false
+	}
+
+	a.slabs = append(a.slabs, make([]byte, arenaSlabSize))
+	a.class = append(a.class, class)
+	a.live = append(a.live, 0)
+	a.carve(uint32(len(a.slabs)-1), class)
+	return true
+}
+
+// reassign отдает классу class опустевший слаб другого класса
+func (a *arenaSlabs) reassign(class uint8) bool {
+	for slab := range a.slabs {
+		if a.live[slab] != 0 || a.class[slab] == class {
+			continue
+		}
+
+		// Свободные чанки слаба убираются из списка прежнего класса
+		old := a.class[slab]
+		free := a.free[old][:0]
+		for _, chunk := range a.free[old] {
+			if chunk.slab != uint32(slab) {
+				free = append(free, chunk)
+			}
+		}
+		a.free[old] = free
+
+		a.class[slab] = class
+		a.carve(uint32(slab), class)
+		return true
+	}
+	return false
+}
+
+// donor выбирает слаб другого класса, который можно отдать классу class после вытеснения его записей:
+// слаб с наименьшим количеством занятых чанков. Возвращает false, если других классов слабы не заняты.
+func (a *arenaSlabs) donor(class uint8) (uint32, bool) {
+	best, found := uint32(0), false
+	for slab := range a.slabs {
+		if a.class[slab] == class || a.live[slab] == 0 {
+			continue
+		}
+		if !found || a.live[slab] < a.live[best] {
+			best, found = uint32(slab), true
+		}
+	}
+	return best, found
+}
+
+// carve делит слаб на свободные чанки класса class
+func (a *arenaSlabs) carve(slab uint32, class uint8) {
+	size := arenaClasses[class]
+	// Чанки добавляются с конца, чтобы выделялись в порядке смещений
+	for offset := arenaSlabSize/size*size - size; ; offset -= size {
+		a.free[class] = append(a.free[class], arenaChunk{slab: slab, offset: offset})
+		if offset == 0 {
+			return
+		}
+	}
+}
+
+// reset освобождает все чанки, сохраняя выделенные слабы
+func (a *arenaSlabs) reset() {
+	for class := range a.free {
+		a.free[class] = a.free[class][:0]
+	}
+	for slab := range a.slabs {
+		a.live[slab] = 0
+		a.carve(uint32(slab), a.class[slab])
+	}
+	a.used = 0
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// Snapshot запись содержимого кэша в w в том же формате, что и у LRU, поэтому снимок
+// одного движка хранения загружается другим. Возвращает количество записанных записей.
+func (a *Arena) Snapshot(ctx context.Context, w io.Writer) (n int, err error) {
+	return writeSnapshot(a, w)
+}
+
+// Restore загрузка содержимого кэша из снимка, записанного Snapshot. Записи, срок которых прошел
+// за время простоя, и записи, не помещающиеся в слаб или в лимит памяти шарда, пропускаются. Возвращает количество
+// восстановленных записей; для снимка неизвестного формата или версии возвращает ErrInvalidSnapshot.
+func (a *Arena) Restore(ctx context.Context, r io.Reader) (n int, err error) {
+	return readSnapshot(a, r)
+}
+
+// OpenLog включение журнала изменений cfg.Path (см. LRU.OpenLog)
+func (a *Arena) OpenLog(ctx context.Context, cfg model.OpLogConfig) (n int, err error) {
+	return openLog(a, &a.oplog, cfg)
+}
+
+// CompactLog переписывает журнал изменений из текущего содержимого кэша, если пора (см. LRU.CompactLog)
+func (a *Arena) CompactLog(ctx context.Context) (compacted bool, err error) {
+	return compactLogIfDue(a, a.oplog.Load())
+}
+
+// exportRecords выгрузка записей кэша для снимка и журнала изменений. Записи шарда копируются
+// под блокировкой на чтение, а fn вызывается уже после ее снятия.
+func (a *Arena) exportRecords(fn func(rec lru.Record[string, interface{}]) error) error {
+	if a.closed.Load() {
+		return def.ErrClosed
+	}
+
+	var entries []arenaEntry
+	for _, s := range a.shards {
+		entries = entries[:0]
+		s.mu.RLock()
+		now := time.Now().UnixNano()
+		for idx := s.slots[0].prev; idx != 0; idx = s.slots[idx].prev {
+			if !s.slots[idx].dead(now) {
+				entries = append(entries, s.entry(idx))
+			}
+		}
+		s.mu.RUnlock()
+
+		for _, ent := range entries {
+			value, err := decodeSnapshotValue(ent.value)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", ent.key, err)
+			}
+			err = fn(lru.Record[string, interface{}]{
+				Key:          ent.key,
+				Value:        value,
+				ExpiresAt:    unixTime(ent.expiresAt),
+				TTL:          ent.ttl,
+				Sliding:      ent.sliding,
+				StaleTTL:     ent.staleTTL,
+				RefreshAhead: ent.refreshAhead,
+				Tags:         decodeArenaTags(ent.tags),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importRecord загрузка записи из снимка или журнала изменений с сохраненными датой истечения
+// и параметрами. Для записи, срок которой уже прошел, возвращает ErrExpired.
+func (a *Arena) importRecord(rec lru.Record[string, interface{}]) error {
+	if a.closed.Load() {
+		return def.ErrClosed
+	}
+	if err := checkArenaSettings(rec.TTL, rec.StaleTTL, rec.RefreshAhead, rec.Tags); err != nil {
+		return err
+	}
+
+	var expiresAt int64
+	if !rec.ExpiresAt.IsZero() {
+		expiresAt = rec.ExpiresAt.UnixNano()
+		if time.Now().UnixNano() > expiresAt+int64(rec.StaleTTL) {
+			return def.ErrExpired
+		}
+	}
+
+	value, err := json.Marshal(rec.Value)
+	if err != nil {
+		return fmt.Errorf("key %q: %w", rec.Key, err)
+	}
+	ent := arenaEntry{
+		key:          rec.Key,
+		value:        value,
+		tags:         encodeArenaTags(uniqueTags(rec.Tags)),
+		expiresAt:    expiresAt,
+		ttl:          rec.TTL,
+		sliding:      rec.Sliding,
+		staleTTL:     rec.StaleTTL,
+		refreshAhead: rec.RefreshAhead,
+	}
+
+	hash, s := a.locate(rec.Key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx, err := s.store(ent, hash)
+	if err != nil {
+		return err
+	}
+	a.notifyPut(s, idx)
+	return nil
+}
+
+// evictKey удаление записи при применении журнала изменений
+func (a *Arena) evictKey(key string) error {
+	_, err := a.evict(key)
+	return err
+}
+
+// evictAll очистка кэша при применении журнала изменений
+func (a *Arena) evictAll() error {
+	return a.EvictAll(context.Background())
+}
+
+// snapshotRecord возвращает строку снимка с записью слота idx. Значение строки ссылается
+// на память слаба, поэтому строку нужно закодировать до снятия блокировки шарда.
+func (s *arenaShard) snapshotRecord(idx uint32) snapshotRecord {
+	slot := &s.slots[idx]
+	return snapshotRecord{
+		Key:          string(s.key(idx)),
+		Value:        s.value(idx),
+		ExpiresAt:    slot.expiresAt,
+		TTL:          slot.ttl,
+		Sliding:      slot.sliding,
+		StaleTTL:     slot.staleTTL,
+		RefreshAhead: slot.refreshAhead,
+		Tags:         decodeArenaTags(s.tags(idx)),
+	}
+}
@@ -0,0 +1,579 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// newTestArena создает Arena с параметрами opts, по умолчанию с одним шардом
+func newTestArena(t *testing.T, size int, opts ArenaOptions) *Arena {
+	t.Helper()
+
+	if opts.Shards == 0 {
+		opts.Shards = 1
+	}
+	opts.JanitorInterval, opts.JanitorBudget = time.Hour, 100
+	a, err := NewArena(size, time.Hour, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = a.Close(context.Background()) })
+	return a
+}
+
+// hasPointers сообщает, содержат ли значения типа typ указатели, которые должен обходить сборщик мусора
+func hasPointers(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Slice, reflect.String,
+		reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	case reflect.Array:
+		return typ.Len() > 0 && hasPointers(typ.Elem())
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			if hasPointers(typ.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkShard проверяет согласованность индексов шарда s
+func checkShard(t *testing.T, s *arenaShard) {
+	t.Helper()
+
+	// Кольцо и цепочки хэшей содержат все занятые слоты
+	ring := 0
+	for idx := s.slots[0].next; idx != 0; idx = s.slots[idx].next {
+		require.True(t, s.slots[idx].used)
+		require.Equal(t, idx, s.lookup(string(s.key(idx)), s.slots[idx].hash))
+		ring++
+	}
+	require.Equal(t, s.len(), ring)
+
+	// Дерево ключей содержит все записи по порядку ключей
+	var keys [][]byte
+	s.ascend(nil, func(idx uint32) bool {
+		keys = append(keys, s.key(idx))
+		return true
+	})
+	require.Len(t, keys, s.len())
+	for i := 1; i < len(keys); i++ {
+		require.Negative(t, bytes.Compare(keys[i-1], keys[i]))
+	}
+
+	// Куча упорядочена по сроку удаления и содержит все истекающие записи
+	expiring := 0
+	for idx := range s.slots[1:] {
+		if slot := &s.slots[idx+1]; slot.used && slot.expiresAt != 0 {
+			expiring++
+			require.Equal(t, uint32(idx+1), s.expiry[slot.heapPos-1])
+		}
+	}
+	require.Len(t, s.expiry, expiring)
+	for i := 1; i < len(s.expiry); i++ {
+		require.False(t, s.heapLess(i, (i-1)/2))
+	}
+
+	// Узлов тегов столько же, сколько тегов у записей
+	tags, used := 0, len(s.tagNodes)-1-len(s.freeTagNodes)
+	for idx := s.slots[0].next; idx != 0; idx = s.slots[idx].next {
+		tags += len(decodeArenaTags(s.tags(idx)))
+	}
+	require.Equal(t, tags, used)
+}
+
+func TestArena_NoPointers(t *testing.T) {
+	// Записи кэша хранятся в слотах и индексах шарда, поэтому ни они, ни элементы индексов
+	// не должны содержать указателей
+	s := newArenaShard(1, 0, false, maphash.MakeSeed())
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(arenaSlot{}),
+		reflect.TypeOf(arenaChunk{}),
+		reflect.TypeOf(arenaTagNode{}),
+		reflect.TypeOf(s.slots).Elem(),
+		reflect.TypeOf(s.freeSlots).Elem(),
+		reflect.TypeOf(s.index).Key(),
+		reflect.TypeOf(s.index).Elem(),
+		reflect.TypeOf(s.tagNodes).Elem(),
+		reflect.TypeOf(s.freeTagNodes).Elem(),
+		reflect.TypeOf(s.tagIndex).Key(),
+		reflect.TypeOf(s.tagIndex).Elem(),
+		reflect.TypeOf(s.expiry).Elem(),
+		reflect.TypeOf(s.seed),
+		reflect.TypeOf(s.slabs.class).Elem(),
+		reflect.TypeOf(s.slabs.live).Elem(),
+		reflect.TypeOf(s.slabs.free).Elem().Elem(),
+	} {
+		assert.False(t, hasPointers(typ), typ.String())
+	}
+	assert.True(t, hasPointers(reflect.TypeOf(arenaEntry{})))
+}
+
+func TestArena_PutGetEvict(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 10, ArenaOptions{Shards: 2})
+
+	v1, err := a.Put(ctx, model.EntryPutData{Key: "a", Value: map[string]interface{}{"x": 1.5}})
+	require.NoError(t, err)
+	v2, err := a.Put(ctx, model.EntryPutData{Key: "a", Value: int64(7)})
+	require.NoError(t, err)
+	assert.Greater(t, v2, v1)
+
+	entry, err := a.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), entry.Value)
+	assert.Equal(t, v2, entry.Version)
+
+	_, err = a.Get(ctx, "b")
+	assert.ErrorIs(t, err, def.ErrNotFound)
+
+	value, err := a.Evict(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), value)
+	_, err = a.Evict(ctx, "a")
+	assert.ErrorIs(t, err, def.ErrNotFound)
+
+	_, err = a.Put(ctx, model.EntryPutData{Key: "short", Value: "x", TTL: time.Millisecond})
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = a.Get(ctx, "short")
+	assert.ErrorIs(t, err, def.ErrExpired)
+}
+
+func TestArena_LRUOrder(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 3, ArenaOptions{PromoteOnRead: true})
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := a.Put(ctx, model.EntryPutData{Key: key, Value: key})
+		require.NoError(t, err)
+	}
+	// Прочитанная запись получает второй шанс и вытесняется следующая за ней
+	_, err := a.Get(ctx, "a")
+	require.NoError(t, err)
+	_, err = a.Put(ctx, model.EntryPutData{Key: "d", Value: "d"})
+	require.NoError(t, err)
+
+	_, err = a.Get(ctx, "b")
+	assert.ErrorIs(t, err, def.ErrNotFound)
+	assert.Equal(t, []string{"c", "a", "d"}, exportKeys(t, a))
+}
+
+func TestArena_IncrOverflow(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 10, ArenaOptions{})
+
+	_, err := a.Put(ctx, model.EntryPutData{Key: "i", Value: int64(math.MaxInt64)})
+	require.NoError(t, err)
+	_, err = a.Incr(ctx, "i", 1, 0)
+	assert.ErrorIs(t, err, def.ErrOverflow)
+
+	_, err = a.Put(ctx, model.EntryPutData{Key: "f", Value: 1.7e308})
+	require.NoError(t, err)
+	_, err = a.Incr(ctx, "f", 1.7e308, 0)
+	assert.ErrorIs(t, err, def.ErrOverflow)
+
+	entry, err := a.Get(ctx, "f")
+	require.NoError(t, err)
+	assert.Equal(t, 1.7e308, entry.Value)
+}
+
+func TestArenaShard_HashCollisions(t *testing.T) {
+	s := newArenaShard(4, 0, false, maphash.MakeSeed())
+
+	// Все ключи получают один хэш
+	const hash = 42
+	for i, key := range []string{"a", "b", "c"} {
+		_, err := s.store(arenaEntry{key: key, value: []byte(fmt.Sprint(i))}, hash)
+		require.NoError(t, err)
+	}
+	assert.Len(t, s.index, 1)
+	for i, key := range []string{"a", "b", "c"} {
+		idx := s.lookup(key, hash)
+		require.NotZero(t, idx, key)
+		assert.Equal(t, fmt.Sprint(i), string(s.value(idx)), key)
+	}
+	assert.Zero(t, s.lookup("d", hash))
+
+	// Удаление из середины цепочки не затрагивает остальные ключи
+	s.remove(s.lookup("b", hash))
+	assert.Zero(t, s.lookup("b", hash))
+	assert.NotZero(t, s.lookup("a", hash))
+	assert.NotZero(t, s.lookup("c", hash))
+
+	// Перезапись ключа не задевает другой ключ с тем же хэшем
+	_, err := s.store(arenaEntry{key: "a", value: []byte(strings.Repeat("x", 200))}, hash)
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(s.value(s.lookup("c", hash))))
+	assert.Equal(t, 2, s.len())
+	checkShard(t, s)
+
+	s.remove(s.lookup("a", hash))
+	s.remove(s.lookup("c", hash))
+	assert.Empty(t, s.index)
+}
+
+func TestArena_MaxBytes(t *testing.T) {
+	ctx := context.Background()
+	const maxBytes = 1000
+	a := newTestArena(t, 1000, ArenaOptions{Shards: 3, MaxBytes: maxBytes})
+
+	// Лимит делится между шардами без округления
+	var total int64
+	for _, s := range a.shards {
+		total += s.slabs.maxUsed
+	}
+	assert.Equal(t, int64(maxBytes), total)
+
+	for i := range 200 {
+		_, err := a.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("key-%03d", i), Value: i})
+		require.NoError(t, err)
+
+		stats, err := a.Stats(ctx)
+		require.NoError(t, err)
+		require.LessOrEqual(t, stats.Bytes, int64(maxBytes))
+	}
+	stats, err := a.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxBytes), stats.MaxBytes)
+	assert.Positive(t, stats.Len)
+	assert.Less(t, stats.Len, 200)
+
+	// Запись, чанк которой больше лимита шарда, отклоняется, хотя и помещается в слаб
+	_, err = a.Put(ctx, model.EntryPutData{Key: "big", Value: strings.Repeat("x", 400)})
+	assert.ErrorIs(t, err, def.ErrTooLarge)
+	_, err = a.Put(ctx, model.EntryPutData{Key: "huge", Value: strings.Repeat("x", arenaSlabSize)})
+	assert.ErrorIs(t, err, def.ErrTooLarge)
+}
+
+func TestArena_MixedSizes(t *testing.T) {
+	ctx := context.Background()
+	const maxBytes = 512 * 1024
+	a := newTestArena(t, 100000, ArenaOptions{Shards: 1, MaxBytes: maxBytes})
+
+	// Запись нового класса размера при далеком от исчерпания лимите ничего не вытесняет
+	for i := range 100 {
+		_, err := a.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("k%03d", i), Value: i})
+		require.NoError(t, err)
+	}
+	_, err := a.Put(ctx, model.EntryPutData{Key: "mid", Value: strings.Repeat("x", 200)})
+	require.NoError(t, err)
+	stats, err := a.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 101, stats.Len)
+
+	// Записи разных размеров вперемешку: лимит соблюдается побайтно, а заполнение не падает
+	// из-за перераспределения слабов между классами
+	sizes := []int{10, 100, 300, 1000, 3000, 10000}
+	for i := range 5000 {
+		size := sizes[(i*7+i/3)%len(sizes)]
+		_, err := a.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("m%05d", i), Value: strings.Repeat("x", size)})
+		require.NoError(t, err)
+
+		stats, err := a.Stats(ctx)
+		require.NoError(t, err)
+		require.LessOrEqual(t, stats.Bytes, int64(maxBytes))
+		if i >= 1000 {
+			require.Greater(t, stats.Bytes, int64(maxBytes/2), "put %d", i)
+		}
+	}
+	s := a.shards[0]
+	assert.LessOrEqual(t, len(s.slabs.slabs), maxArenaSlabs(maxBytes))
+	checkShard(t, s)
+}
+
+func TestArena_SlabRebalancing(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 40000, ArenaOptions{MaxBytes: 4 * arenaSlabSize})
+	s := a.shards[0]
+	// Слабов не больше двух, чтобы новому классу не хватило слаба
+	s.slabs.maxSlabs = 2
+
+	// Мелкие записи занимают оба слаба чанками наименьшего класса
+	perSlab := arenaSlabSize / arenaMinChunk
+	n := perSlab + perSlab/4
+	for i := range n {
+		_, err := a.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("k%05d", i), Value: i})
+		require.NoError(t, err)
+	}
+	require.Len(t, s.slabs.slabs, 2)
+	require.Equal(t, []uint32{uint32(perSlab), uint32(perSlab / 4)}, s.slabs.live)
+
+	// Крупной записи отдается менее занятый слаб, а вытесняются только его записи
+	_, err := a.Put(ctx, model.EntryPutData{Key: "big", Value: strings.Repeat("x", 100_000)})
+	require.NoError(t, err)
+	assert.Equal(t, perSlab+1, s.len())
+	assert.NotEqual(t, s.slabs.class[0], s.slabs.class[1])
+
+	_, err = a.Get(ctx, "k00000")
+	assert.NoError(t, err)
+	_, err = a.Get(ctx, fmt.Sprintf("k%05d", n-1))
+	assert.ErrorIs(t, err, def.ErrNotFound)
+	checkShard(t, s)
+
+	// Когда у класса есть записи, место освобождают его давние записи, а не слабы других классов
+	for i := range 30 {
+		_, err := a.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("big%02d", i), Value: strings.Repeat("x", 100_000)})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint32(perSlab), s.slabs.live[0])
+	_, err = a.Get(ctx, "big")
+	assert.ErrorIs(t, err, def.ErrNotFound)
+	_, err = a.Get(ctx, "big29")
+	assert.NoError(t, err)
+	assert.Len(t, s.slabs.slabs, 2)
+	checkShard(t, s)
+}
+
+func TestArena_TxnAtomic(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 10, ArenaOptions{MaxBytes: 500})
+
+	_, err := a.Put(ctx, model.EntryPutData{Key: "a", Value: int64(1)})
+	require.NoError(t, err)
+	before, err := a.Get(ctx, "a")
+	require.NoError(t, err)
+
+	// Запись, не помещающаяся в лимит памяти шарда, отменяет всю транзакцию до применения
+	_, err = a.Txn(ctx, model.TxnData{Ops: []model.TxnOp{
+		{Op: model.TxnOpIncr, Data: model.EntryPutData{Key: "a"}, Delta: int64(1)},
+		{Op: model.TxnOpPut, Data: model.EntryPutData{Key: "b", Value: strings.Repeat("x", 600)}},
+	}})
+	var txnErr *def.TxnError
+	require.ErrorAs(t, err, &txnErr)
+	assert.Equal(t, 1, txnErr.Op)
+	assert.ErrorIs(t, err, def.ErrTooLarge)
+
+	after, err := a.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	_, err = a.Get(ctx, "b")
+	assert.ErrorIs(t, err, def.ErrNotFound)
+}
+
+func TestArena_TxnRollback(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 10, ArenaOptions{})
+
+	for _, key := range []string{"a", "b"} {
+		_, err := a.Put(ctx, model.EntryPutData{Key: key, Value: key, Tags: []string{"t"}})
+		require.NoError(t, err)
+	}
+	before := contents(t, a)
+	versionA, err := a.Get(ctx, "a")
+	require.NoError(t, err)
+
+	// Транзакция, запись которой не удалась, откатывает уже примененные операции
+	s := a.shards[0]
+	undo := arenaTxnUndo{saved: make(map[string]struct{})}
+	for _, key := range []string{"a", "b", "c"} {
+		hash, _ := a.locate(key)
+		undo.save(s, key, hash)
+	}
+	hashA, _ := a.locate("a")
+	hashB, _ := a.locate("b")
+	hashC, _ := a.locate("c")
+	_, err = s.store(arenaEntry{key: "a", value: []byte(`"changed"`)}, hashA)
+	require.NoError(t, err)
+	s.remove(s.lookup("b", hashB))
+	_, err = s.store(arenaEntry{key: "c", value: []byte(`"new"`)}, hashC)
+	require.NoError(t, err)
+	require.NoError(t, undo.rollback(a))
+
+	assert.Equal(t, before, contents(t, a))
+	after, err := a.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, versionA.Version, after.Version)
+	n, err := a.EvictByTag(ctx, "t")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	checkShard(t, s)
+}
+
+func TestArena_TxnRollbackFailure(t *testing.T) {
+	ctx := context.Background()
+	cfg := model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: model.FsyncNever}
+	a := newTestArena(t, 10, ArenaOptions{MaxBytes: 1000})
+	_, err := a.OpenLog(ctx, cfg)
+	require.NoError(t, err)
+
+	_, err = a.Put(ctx, model.EntryPutData{Key: "a", Value: strings.Repeat("x", 400)})
+	require.NoError(t, err)
+
+	s := a.shards[0]
+	hash, _ := a.locate("a")
+	undo := arenaTxnUndo{saved: make(map[string]struct{})}
+	undo.save(s, "a", hash)
+	s.remove(s.lookup("a", hash))
+
+	// Прежняя запись больше не помещается в шард: откат сообщает об ошибке,
+	// а журнал изменений, как и кэш, теряет запись
+	s.slabs.maxUsed = 100
+	err = undo.rollback(a)
+	assert.ErrorIs(t, err, def.ErrTooLarge)
+	assert.Zero(t, s.lookup("a", hash))
+	s.slabs.maxUsed = 1000
+	require.NoError(t, a.Close(ctx))
+
+	restored := newTestArena(t, 10, ArenaOptions{MaxBytes: 1000})
+	_, err = restored.OpenLog(ctx, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, contents(t, restored))
+}
+
+func TestArena_PrefixAndTags(t *testing.T) {
+	ctx := context.Background()
+	a := newTestArena(t, 100, ArenaOptions{Shards: 3})
+
+	for i := range 20 {
+		tags := []string{fmt.Sprintf("mod%d", i%3)}
+		if i%2 == 0 {
+			tags = append(tags, "even")
+		}
+		_, err := a.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("user:%02d", i), Value: int64(i), Tags: tags})
+		require.NoError(t, err)
+	}
+	_, err := a.Put(ctx, model.EntryPutData{Key: "user", Value: "no colon"})
+	require.NoError(t, err)
+	_, err = a.Put(ctx, model.EntryPutData{Key: "users", Value: "after"})
+	require.NoError(t, err)
+
+	keys, values, err := a.ScanPrefix(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:10", "user:11", "user:12", "user:13", "user:14", "user:15", "user:16", "user:17", "user:18", "user:19"}, keys)
+	assert.Equal(t, int64(10), values[0])
+
+	keys, _, err = a.ScanPrefix(ctx, "user")
+	require.NoError(t, err)
+	assert.Len(t, keys, 22)
+	assert.Equal(t, "user", keys[0])
+	assert.Equal(t, "users", keys[21])
+
+	// Перезапись без тегов убирает запись из индекса тегов
+	_, err = a.Put(ctx, model.EntryPutData{Key: "user:00", Value: int64(0)})
+	require.NoError(t, err)
+
+	n, err := a.EvictByTag(ctx, "even")
+	require.NoError(t, err)
+	assert.Equal(t, 9, n)
+	n, err = a.EvictByTag(ctx, "even")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	// Четные записи с тегом mod1 уже удалены
+	n, err = a.EvictByTag(ctx, "mod1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	_, err = a.EvictByTag(ctx, "")
+	assert.ErrorIs(t, err, lru.ErrInvalidOption)
+
+	n, err = a.EvictPrefix(ctx, "user:")
+	require.NoError(t, err)
+	assert.Equal(t, 7, n)
+	keys, _, err = a.GetAll(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user", "users"}, keys)
+	for _, s := range a.shards {
+		checkShard(t, s)
+	}
+}
+
+func TestArenaShard_DeleteDead(t *testing.T) {
+	s := newArenaShard(10, 0, false, maphash.MakeSeed())
+	seed := maphash.MakeSeed()
+	now := time.Now().UnixNano()
+
+	entries := []arenaEntry{
+		{key: "dead1", expiresAt: now - 3},
+		{key: "forever"},
+		{key: "dead2", expiresAt: now - 2},
+		{key: "stale", expiresAt: now - 1, staleTTL: time.Hour},
+		{key: "live", expiresAt: now + int64(time.Hour)},
+	}
+	for _, ent := range entries {
+		ent.value = []byte("1")
+		_, err := s.store(ent, maphash.String(seed, ent.key))
+		require.NoError(t, err)
+	}
+	checkShard(t, s)
+
+	// Удаления идут в порядке сроков и ограничены budget
+	s.deleteDead(1, now)
+	assert.Zero(t, s.lookup("dead1", maphash.String(seed, "dead1")))
+	assert.NotZero(t, s.lookup("dead2", maphash.String(seed, "dead2")))
+
+	s.deleteDead(10, now)
+	assert.Equal(t, 3, s.len())
+	assert.Len(t, s.expiry, 2)
+	checkShard(t, s)
+
+	// Продление срока переставляет запись в куче
+	idx := s.lookup("stale", maphash.String(seed, "stale"))
+	s.slots[idx].expiresAt = now + 2*int64(time.Hour)
+	s.schedule(idx)
+	assert.Equal(t, s.lookup("live", maphash.String(seed, "live")), s.expiry[0])
+	checkShard(t, s)
+}
+
+func TestArenaShard_IndexesConsistent(t *testing.T) {
+	s := newArenaShard(50, 8*1024, false, maphash.MakeSeed())
+	rnd := rand.New(rand.NewPCG(1, 2))
+	now := time.Now().UnixNano()
+
+	for i := range 5000 {
+		key := fmt.Sprintf("k%03d", rnd.IntN(120))
+		// Хэши из небольшого диапазона, чтобы цепочки были длинными
+		hash := uint64(key[len(key)-1] % 4)
+
+		switch rnd.IntN(4) {
+		case 0:
+			if idx := s.lookup(key, hash); idx != 0 {
+				s.remove(idx)
+			}
+		default:
+			var tags []string
+			for range rnd.IntN(3) {
+				tags = append(tags, fmt.Sprintf("t%d", rnd.IntN(5)))
+			}
+			ent := arenaEntry{
+				key:   key,
+				value: bytes.Repeat([]byte("x"), rnd.IntN(400)),
+				tags:  encodeArenaTags(uniqueTags(tags)),
+			}
+			if rnd.IntN(2) == 0 {
+				ent.expiresAt = now + int64(rnd.IntN(1000)-500)
+			}
+			_, err := s.store(ent, hash)
+			require.NoError(t, err)
+		}
+
+		if i%100 == 0 {
+			s.deleteDead(3, now)
+		}
+		if i%250 == 0 {
+			checkShard(t, s)
+			require.LessOrEqual(t, s.slabs.used, int64(8*1024))
+		}
+	}
+	checkShard(t, s)
+
+	s.clear()
+	checkShard(t, s)
+	assert.Zero(t, s.len())
+	assert.Empty(t, s.tagIndex)
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// MGet получение данных из кэша по нескольким ключам. Каждый шард блокируется один раз.
+// Результаты располагаются на позициях соответствующих ключей, для промахов содержат ErrNotFound или ErrExpired.
+func (c *LRU) MGet(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	items, errs := c.cache.MGet(keys)
+	if closedBatch(errs) {
+		return nil, lru.ErrClosed
+	}
+
+	results := make([]model.EntryResult, len(keys))
+	for i, key := range keys {
+		results[i].Entry.Key = key
+		if errs[i] != nil {
+			results[i].Err = errs[i]
+			continue
+		}
+		results[i].Entry.Value = items[i].Value
+		results[i].Entry.ExpiresAt = items[i].ExpiresAt
+		results[i].Entry.Stale = items[i].Stale
+		results[i].Entry.Version = items[i].Version
+	}
+
+	return results, nil
+}
+
+// MPut запись нескольких значений в кэш. Каждый шард блокируется один раз.
+// Результаты содержат версии и даты истечения записанных записей или ошибки записи (например, ErrTooLarge).
+func (c *LRU) MPut(ctx context.Context, data []model.EntryPutData) ([]model.EntryResult, error) {
+	defer c.awaitLog()
+
+	entries := make([]lru.PutEntry[string, interface{}], len(data))
+	for i, d := range data {
+		entries[i] = lru.PutEntry[string, interface{}]{
+			Key:     d.Key,
+			Value:   d.Value,
+			TTL:     d.TTL,
+			Options: putOptions(d),
+		}
+	}
+
+	items, errs := c.cache.MPut(entries)
+	if closedBatch(errs) {
+		return nil, lru.ErrClosed
+	}
+
+	results := make([]model.EntryResult, len(data))
+	for i, d := range data {
+		results[i].Entry.Key = d.Key
+		if errs[i] != nil {
+			results[i].Err = errs[i]
+			continue
+		}
+		results[i].Entry.ExpiresAt = items[i].ExpiresAt
+		results[i].Entry.Version = items[i].Version
+	}
+
+	return results, nil
+}
+
+// MDelete удаление данных по нескольким ключам. Каждый шард блокируется один раз.
+// Результаты содержат удаленные значения или ErrNotFound, ErrExpired.
+func (c *LRU) MDelete(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	defer c.awaitLog()
+
+	values, errs := c.cache.MEvict(keys)
+	if closedBatch(errs) {
+		return nil, lru.ErrClosed
+	}
+
+	results := make([]model.EntryResult, len(keys))
+	for i, key := range keys {
+		results[i].Entry.Key = key
+		results[i].Entry.Value = values[i]
+		results[i].Err = errs[i]
+	}
+
+	return results, nil
+}
+
+// closedBatch сообщает, что пакетная операция не выполнена, так как кэш закрыт.
+// Закрытие проверяется один раз на пакет, поэтому достаточно проверить первую ошибку.
+func closedBatch(errs []error) bool {
+	return len(errs) > 0 && errors.Is(errs[0], lru.ErrClosed)
+}
@@ -1,209 +1,209 @@
-// package cache
+// Package cache содержит имплементации хранилища сервиса: LRU поверх встраиваемого кэша из pkg/lru
+// и Arena, хранящую сериализованные значения в слабах вне кучи объектов
 package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/rs/zerolog/log"
-
+	"github.com/vitbogit/golang-cache-lru/internal/model"
 	def "github.com/vitbogit/golang-cache-lru/internal/repository"
-	"github.com/vitbogit/golang-cache-lru/internal/repository/cache/list"
-)
-
-const (
-	defaultEvicterFrequency = 50 * time.Nanosecond
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
 )
 
 var _ def.ILRUCache = (*LRU)(nil)
 
-// LRU имплементирует потокобезопасный LRU-кэш с поддержкой TTL
+// LRU имплементирует потокобезопасный LRU-кэш с поддержкой TTL.
+// Является тонким адаптером над lru.Cache со строковыми ключами и произвольными значениями.
 type LRU struct {
-	size      int
-	evictList *list.LruList
-	items     map[string]*list.Entry
-
-	mu         sync.Mutex
-	defaultTTL time.Duration
-	done       chan struct{}
+	cache *lru.Cache[string, interface{}]
+	oplog atomic.Pointer[opLog] // журнал изменений, nil - не включен (см. OpenLog)
 }
 
-// NewCache создает новый кэш
-func NewCache(size int, defaultTTL time.Duration) *LRU {
-	if size <= 0 {
-		log.Fatal().Msg("cache size can not be zero")
-	}
+// NewCache создает новый кэш размера size с TTL по умолчанию defaultTTL и индексом ключей для поиска по префиксу.
+// Дополнительные параметры (шардирование, фоновая очистка и т.д.) передаются опциями pkg/lru.
+func NewCache(size int, defaultTTL time.Duration, opts ...lru.Option) (*LRU, error) {
 	if defaultTTL <= 0 {
-		log.Fatal().Msg("default TTL for cache can not be zero")
+		return nil, fmt.Errorf("%w: default TTL for cache must be > 0", lru.ErrInvalidOption)
 	}
 
-	res := LRU{
-		size:       size,
-		evictList:  list.NewList(),
-		items:      make(map[string]*list.Entry),
-		defaultTTL: defaultTTL,
-		done:       make(chan struct{}),
+	c := &LRU{}
+	cache, err := lru.New[string, interface{}](size, append([]lru.Option{
+		lru.WithDefaultTTL(defaultTTL),
+		lru.WithPrefixIndex(),
+		lru.WithObserver(c.observe),
+	}, opts...)...)
+	if err != nil {
+		return nil, err
 	}
 
-	// Тикер, который будет раз в defaultEvicterFrequency времени
-	// запускать deleteExpired() для удаления старых элементов
-	go func(done <-chan struct{}) {
-		ticker := time.NewTicker(defaultEvicterFrequency)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				res.deleteExpired()
-			}
-		}
-	}(res.done)
-
-	return &res
+	c.cache = cache
+	return c, nil
 }
 
 // EvictAll ручная инвалидация всего кэша
 func (c *LRU) EvictAll(ctx context.Context) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Очистка мапы значений
-	for k := range c.items {
-		delete(c.items, k)
-	}
+	defer c.awaitLog()
 
-	// Очистка двухсвязного списка
-	c.evictList.Init()
-
-	return nil
+	return c.cache.EvictAll()
 }
 
-// Put запись данных в кэш
-func (c *LRU) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	if len(key) == 0 || ttl < 0 {
-		return fmt.Errorf("некорректные входные данные")
-	}
-
-	if ttl == 0 {
-		ttl = c.defaultTTL
+// Stats получение текущего заполнения кэша
+func (c *LRU) Stats(ctx context.Context) (model.CacheStats, error) {
+	stats, err := c.cache.Stats()
+	if err != nil {
+		return model.CacheStats{}, err
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return model.CacheStats{
+		Len:      stats.Len,
+		Size:     stats.Size,
+		Bytes:    stats.Cost,
+		MaxBytes: stats.MaxCost,
+		Admitted: stats.Admitted,
+		Rejected: stats.Rejected,
+	}, nil
+}
 
-	now := time.Now()
+// Close останавливает фоновую очистку кэша, после чего операции над ним возвращают ErrClosed.
+// Журнал изменений, если он включен, сбрасывается на диск и закрывается.
+func (c *LRU) Close(ctx context.Context) error {
+	return errors.Join(c.cache.Close(ctx), c.oplog.Load().closeIfOpen())
+}
 
-	// Перезапись существующего элемента
-	if ent, ok := c.items[key]; ok {
-		c.evictList.MoveToFront(ent)
-		ent.Value = value
-		ent.ExpiresAt = now.Add(ttl)
-		return nil
-	}
+// Put запись данных в кэш. Возвращает версию записанной записи.
+func (c *LRU) Put(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	defer c.awaitLog()
 
-	// Добавление в список
-	ent := c.evictList.PushFront(key, value, now.Add(ttl)) // может "переполнить" список
-	if c.evictList.Length() > c.size {                     // удаление лишнего элемента сзади
-		c.removeOldest()
-	}
+	item, err := c.cache.PutItem(data.Key, data.Value, data.TTL, putOptions(data)...)
+	return item.Version, err
+}
 
-	// Добавление в мапу
-	c.items[key] = ent
+// CompareAndSwap запись данных в кэш, только если текущая версия записи равна expectedVersion.
+// Возвращает версию записанной записи или ErrVersionMismatch, ErrNotFound, ErrExpired.
+func (c *LRU) CompareAndSwap(ctx context.Context, data model.EntryPutData, expectedVersion uint64) (version uint64, err error) {
+	defer c.awaitLog()
 
-	return nil
+	item, err := c.cache.CompareAndSwap(data.Key, expectedVersion, data.Value, data.TTL, putOptions(data)...)
+	return item.Version, err
 }
 
-// Get получение данных из кэша по ключу
-func (c *LRU) Get(ctx context.Context, key string) (value interface{}, expiresAt time.Time, err error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// PutIfAbsent запись данных в кэш, только если записи с таким ключом нет (или она истекла).
+// Возвращает версию записанной записи или ErrExists.
+func (c *LRU) PutIfAbsent(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	defer c.awaitLog()
 
-	if ent, ok := c.items[key]; ok {
-		// Дополнительная проверка на expired
-		if time.Now().After(ent.ExpiresAt) {
-			// возвращаем nil error для not found
-			return nil, time.Time{}, nil
-		}
+	item, err := c.cache.PutIfAbsent(data.Key, data.Value, data.TTL, putOptions(data)...)
+	return item.Version, err
+}
 
-		// Успешно найдено
-		return ent.Value, ent.ExpiresAt, nil
-	}
+// Replace запись данных в кэш, только если запись с таким ключом уже есть.
+// Возвращает версию записанной записи или ErrNotFound, ErrExpired.
+func (c *LRU) Replace(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	defer c.awaitLog()
 
-	// возвращаем nil error для not found
-	return nil, time.Time{}, nil
+	item, err := c.cache.Replace(data.Key, data.Value, data.TTL, putOptions(data)...)
+	return item.Version, err
 }
 
-// Evict ручное удаление данных по ключу
-func (c *LRU) Evict(ctx context.Context, key string) (value interface{}, err error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// putOptions возвращает опции pkg/lru, соответствующие параметрам записи
+func putOptions(data model.EntryPutData) []lru.PutOption {
+	var opts []lru.PutOption
+	if data.Sliding {
+		opts = append(opts, lru.Sliding())
+	}
+	if data.StaleTTL > 0 {
+		opts = append(opts, lru.StaleTTL(data.StaleTTL))
+	}
+	if data.RefreshAhead > 0 {
+		opts = append(opts, lru.RefreshAhead(data.RefreshAhead))
+	}
+	if len(data.Tags) > 0 {
+		opts = append(opts, lru.Tags(data.Tags...))
+	}
+	return opts
+}
 
-	if ent, ok := c.items[key]; ok {
-		c.removeElement(ent)
-		return ent.Value, nil
+// Get получение данных из кэша по ключу. Для отсутствующих записей возвращает ErrNotFound,
+// для истекших - ErrExpired.
+func (c *LRU) Get(ctx context.Context, key string) (model.EntryGetData, error) {
+	item, err := c.cache.GetItem(key)
+	if err != nil {
+		return model.EntryGetData{}, err
 	}
 
-	return nil, nil
+	return model.EntryGetData{
+		Key:       key,
+		Value:     item.Value,
+		ExpiresAt: item.ExpiresAt,
+		Stale:     item.Stale,
+		Version:   item.Version,
+	}, nil
 }
 
-// GetAll получение всего наполнения кэша в виде двух слайсов: слайса ключей и слайса значений.
-// Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
-func (c *LRU) GetAll(ctx context.Context) (keys []string, values []interface{}, err error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	keys = make([]string, 0, len(c.items))
-	values = make([]interface{}, 0, len(c.items))
+// GetOrLoad получение данных из кэша по ключу, а при промахе - загрузка через loader и запись в кэш.
+// Если загруженное значение отклонено фильтром допуска, оно возвращается вместе с ErrRejected.
+func (c *LRU) GetOrLoad(ctx context.Context, key string, loader def.Loader) (model.EntryGetData, error) {
+	defer c.awaitLog()
 
-	now := time.Now()
+	value, expiresAt, err := c.cache.GetOrLoad(ctx, key, lru.Loader[string, interface{}](loader))
+	if errors.Is(err, lru.ErrRejected) {
+		// Загруженное значение не записано в кэш, но отдается вызывающему
+		return model.EntryGetData{Key: key, Value: value}, err
+	}
+	if err != nil {
+		return model.EntryGetData{}, err
+	}
 
-	for ent := c.evictList.Back(); ent != nil; ent = ent.PrevEntry() {
-		// Дополнительная проверка на expired
-		if now.After(ent.ExpiresAt) {
-			continue
-		}
+	return model.EntryGetData{
+		Key:       key,
+		Value:     value,
+		ExpiresAt: expiresAt,
+	}, nil
+}
 
-		keys = append(keys, ent.Key)
-		values = append(values, ent.Value)
+// SetLoader регистрация загрузчика для фонового обновления записей (refresh-ahead и stale_ttl)
+func (c *LRU) SetLoader(loader def.Loader) {
+	if loader == nil {
+		c.cache.SetLoader(nil)
+		return
 	}
-	return keys, values, nil
+	c.cache.SetLoader(lru.Loader[string, interface{}](loader))
 }
 
-// removeOldest удаляет старейший элемент. Подразумевается, что уже вызван lock.
-func (c *LRU) removeOldest() {
-	if ent := c.evictList.Back(); ent != nil {
-		c.removeElement(ent)
-	}
+// Evict ручное удаление данных по ключу. Для отсутствующих записей возвращает ErrNotFound,
+// для истекших - ErrExpired.
+func (c *LRU) Evict(ctx context.Context, key string) (value interface{}, err error) {
+	defer c.awaitLog()
+
+	return c.cache.Evict(key)
 }
 
-// removeElement удаляет указанный элемент. Подразумевается, что уже вызван lock.
-func (c *LRU) removeElement(e *list.Entry) {
-	c.evictList.Remove(e)  // удаление из списка
-	delete(c.items, e.Key) // удаление из мапы
+// ScanPrefix получение записей, ключи которых начинаются с prefix, в виде двух слайсов, как в GetAll.
+// Ключи упорядочены лексикографически.
+func (c *LRU) ScanPrefix(ctx context.Context, prefix string) (keys []string, values []interface{}, err error) {
+	return c.cache.ScanPrefix(prefix)
 }
 
-// deleteExpired вызывается каждые defaultEvicterFrequency времени специальной горутиной
-// для удаления expired элементов
-func (c *LRU) deleteExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// EvictPrefix удаление всех записей, ключи которых начинаются с prefix. Возвращает количество удаленных записей.
+func (c *LRU) EvictPrefix(ctx context.Context, prefix string) (n int, err error) {
+	defer c.awaitLog()
 
-	now := time.Now()
+	return c.cache.EvictPrefix(prefix)
+}
 
-	var nextEnt *list.Entry
-	for ent := c.evictList.Back(); ; ent = nextEnt {
-		if ent == nil {
-			break
-		}
+// EvictByTag удаление всех записей, помеченных тегом tag. Возвращает количество удаленных записей.
+func (c *LRU) EvictByTag(ctx context.Context, tag string) (n int, err error) {
+	defer c.awaitLog()
 
-		nextEnt = ent.PrevEntry()
+	return c.cache.EvictByTag(tag)
+}
 
-		// Дополнительная проверка на expired
-		if now.After(ent.ExpiresAt) {
-			c.removeElement(ent)
-		}
-	}
+// GetAll получение всего наполнения кэша в виде двух слайсов: слайса ключей и слайса значений.
+// Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
+func (c *LRU) GetAll(ctx context.Context) (keys []string, values []interface{}, err error) {
+	return c.cache.GetAll()
 }
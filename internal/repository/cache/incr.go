@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// Incr атомарное увеличение числового значения записи на delta (int64 или float64).
+// Отсутствующая или истекшая запись создается с TTL ttl и значением delta, у существующей
+// сохраняется дата истечения. Для нечисловых значений возвращает ErrNotNumeric,
+// при переполнении - ErrOverflow.
+func (c *LRU) Incr(ctx context.Context, key string, delta interface{}, ttl time.Duration) (model.EntryGetData, error) {
+	defer c.awaitLog()
+
+	if _, ok := toNumber(delta); !ok {
+		return model.EntryGetData{}, fmt.Errorf("%w: delta %T", def.ErrNotNumeric, delta)
+	}
+
+	item, err := c.cache.Update(key, incrFunc(delta), ttl)
+	if err != nil {
+		return model.EntryGetData{}, err
+	}
+
+	return model.EntryGetData{
+		Key:       key,
+		Value:     item.Value,
+		ExpiresAt: item.ExpiresAt,
+		Version:   item.Version,
+	}, nil
+}
+
+// incrFunc возвращает функцию обновления, увеличивающую значение записи на delta.
+// Отсутствующая или истекшая запись считается равной нулю.
+func incrFunc(delta interface{}) lru.UpdateFunc[interface{}] {
+	return func(item lru.Item[interface{}], err error) (interface{}, error) {
+		if errors.Is(err, lru.ErrNotFound) {
+			return addNumbers(int64(0), delta)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return addNumbers(item.Value, delta)
+	}
+}
+
+// addNumbers складывает два числа. Сумма целых чисел остается целой (int64), иначе
+// результат - float64 (в том числе для значений, записанных через JSON). Если сумма целых
+// чисел не помещается в int64, а дробная сумма бесконечна или не является числом (такое
+// значение нельзя записать в JSON), возвращает ErrOverflow.
+func addNumbers(value, delta interface{}) (interface{}, error) {
+	a, ok := toNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: value %T", def.ErrNotNumeric, value)
+	}
+	b, ok := toNumber(delta)
+	if !ok {
+		return nil, fmt.Errorf("%w: delta %T", def.ErrNotNumeric, delta)
+	}
+
+	ai, aInt := a.(int64)
+	bi, bInt := b.(int64)
+	if aInt && bInt {
+		sum := ai + bi
+		// Переполнение: слагаемые одного знака, а знак суммы другой
+		if (ai^sum)&(bi^sum) < 0 {
+			return nil, fmt.Errorf("%w: %d + %d", def.ErrOverflow, ai, bi)
+		}
+		return sum, nil
+	}
+	sum := toFloat(a) + toFloat(b)
+	if math.IsInf(sum, 0) || math.IsNaN(sum) {
+		return nil, fmt.Errorf("%w: %g + %g", def.ErrOverflow, toFloat(a), toFloat(b))
+	}
+	return sum, nil
+}
+
+// toNumber приводит числовое значение к int64 или float64
+func toNumber(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return nil, false
+}
+
+// toFloat приводит результат toNumber к float64
+func toFloat(v interface{}) float64 {
+	if n, ok := v.(int64); ok {
+		return float64(n)
+	}
+	return v.(float64)
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+func TestToNumber(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+		ok    bool
+	}{
+		{"int", 5, int64(5), true},
+		{"int32", int32(-3), int64(-3), true},
+		{"int64", int64(math.MaxInt64), int64(math.MaxInt64), true},
+		{"uint8", uint8(7), int64(7), true},
+		{"float32", float32(0.5), float64(0.5), true},
+		{"float64", 1.25, 1.25, true},
+		{"string", "5", nil, false},
+		{"uint64", uint64(1), nil, false},
+		{"nil", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := toNumber(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAddNumbers(t *testing.T) {
+	tests := []struct {
+		name         string
+		value, delta interface{}
+		want         interface{}
+		err          error
+	}{
+		{"ints", 2, int64(3), int64(5), nil},
+		{"negative", int64(2), -5, int64(-3), nil},
+		{"int and float", int64(1), 0.5, 1.5, nil},
+		{"floats", 0.25, float32(0.5), 0.75, nil},
+		{"max", int64(math.MaxInt64 - 1), 1, int64(math.MaxInt64), nil},
+		{"min", int64(math.MinInt64 + 1), -1, int64(math.MinInt64), nil},
+		{"overflow", int64(math.MaxInt64), 1, nil, def.ErrOverflow},
+		{"underflow", int64(math.MinInt64), int64(-1), nil, def.ErrOverflow},
+		{"float does not overflow", float64(math.MaxInt64), 1, float64(math.MaxInt64) + 1, nil},
+		{"float overflow", math.MaxFloat64, math.MaxFloat64, nil, def.ErrOverflow},
+		{"float absorbs int", -math.MaxFloat64, int64(math.MinInt64), -math.MaxFloat64, nil},
+		{"negative float overflow", -math.MaxFloat64, -math.MaxFloat64, nil, def.ErrOverflow},
+		{"nan", math.NaN(), 1, nil, def.ErrOverflow},
+		{"string value", "1", 1, nil, def.ErrNotNumeric},
+		{"string delta", int64(1), "1", nil, def.ErrNotNumeric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addNumbers(tt.value, tt.delta)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLRU_IncrOverflow(t *testing.T) {
+	c, err := NewCache(10, time.Minute)
+	require.NoError(t, err)
+
+	_, err = c.Put(context.Background(), model.EntryPutData{Key: "a", Value: int64(math.MaxInt64)})
+	require.NoError(t, err)
+
+	_, err = c.Incr(context.Background(), "a", 1, 0)
+	assert.ErrorIs(t, err, def.ErrOverflow)
+
+	// Значение записи не меняется
+	entry, err := c.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt64), entry.Value)
+}
+
+func TestLRU_IncrFloatOverflow(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache(10, time.Minute)
+	require.NoError(t, err)
+
+	_, err = c.Put(ctx, model.EntryPutData{Key: "f", Value: 1.7e308})
+	require.NoError(t, err)
+
+	_, err = c.Incr(ctx, "f", 1.7e308, 0)
+	assert.ErrorIs(t, err, def.ErrOverflow)
+
+	// Значение записи не меняется, и кэш по-прежнему записывается в снимок
+	entry, err := c.Get(ctx, "f")
+	require.NoError(t, err)
+	assert.Equal(t, 1.7e308, entry.Value)
+	_, err = c.Snapshot(ctx, io.Discard)
+	assert.NoError(t, err)
+}
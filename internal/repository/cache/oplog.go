@@ -0,0 +1,558 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+const (
+	opLogFormat  = "golang-cache-lru/oplog" // Идентификатор формата журнала в заголовке
+	opLogVersion = 1                        // Версия формата журнала (строки записей - как у снимка)
+
+	opLogSyncInterval = time.Second // Период сброса журнала на диск при политике FsyncEverySec
+)
+
+const (
+	opPut      = "put"       // Запись сохранена, строка содержит ее состояние
+	opEvict    = "evict"     // Запись удалена
+	opEvictAll = "evict_all" // Кэш очищен
+)
+
+// opLogRecord задает строку журнала с одним изменением кэша
+type opLogRecord struct {
+	Op     string          `json:"op"`
+	Key    string          `json:"key,omitempty"`    // Ключ удаленной записи (opEvict)
+	Record *snapshotRecord `json:"record,omitempty"` // Состояние сохраненной записи (opPut)
+}
+
+// opLog журнал изменений кэша в формате NDJSON: заголовок (как у снимка) и по строке на изменение.
+//
+// Кэш сообщает об изменениях под блокировкой шарда, поэтому изменения одного ключа попадают в журнал
+// в порядке выполнения. Под этой блокировкой изменение только кодируется и добавляется в буфер, а в файл
+// его дописывает и сбрасывает на диск фоновая горутина, поэтому медленный диск не задерживает обращения
+// к шарду. При политике FsyncAlways операция кэша после снятия блокировки шарда дожидается сброса своего
+// изменения на диск (см. wait); изменения, накопившиеся за время сброса, сбрасываются вместе.
+// Запись сохраненного значения содержит его полное состояние, поэтому повторное применение журнала
+// поверх снимка или поверх уже примененной части дает тот же результат.
+type opLog struct {
+	cfg model.OpLogConfig
+
+	io sync.Mutex // упорядочивает работу с файлом: дозапись, сброс на диск, замену при сжатии и закрытие
+
+	mu       sync.Mutex
+	cond     *sync.Cond    // сообщает ожидающим в wait о сбросе изменений на диск, ошибке и закрытии
+	file     *os.File      // текущий файл журнала, nil - еще не записан первым сжатием или уже закрыт
+	size     int64         // текущий размер журнала
+	base     int64         // размер журнала после последнего сжатия
+	dirty    bool          // есть записанные в файл изменения, еще не сброшенные на диск
+	pending  []byte        // изменения, еще не записанные в файл
+	appended uint64        // количество изменений, добавленных в журнал
+	synced   uint64        // количество изменений, сброшенных на диск (для FsyncAlways)
+	rewrite  *bytes.Buffer // изменения, сделанные во время сжатия, nil - сжатие не выполняется
+	err      error         // ошибка записи в журнал: изменения не пишутся до следующего сжатия
+	closed   bool
+
+	wake    chan struct{} // будит фоновую горутину при появлении изменений в pending
+	done    chan struct{} // закрывается в close для остановки фоновой горутины
+	stopped chan struct{} // закрывается фоновой горутиной при завершении
+}
+
+// newOpLog создает журнал с параметрами cfg. Файл журнала записывается первым сжатием.
+func newOpLog(cfg model.OpLogConfig) *opLog {
+	l := &opLog{
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// validateOpLogConfig проверяет параметры журнала
+func validateOpLogConfig(cfg model.OpLogConfig) error {
+	switch {
+	case cfg.Path == "":
+		return fmt.Errorf("%w: path is empty", def.ErrInvalidLog)
+	case cfg.Fsync != model.FsyncAlways && cfg.Fsync != model.FsyncEverySec && cfg.Fsync != model.FsyncNever:
+		return fmt.Errorf("%w: unknown fsync policy %q", def.ErrInvalidLog, cfg.Fsync)
+	case cfg.CompactMinBytes < 0:
+		return fmt.Errorf("%w: compact min bytes can not be negative", def.ErrInvalidLog)
+	}
+	return nil
+}
+
+// OpenLog включение журнала изменений cfg.Path. Изменения из существующего журнала применяются поверх
+// текущего содержимого кэша (например, загруженного из снимка); оборванная при сбое последняя строка
+// пропускается. Затем журнал переписывается из получившегося содержимого кэша, и далее в него
+// дописываются все изменения. Возвращает количество примененных изменений.
+func (c *LRU) OpenLog(ctx context.Context, cfg model.OpLogConfig) (n int, err error) {
+	return openLog(c, &c.oplog, cfg)
+}
+
+// CompactLog переписывает журнал изменений из текущего содержимого кэша, если журнал вырос вдвое
+// с прошлого сжатия и превысил cfg.CompactMinBytes, а также после ошибки записи в журнал.
+// Если журнал не включен, ничего не делает.
+func (c *LRU) CompactLog(ctx context.Context) (compacted bool, err error) {
+	return compactLogIfDue(c, c.oplog.Load())
+}
+
+// observe получает изменения кэша и дописывает их в журнал, если он включен
+func (c *LRU) observe(m lru.Mutation[string, interface{}]) {
+	if l := c.oplog.Load(); l != nil {
+		l.append(m)
+	}
+}
+
+// awaitLog дожидается сброса изменений операции в журнал на диск при политике FsyncAlways (см. opLog.wait)
+func (c *LRU) awaitLog() {
+	c.oplog.Load().wait()
+}
+
+// openLog применяет журнал cfg.Path к store и включает запись изменений store в журнал,
+// сохраняя его в oplog
+func openLog(store recordStore, oplog *atomic.Pointer[opLog], cfg model.OpLogConfig) (n int, err error) {
+	if err := validateOpLogConfig(cfg); err != nil {
+		return 0, err
+	}
+
+	n, err = replayLog(store, cfg.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return n, err
+	}
+
+	l := newOpLog(cfg)
+	if !oplog.CompareAndSwap(nil, l) {
+		return n, fmt.Errorf("%w: log is already open", def.ErrInvalidLog)
+	}
+	if err := compactLog(store, l); err != nil {
+		oplog.Store(nil)
+		return n, err
+	}
+
+	go l.run()
+	return n, nil
+}
+
+// compactLogIfDue переписывает журнал l из содержимого store, если пора (см. CompactLog).
+// Для nil ничего не делает.
+func compactLogIfDue(store recordStore, l *opLog) (compacted bool, err error) {
+	if l == nil {
+		return false, nil
+	}
+
+	l.mu.Lock()
+	writeErr := l.err
+	due := writeErr != nil || (l.size >= l.cfg.CompactMinBytes && l.size >= 2*l.base)
+	l.mu.Unlock()
+	if !due {
+		return false, nil
+	}
+
+	if err := compactLog(store, l); err != nil {
+		return false, errors.Join(writeErr, err)
+	}
+	if writeErr != nil {
+		// Журнал восстановлен, но о сбое записи нужно сообщить
+		return true, fmt.Errorf("log rewritten after write failure: %w", writeErr)
+	}
+	return true, nil
+}
+
+// replayLog применяет изменения из журнала path к store
+func replayLog(store recordStore, path string) (n int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	header, err := r.ReadBytes('\n')
+	if errors.Is(err, io.EOF) {
+		// Журнал оборвался до конца заголовка: изменений в нем нет
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var h snapshotHeader
+	if err := json.Unmarshal(header, &h); err != nil {
+		return 0, fmt.Errorf("%w: %v", def.ErrInvalidLog, err)
+	}
+	if h.Format != opLogFormat || h.Version != opLogVersion {
+		return 0, fmt.Errorf("%w: unsupported format %q version %d", def.ErrInvalidLog, h.Format, h.Version)
+	}
+
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Строка без перевода строки в конце не была дописана до сбоя и не применяется
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+
+		var op opLogRecord
+		if err := json.Unmarshal(line, &op); err != nil {
+			return n, fmt.Errorf("%w: %v", def.ErrInvalidLog, err)
+		}
+		if err := applyLogRecord(store, op); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// applyLogRecord применяет к store изменение из строки журнала
+func applyLogRecord(store recordStore, op opLogRecord) error {
+	switch op.Op {
+	case opPut:
+		if op.Record == nil {
+			return fmt.Errorf("%w: put without record", def.ErrInvalidLog)
+		}
+		rec, err := op.Record.record()
+		if err != nil {
+			return fmt.Errorf("%w: %v", def.ErrInvalidLog, err)
+		}
+		err = store.importRecord(rec)
+		if errors.Is(err, lru.ErrExpired) || errors.Is(err, lru.ErrTooLarge) {
+			return nil
+		}
+		return err
+	case opEvict:
+		err := store.evictKey(op.Key)
+		if errors.Is(err, lru.ErrNotFound) {
+			return nil
+		}
+		return err
+	case opEvictAll:
+		return store.evictAll()
+	default:
+		return fmt.Errorf("%w: unknown operation %q", def.ErrInvalidLog, op.Op)
+	}
+}
+
+// compactLog переписывает журнал l из текущего содержимого store. Новый журнал записывается во временный
+// файл рядом с прежним; изменения, сделанные во время записи, дописываются и в прежний журнал, и в буфер,
+// который добавляется в конец нового журнала перед заменой прежнего.
+func compactLog(store recordStore, l *opLog) (err error) {
+	l.mu.Lock()
+	if l.rewrite != nil || l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.rewrite = new(bytes.Buffer)
+	l.mu.Unlock()
+
+	var (
+		tmp      *os.File
+		switched bool // изменения уже пишутся только для нового журнала
+	)
+	defer func() {
+		if err != nil {
+			l.mu.Lock()
+			l.rewrite = nil
+			if switched && l.err == nil {
+				// Изменения после переключения не попали в прежний журнал, он больше не полон
+				l.err = err
+			}
+			l.cond.Broadcast()
+			l.mu.Unlock()
+			if tmp != nil {
+				tmp.Close()
+				os.Remove(tmp.Name())
+			}
+		}
+	}()
+
+	dir := filepath.Dir(l.cfg.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if tmp, err = os.CreateTemp(dir, filepath.Base(l.cfg.Path)+".tmp-*"); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	err = enc.Encode(snapshotHeader{Format: opLogFormat, Version: opLogVersion, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	err = store.exportRecords(func(rec lru.Record[string, interface{}]) error {
+		line, err := snapshotRecordOf(rec)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(opLogRecord{Op: opPut, Record: &line})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Фоновая горутина не пишет в прежний журнал, пока он не заменен
+	l.io.Lock()
+	defer l.io.Unlock()
+
+	// Переключение на новый журнал: изменения из буфера сжатия дописываются в его конец, а еще не
+	// записанные в прежний журнал отбрасываются - они уже есть в буфере или в выгруженном содержимом.
+	// Изменения после переключения копятся в pending и дописываются в новый журнал после замены.
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return def.ErrClosed
+	}
+	rewrite, seq := l.rewrite, l.appended
+	l.rewrite, l.pending, l.err = nil, nil, nil
+	switched = true
+	l.mu.Unlock()
+
+	if _, err = rewrite.WriteTo(w); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), l.cfg.Path); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	old := l.file
+	l.file, l.size, l.base = tmp, info.Size(), info.Size()
+	l.dirty = false
+	l.synced = max(l.synced, seq)
+	l.cond.Broadcast()
+	l.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// closeIfOpen сбрасывает журнал на диск и закрывает его. Для nil ничего не делает.
+func (l *opLog) closeIfOpen() error {
+	if l == nil {
+		return nil
+	}
+	return l.close()
+}
+
+// append дописывает изменение m в журнал
+func (l *opLog) append(m lru.Mutation[string, interface{}]) {
+	op := opLogRecord{}
+	switch m.Kind {
+	case lru.MutationPut:
+		line, err := snapshotRecordOf(m.Record)
+		if err != nil {
+			l.fail(err)
+			return
+		}
+		op.Op, op.Record = opPut, &line
+	case lru.MutationEvict:
+		op.Op, op.Key = opEvict, m.Record.Key
+	case lru.MutationClear:
+		op.Op = opEvictAll
+	}
+	l.write(op)
+}
+
+// write добавляет строку op в журнал. Вызывается под блокировкой шарда, поэтому только кодирует
+// изменение и добавляет его в буфер; в файл его дописывает фоновая горутина (см. flush).
+func (l *opLog) write(op opLogRecord) {
+	data, err := json.Marshal(op)
+	if err != nil {
+		l.fail(err)
+		return
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	if l.rewrite != nil {
+		l.rewrite.Write(data)
+	}
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.appended++
+	if l.err == nil {
+		l.pending = append(l.pending, data...)
+	}
+	l.mu.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// wait при политике FsyncAlways дожидается сброса на диск всех изменений, добавленных в журнал до вызова.
+// Вызывается после операции кэша, уже без блокировки шарда. Не ждет, если журнал еще не записан первым
+// сжатием, закрыт или не дописывается после ошибки. Для nil ничего не делает.
+func (l *opLog) wait() {
+	if l == nil || l.cfg.Fsync != model.FsyncAlways {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seq := l.appended
+	for l.synced < seq && l.file != nil && l.err == nil {
+		l.cond.Wait()
+	}
+}
+
+// flush дописывает в файл изменения из pending и при политике FsyncAlways сбрасывает их на диск
+func (l *opLog) flush() {
+	l.io.Lock()
+	defer l.io.Unlock()
+
+	l.mu.Lock()
+	file, data, seq := l.file, l.pending, l.appended
+	if file == nil || l.err != nil || len(data) == 0 {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+
+	n, err := file.Write(data)
+	if err == nil && l.cfg.Fsync == model.FsyncAlways {
+		err = file.Sync()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.size += int64(n)
+	switch {
+	case err != nil:
+		if l.err == nil {
+			l.err = err
+		}
+	case l.cfg.Fsync == model.FsyncAlways:
+		l.synced = seq
+	default:
+		l.dirty = true
+	}
+	l.cond.Broadcast()
+}
+
+// fail запоминает ошибку записи: до следующего сжатия журнал не дописывается, иначе в нем
+// осталось бы пропущенное изменение
+func (l *opLog) fail(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err == nil {
+		l.err = err
+	}
+	l.cond.Broadcast()
+}
+
+// sync сбрасывает на диск изменения, записанные в файл с прошлого сброса
+func (l *opLog) sync() {
+	l.io.Lock()
+	defer l.io.Unlock()
+
+	l.mu.Lock()
+	file, dirty := l.file, l.dirty && l.err == nil
+	l.mu.Unlock()
+	if file == nil || !dirty {
+		return
+	}
+
+	err := file.Sync()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err != nil {
+		if l.err == nil {
+			l.err = err
+		}
+		return
+	}
+	l.dirty = false
+}
+
+// run дописывает в файл добавленные изменения и раз в opLogSyncInterval сбрасывает журнал на диск
+// при политике FsyncEverySec
+func (l *opLog) run() {
+	defer close(l.stopped)
+
+	var tick <-chan time.Time
+	if l.cfg.Fsync == model.FsyncEverySec {
+		ticker := time.NewTicker(opLogSyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.wake:
+			l.flush()
+		case <-tick:
+			l.sync()
+		}
+	}
+}
+
+// close останавливает фоновую горутину, дописывает накопленные изменения, сбрасывает журнал на диск
+// при любой политике и закрывает его. Возвращает ошибку записи, если после нее журнал так и не был переписан.
+func (l *opLog) close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	close(l.done)
+	<-l.stopped
+
+	l.flush()
+
+	l.io.Lock()
+	defer l.io.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	errs := []error{l.err}
+	if l.file != nil {
+		errs = append(errs, l.file.Sync(), l.file.Close())
+		l.file = nil
+	}
+	l.cond.Broadcast()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// logStore задает кэш с журналом изменений для тестов обоих движков хранения
+type logStore interface {
+	def.ILRUCache
+	recordStore
+}
+
+// newLogStores возвращает конструкторы кэшей обоих движков хранения
+func newLogStores() map[string]func(t *testing.T) logStore {
+	return map[string]func(t *testing.T) logStore{
+		"heap": func(t *testing.T) logStore {
+			c, err := NewCache(100, time.Hour, lru.WithShards(2))
+			require.NoError(t, err)
+			return c
+		},
+		"arena": func(t *testing.T) logStore {
+			a, err := NewArena(100, time.Hour, ArenaOptions{Shards: 2, JanitorInterval: time.Minute, JanitorBudget: 10})
+			require.NoError(t, err)
+			return a
+		},
+	}
+}
+
+// contents возвращает значения всех записей store по ключам
+func contents(t *testing.T, store recordStore) map[string]interface{} {
+	t.Helper()
+
+	values := make(map[string]interface{})
+	require.NoError(t, store.exportRecords(func(rec lru.Record[string, interface{}]) error {
+		values[rec.Key] = rec.Value
+		return nil
+	}))
+	return values
+}
+
+func TestOpLog_ReplayAfterRestart(t *testing.T) {
+	for engine, newStore := range newLogStores() {
+		t.Run(engine, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: model.FsyncNever}
+
+			c := newStore(t)
+			n, err := c.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, 0, n)
+
+			_, err = c.Put(ctx, model.EntryPutData{Key: "gone", Value: "x"})
+			require.NoError(t, err)
+			require.NoError(t, c.EvictAll(ctx))
+			for i := range 5 {
+				_, err := c.Put(ctx, model.EntryPutData{Key: fmt.Sprintf("k%d", i), Value: int64(i), Tags: []string{"t"}})
+				require.NoError(t, err)
+			}
+			_, err = c.Evict(ctx, "k0")
+			require.NoError(t, err)
+			_, err = c.Incr(ctx, "k1", int64(10), 0)
+			require.NoError(t, err)
+			want := contents(t, c)
+			require.NoError(t, c.Close(ctx))
+
+			restored := newStore(t)
+			defer restored.Close(ctx)
+			n, err = restored.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+			// put, evict_all, 5 put, evict, put
+			assert.Equal(t, 9, n)
+			assert.Equal(t, want, contents(t, restored))
+			assert.Equal(t, map[string]interface{}{"k1": int64(11), "k2": int64(2), "k3": int64(3), "k4": int64(4)}, want)
+
+			// Открытие переписывает журнал из содержимого кэша
+			require.NoError(t, restored.Close(ctx))
+			again := newStore(t)
+			defer again.Close(ctx)
+			n, err = again.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, 4, n)
+			assert.Equal(t, want, contents(t, again))
+		})
+	}
+}
+
+func TestOpLog_TruncatedLastLine(t *testing.T) {
+	ctx := context.Background()
+	cfg := model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: model.FsyncAlways}
+
+	c, err := NewCache(10, time.Hour)
+	require.NoError(t, err)
+	_, err = c.OpenLog(ctx, cfg)
+	require.NoError(t, err)
+	_, err = c.Put(ctx, model.EntryPutData{Key: "a", Value: "x"})
+	require.NoError(t, err)
+	require.NoError(t, c.Close(ctx))
+
+	// Сбой во время дозаписи оставляет строку без перевода строки в конце
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put","record":{"key":"b","val`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	restored, err := NewCache(10, time.Hour)
+	require.NoError(t, err)
+	defer restored.Close(ctx)
+	n, err := restored.OpenLog(ctx, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, map[string]interface{}{"a": "x"}, contents(t, restored))
+
+	// Поврежденная строка в середине журнала - ошибка, а не пропуск
+	require.NoError(t, os.WriteFile(cfg.Path, []byte(`{"format":"golang-cache-lru/oplog","version":1}`+"\n"+`{"op":`+"\n"), 0o644))
+	broken, err := NewCache(10, time.Hour)
+	require.NoError(t, err)
+	defer broken.Close(ctx)
+	_, err = broken.OpenLog(ctx, cfg)
+	assert.ErrorIs(t, err, def.ErrInvalidLog)
+}
+
+func TestOpLog_CompactionRacingWrites(t *testing.T) {
+	for engine, newStore := range newLogStores() {
+		t.Run(engine, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: model.FsyncNever}
+
+			c := newStore(t)
+			_, err := c.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for w := range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range 200 {
+						key := fmt.Sprintf("k%d", (w*200+i)%50)
+						if i%7 == 0 {
+							_, _ = c.Evict(ctx, key)
+							continue
+						}
+						_, err := c.Put(ctx, model.EntryPutData{Key: key, Value: int64(w*1000 + i)})
+						assert.NoError(t, err)
+					}
+				}()
+			}
+
+			var l *opLog
+			switch store := c.(type) {
+			case *LRU:
+				l = store.oplog.Load()
+			case *Arena:
+				l = store.oplog.Load()
+			}
+			for range 20 {
+				require.NoError(t, compactLog(c, l))
+			}
+			wg.Wait()
+
+			want := contents(t, c)
+			require.NoError(t, c.Close(ctx))
+
+			restored := newStore(t)
+			defer restored.Close(ctx)
+			_, err = restored.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, want, contents(t, restored))
+		})
+	}
+}
+
+func TestOpLog_FsyncPolicies(t *testing.T) {
+	for _, fsync := range []string{model.FsyncAlways, model.FsyncEverySec, model.FsyncNever} {
+		t.Run(fsync, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: fsync}
+
+			c, err := NewCache(10, time.Hour)
+			require.NoError(t, err)
+			_, err = c.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+
+			_, err = c.Put(ctx, model.EntryPutData{Key: "a", Value: "x"})
+			require.NoError(t, err)
+
+			inFile := func() bool {
+				data, err := os.ReadFile(cfg.Path)
+				require.NoError(t, err)
+				return len(data) > 0 && data[len(data)-1] == '\n' && bytes.Contains(data, []byte(`"key":"a"`))
+			}
+			if fsync == model.FsyncAlways {
+				// Операция завершается только после сброса изменения на диск
+				assert.True(t, inFile())
+			} else {
+				// Изменение дописывается в файл фоновой горутиной
+				assert.Eventually(t, inFile, time.Second, time.Millisecond)
+			}
+
+			l := c.oplog.Load()
+			if fsync == model.FsyncEverySec {
+				assert.Eventually(t, func() bool {
+					l.mu.Lock()
+					defer l.mu.Unlock()
+					return !l.dirty
+				}, 3*opLogSyncInterval, 10*time.Millisecond)
+			}
+
+			require.NoError(t, c.Close(ctx))
+			restored, err := NewCache(10, time.Hour)
+			require.NoError(t, err)
+			defer restored.Close(ctx)
+			_, err = restored.OpenLog(ctx, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"a": "x"}, contents(t, restored))
+		})
+	}
+
+	c, err := NewCache(10, time.Hour)
+	require.NoError(t, err)
+	_, err = c.OpenLog(context.Background(), model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: "sometimes"})
+	assert.ErrorIs(t, err, def.ErrInvalidLog)
+}
+
+func TestOpLog_RecoveryAfterWriteError(t *testing.T) {
+	ctx := context.Background()
+	cfg := model.OpLogConfig{Path: filepath.Join(t.TempDir(), "cache.oplog"), Fsync: model.FsyncAlways}
+
+	c, err := NewCache(10, time.Hour)
+	require.NoError(t, err)
+	_, err = c.OpenLog(ctx, cfg)
+	require.NoError(t, err)
+	_, err = c.Put(ctx, model.EntryPutData{Key: "a", Value: "x"})
+	require.NoError(t, err)
+
+	// Файл журнала закрывается под ногами, и следующая дозапись завершается ошибкой
+	l := c.oplog.Load()
+	l.io.Lock()
+	require.NoError(t, l.file.Close())
+	l.io.Unlock()
+
+	_, err = c.Put(ctx, model.EntryPutData{Key: "b", Value: "y"})
+	require.NoError(t, err)
+	l.mu.Lock()
+	writeErr := l.err
+	l.mu.Unlock()
+	require.Error(t, writeErr)
+
+	// После ошибки журнал переписывается из содержимого кэша при следующей проверке сжатия
+	compacted, err := c.CompactLog(ctx)
+	assert.True(t, compacted)
+	assert.ErrorIs(t, err, writeErr)
+
+	_, err = c.Put(ctx, model.EntryPutData{Key: "c", Value: "z"})
+	require.NoError(t, err)
+	compacted, err = c.CompactLog(ctx)
+	assert.False(t, compacted)
+	assert.NoError(t, err)
+	require.NoError(t, c.Close(ctx))
+
+	restored, err := NewCache(10, time.Hour)
+	require.NoError(t, err)
+	defer restored.Close(ctx)
+	_, err = restored.OpenLog(ctx, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "x", "b": "y", "c": "z"}, contents(t, restored))
+}
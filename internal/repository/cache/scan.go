@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// Scan постраничный обход кэша. Курсор - непрозрачная строка, кодирующая lru.Cursor.
+func (c *LRU) Scan(ctx context.Context, cursor string, limit int, order string) (model.ScanPage, error) {
+	var scanOrder lru.ScanOrder
+	switch order {
+	case model.ScanOrderLRU:
+		scanOrder = lru.ScanLRU
+	case model.ScanOrderMRU:
+		scanOrder = lru.ScanMRU
+	default:
+		return model.ScanPage{}, def.ErrInvalidCursor
+	}
+
+	from, err := decodeCursor(cursor)
+	if err != nil {
+		return model.ScanPage{}, err
+	}
+
+	keys, values, next, err := c.cache.Scan(from, limit, scanOrder)
+	if errors.Is(err, lru.ErrInvalidOption) && limit > 0 {
+		// При корректном limit отказ означает курсор с несуществующим номером шарда
+		return model.ScanPage{}, def.ErrInvalidCursor
+	}
+	if err != nil {
+		return model.ScanPage{}, err
+	}
+
+	return model.ScanPage{
+		Keys:   keys,
+		Values: values,
+		Cursor: encodeCursor(next),
+	}, nil
+}
+
+// encodeCursor кодирует курсор обхода в строку вида base64("шард.отметка.ключ").
+// Завершенный обход кодируется пустой строкой.
+func encodeCursor(cursor lru.Cursor[string]) string {
+	if cursor.Done() {
+		return ""
+	}
+	raw := strconv.Itoa(cursor.Shard) + "." + strconv.FormatUint(cursor.Stamp, 10) + "." + cursor.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor декодирует курсор, полученный от encodeCursor. Пустая строка - начало обхода.
+func decodeCursor(cursor string) (lru.Cursor[string], error) {
+	if len(cursor) == 0 {
+		return lru.Cursor[string]{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return lru.Cursor[string]{}, def.ErrInvalidCursor
+	}
+
+	// Ключ идет последним и может содержать точки
+	parts := strings.SplitN(string(raw), ".", 3)
+	if len(parts) != 3 {
+		return lru.Cursor[string]{}, def.ErrInvalidCursor
+	}
+	shard, err := strconv.Atoi(parts[0])
+	if err != nil || shard < 0 {
+		return lru.Cursor[string]{}, def.ErrInvalidCursor
+	}
+	stamp, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return lru.Cursor[string]{}, def.ErrInvalidCursor
+	}
+
+	return lru.Cursor[string]{Shard: shard, Stamp: stamp, Key: parts[2]}, nil
+}
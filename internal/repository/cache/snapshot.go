@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+const (
+	snapshotFormat  = "golang-cache-lru/snapshot" // Идентификатор формата снимка в заголовке
+	snapshotVersion = 1                           // Версия формата снимка
+)
+
+// snapshotHeader задает первую строку снимка
+type snapshotHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// snapshotRecord задает строку снимка с одной записью кэша
+type snapshotRecord struct {
+	Key          string          `json:"key"`
+	Value        json.RawMessage `json:"value"`
+	ExpiresAt    int64           `json:"expires_at_ns,omitempty"` // Дата истечения (Unix, нс), отсутствует у неистекающих записей
+	TTL          time.Duration   `json:"ttl_ns,omitempty"`
+	Sliding      bool            `json:"sliding,omitempty"`
+	StaleTTL     time.Duration   `json:"stale_ttl_ns,omitempty"`
+	RefreshAhead time.Duration   `json:"refresh_ahead_ns,omitempty"`
+	Tags         []string        `json:"tags,omitempty"`
+}
+
+// recordStore описывает хранилище, содержимое которого выгружается и загружается записями lru.Record.
+// Через него снимки и журнал изменений работают с любым движком хранения (LRU и Arena).
+type recordStore interface {
+	// exportRecords вызывает fn для всех записей, которые еще можно отдать, в порядке использования (см. lru.Cache.Export)
+	exportRecords(fn func(rec lru.Record[string, interface{}]) error) error
+	// importRecord записывает запись с сохраненными датой истечения и параметрами (см. lru.Cache.Import)
+	importRecord(rec lru.Record[string, interface{}]) error
+	// evictKey удаляет запись по ключу
+	evictKey(key string) error
+	// evictAll удаляет все записи
+	evictAll() error
+}
+
+// Snapshot запись содержимого кэша в w в формате NDJSON: первая строка - заголовок с форматом и версией,
+// далее по строке на запись. Записи каждого шарда идут от давно не использовавшихся к недавно использованным.
+// Дата истечения сохраняется абсолютной, поэтому оставшийся TTL отсчитывается и во время простоя.
+// Возвращает количество записанных записей.
+func (c *LRU) Snapshot(ctx context.Context, w io.Writer) (n int, err error) {
+	return writeSnapshot(c, w)
+}
+
+// Restore загрузка содержимого кэша из снимка, записанного Snapshot. Записи, срок которых прошел
+// за время простоя, и записи, превышающие лимит размера кэша, пропускаются. Возвращает количество
+// восстановленных записей; для снимка неизвестного формата или версии возвращает ErrInvalidSnapshot.
+func (c *LRU) Restore(ctx context.Context, r io.Reader) (n int, err error) {
+	return readSnapshot(c, r)
+}
+
+// exportRecords выгрузка записей кэша для снимка и журнала изменений
+func (c *LRU) exportRecords(fn func(rec lru.Record[string, interface{}]) error) error {
+	return c.cache.Export(fn)
+}
+
+// importRecord загрузка записи из снимка или журнала изменений
+func (c *LRU) importRecord(rec lru.Record[string, interface{}]) error {
+	return c.cache.Import(rec)
+}
+
+// evictKey удаление записи при применении журнала изменений
+func (c *LRU) evictKey(key string) error {
+	_, err := c.cache.Evict(key)
+	return err
+}
+
+// evictAll очистка кэша при применении журнала изменений
+func (c *LRU) evictAll() error {
+	return c.cache.EvictAll()
+}
+
+// writeSnapshot записывает содержимое store в w в формате снимка
+func writeSnapshot(store recordStore, w io.Writer) (n int, err error) {
+	enc := json.NewEncoder(w)
+
+	err = enc.Encode(snapshotHeader{Format: snapshotFormat, Version: snapshotVersion, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return 0, err
+	}
+
+	err = store.exportRecords(func(rec lru.Record[string, interface{}]) error {
+		line, err := snapshotRecordOf(rec)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// readSnapshot загружает в store записи из снимка, записанного writeSnapshot
+func readSnapshot(store recordStore, r io.Reader) (n int, err error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return 0, fmt.Errorf("%w: %v", def.ErrInvalidSnapshot, err)
+	}
+	if header.Format != snapshotFormat || header.Version != snapshotVersion {
+		return 0, fmt.Errorf("%w: unsupported format %q version %d", def.ErrInvalidSnapshot, header.Format, header.Version)
+	}
+
+	for {
+		var line snapshotRecord
+		err := dec.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("%w: %v", def.ErrInvalidSnapshot, err)
+		}
+
+		rec, err := line.record()
+		if err != nil {
+			return n, fmt.Errorf("%w: %v", def.ErrInvalidSnapshot, err)
+		}
+
+		err = store.importRecord(rec)
+		switch {
+		case errors.Is(err, lru.ErrExpired), errors.Is(err, lru.ErrTooLarge):
+			continue
+		case err != nil:
+			return n, err
+		}
+		n++
+	}
+}
+
+// snapshotRecordOf возвращает строку снимка с записью кэша rec
+func snapshotRecordOf(rec lru.Record[string, interface{}]) (snapshotRecord, error) {
+	value, err := json.Marshal(rec.Value)
+	if err != nil {
+		return snapshotRecord{}, fmt.Errorf("key %q: %w", rec.Key, err)
+	}
+
+	line := snapshotRecord{
+		Key:          rec.Key,
+		Value:        value,
+		TTL:          rec.TTL,
+		Sliding:      rec.Sliding,
+		StaleTTL:     rec.StaleTTL,
+		RefreshAhead: rec.RefreshAhead,
+		Tags:         rec.Tags,
+	}
+	if !rec.ExpiresAt.IsZero() {
+		line.ExpiresAt = rec.ExpiresAt.UnixNano()
+	}
+	return line, nil
+}
+
+// record возвращает запись кэша, сохраненную в строке снимка
+func (line snapshotRecord) record() (lru.Record[string, interface{}], error) {
+	value, err := decodeSnapshotValue(line.Value)
+	if err != nil {
+		return lru.Record[string, interface{}]{}, fmt.Errorf("key %q: %v", line.Key, err)
+	}
+
+	rec := lru.Record[string, interface{}]{
+		Key:          line.Key,
+		Value:        value,
+		TTL:          line.TTL,
+		Sliding:      line.Sliding,
+		StaleTTL:     line.StaleTTL,
+		RefreshAhead: line.RefreshAhead,
+		Tags:         line.Tags,
+	}
+	if line.ExpiresAt != 0 {
+		rec.ExpiresAt = time.Unix(0, line.ExpiresAt)
+	}
+	return rec, nil
+}
+
+// decodeSnapshotValue декодирует значение записи так же, как его декодирует API при записи,
+// но целые числа верхнего уровня восстанавливаются как int64, чтобы счетчики Incr оставались целыми
+func decodeSnapshotValue(raw json.RawMessage) (interface{}, error) {
+	// json.Number принимает и строки, содержащие число, поэтому число распознается по первому символу
+	if len(raw) > 0 && (raw[0] == '-' || ('0' <= raw[0] && raw[0] <= '9')) {
+		number := json.Number(raw)
+		if i, err := number.Int64(); err == nil {
+			return i, nil
+		}
+		return number.Float64()
+	}
+
+	var value interface{}
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
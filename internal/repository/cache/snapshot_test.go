@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// exportKeys возвращает ключи store в порядке выгрузки (от давно не использовавшихся к недавно использованным)
+func exportKeys(t *testing.T, store recordStore) []string {
+	t.Helper()
+
+	var keys []string
+	require.NoError(t, store.exportRecords(func(rec lru.Record[string, interface{}]) error {
+		keys = append(keys, rec.Key)
+		return nil
+	}))
+	return keys
+}
+
+func TestLRU_SnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache(10, time.Hour, lru.WithShards(1))
+	require.NoError(t, err)
+
+	puts := []model.EntryPutData{
+		{Key: "a", Value: int64(1)},
+		{Key: "b", Value: "text", TTL: time.Hour, Sliding: true, Tags: []string{"t1"}},
+		{Key: "c", Value: map[string]interface{}{"x": 1.5}, TTL: 1500 * time.Microsecond, StaleTTL: time.Hour, RefreshAhead: 250 * time.Microsecond},
+	}
+	for _, data := range puts {
+		_, err := c.Put(ctx, data)
+		require.NoError(t, err)
+	}
+	// Перезапись меняет порядок использования: a становится недавно использованной
+	_, err = c.Put(ctx, model.EntryPutData{Key: "a", Value: int64(1)})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := c.Snapshot(ctx, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	restored, err := NewCache(10, time.Hour, lru.WithShards(1))
+	require.NoError(t, err)
+	n, err = restored.Restore(ctx, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// Порядок использования сохраняется
+	assert.Equal(t, exportKeys(t, c), exportKeys(t, restored))
+	assert.Equal(t, []string{"b", "c", "a"}, exportKeys(t, restored))
+
+	// Даты истечения и сроки короче миллисекунды сохраняются без округления
+	want := make(map[string]lru.Record[string, interface{}])
+	require.NoError(t, c.exportRecords(func(rec lru.Record[string, interface{}]) error {
+		want[rec.Key] = rec
+		return nil
+	}))
+	require.NoError(t, restored.exportRecords(func(rec lru.Record[string, interface{}]) error {
+		w := want[rec.Key]
+		assert.True(t, w.ExpiresAt.Equal(rec.ExpiresAt), rec.Key)
+		assert.Equal(t, w.TTL, rec.TTL, rec.Key)
+		assert.Equal(t, w.Sliding, rec.Sliding, rec.Key)
+		assert.Equal(t, w.StaleTTL, rec.StaleTTL, rec.Key)
+		assert.Equal(t, w.RefreshAhead, rec.RefreshAhead, rec.Key)
+		assert.Equal(t, w.Tags, rec.Tags, rec.Key)
+		assert.Equal(t, w.Value, rec.Value, rec.Key)
+		return nil
+	}))
+
+	// Счетчик остается целым и после восстановления
+	entry, err := restored.Incr(ctx, "a", int64(1), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), entry.Value)
+}
+
+func TestLRU_RestoreSkipsExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	require.NoError(t, enc.Encode(snapshotHeader{Format: snapshotFormat, Version: snapshotVersion, CreatedAt: now}))
+	require.NoError(t, enc.Encode(snapshotRecord{Key: "expired", Value: json.RawMessage(`1`), ExpiresAt: now.Add(-time.Second).UnixNano(), TTL: time.Minute}))
+	require.NoError(t, enc.Encode(snapshotRecord{Key: "stale", Value: json.RawMessage(`2`), ExpiresAt: now.Add(-time.Second).UnixNano(), TTL: time.Minute, StaleTTL: time.Hour}))
+	require.NoError(t, enc.Encode(snapshotRecord{Key: "live", Value: json.RawMessage(`3`), ExpiresAt: now.Add(time.Hour).UnixNano(), TTL: time.Hour}))
+	require.NoError(t, enc.Encode(snapshotRecord{Key: "forever", Value: json.RawMessage(`4`)}))
+
+	c, err := NewCache(10, time.Hour, lru.WithShards(1))
+	require.NoError(t, err)
+	n, err := c.Restore(ctx, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = c.Get(ctx, "expired")
+	assert.ErrorIs(t, err, def.ErrNotFound)
+	assert.Equal(t, []string{"stale", "live", "forever"}, exportKeys(t, c))
+}
+
+func TestLRU_RestoreHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantN   int
+		wantErr error
+	}{
+		{"empty", "", 0, def.ErrInvalidSnapshot},
+		{"not json", "snapshot\n", 0, def.ErrInvalidSnapshot},
+		{"unknown format", `{"format":"other","version":1}` + "\n", 0, def.ErrInvalidSnapshot},
+		{"version too old", `{"format":"golang-cache-lru/snapshot","version":0}` + "\n", 0, def.ErrInvalidSnapshot},
+		{"version too new", `{"format":"golang-cache-lru/snapshot","version":2}` + "\n", 0, def.ErrInvalidSnapshot},
+		{"header only", `{"format":"golang-cache-lru/snapshot","version":1}` + "\n", 0, nil},
+		{
+			"broken record",
+			`{"format":"golang-cache-lru/snapshot","version":1}` + "\n" + `{"key":"a","value":1}` + "\n" + `{"key":`,
+			1, def.ErrInvalidSnapshot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCache(10, time.Hour)
+			require.NoError(t, err)
+
+			n, err := c.Restore(context.Background(), strings.NewReader(tt.input))
+			assert.Equal(t, tt.wantN, n)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecodeSnapshotValue(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want interface{}
+	}{
+		{"int", `42`, int64(42)},
+		{"negative int", `-7`, int64(-7)},
+		{"float", `1.5`, 1.5},
+		{"exponent", `1e3`, 1000.0},
+		{"int beyond int64", `9223372036854775808`, 9223372036854775808.0},
+		{"string", `"abc"`, "abc"},
+		{"numeric string", `"42"`, "42"},
+		{"bool", `true`, true},
+		{"null", `null`, nil},
+		{"object", `{"n":1,"s":"x"}`, map[string]interface{}{"n": 1.0, "s": "x"}},
+		{"array", `[1,"x"]`, []interface{}{1.0, "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSnapshotValue(json.RawMessage(tt.raw))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := decodeSnapshotValue(json.RawMessage(`-x`))
+	assert.Error(t, err)
+}
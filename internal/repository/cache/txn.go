@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	def "github.com/vitbogit/golang-cache-lru/internal/repository"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// Txn атомарное выполнение транзакции: условия проверяются и операции выполняются по порядку
+// под блокировкой всех затронутых шардов, изменения применяются, только если все условия выполнены
+// и ни одна операция не завершилась ошибкой. Иначе возвращается *TxnError.
+func (c *LRU) Txn(ctx context.Context, data model.TxnData) ([]model.EntryResult, error) {
+	defer c.awaitLog()
+
+	conds := make([]lru.TxnCond[string], len(data.Conditions))
+	for i, cond := range data.Conditions {
+		if len(cond.Key) == 0 {
+			return nil, fmt.Errorf("условие %d: некорректные входные данные", i)
+		}
+		conds[i] = lru.TxnCond[string]{Key: cond.Key, Version: cond.Version}
+		switch cond.Cond {
+		case model.TxnCondExists:
+			conds[i].Type = lru.TxnExists
+		case model.TxnCondAbsent:
+			conds[i].Type = lru.TxnAbsent
+		case model.TxnCondVersion:
+			conds[i].Type = lru.TxnVersion
+		default:
+			return nil, fmt.Errorf("условие %d: неизвестный тип условия %q", i, cond.Cond)
+		}
+	}
+
+	ops := make([]lru.TxnOp[string, interface{}], len(data.Ops))
+	for i, op := range data.Ops {
+		ops[i] = lru.TxnOp[string, interface{}]{Key: op.Data.Key, TTL: op.Data.TTL}
+		switch op.Op {
+		case model.TxnOpPut:
+			ops[i].Type = lru.TxnPut
+			ops[i].Value = op.Data.Value
+			ops[i].Options = putOptions(op.Data)
+		case model.TxnOpEvict:
+			ops[i].Type = lru.TxnEvict
+		case model.TxnOpIncr:
+			if _, ok := toNumber(op.Delta); !ok {
+				return nil, &def.TxnError{Cond: -1, Op: i, Err: fmt.Errorf("%w: delta %T", def.ErrNotNumeric, op.Delta)}
+			}
+			ops[i].Type = lru.TxnUpdate
+			ops[i].Update = incrFunc(op.Delta)
+		default:
+			return nil, fmt.Errorf("операция %d: неизвестный тип операции %q", i, op.Op)
+		}
+	}
+
+	txnResults, err := c.cache.Txn(conds, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.EntryResult, len(txnResults))
+	for i, result := range txnResults {
+		results[i] = model.EntryResult{
+			Entry: model.EntryGetData{
+				Key:       data.Ops[i].Data.Key,
+				Value:     result.Item.Value,
+				ExpiresAt: result.Item.ExpiresAt,
+				Version:   result.Item.Version,
+			},
+			Err: result.Err,
+		}
+	}
+
+	return results, nil
+}
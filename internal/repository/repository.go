@@ -3,19 +3,113 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+var (
+	// ErrNotFound возвращается, если записи с указанным ключом нет в кэше
+	ErrNotFound = lru.ErrNotFound
+	// ErrExpired возвращается, если запись с указанным ключом истекла. Оборачивает ErrNotFound.
+	ErrExpired = lru.ErrExpired
+	// ErrExists возвращается PutIfAbsent, если запись с указанным ключом уже есть в кэше
+	ErrExists = lru.ErrExists
+	// ErrVersionMismatch возвращается CompareAndSwap, если версия записи отличается от ожидаемой
+	ErrVersionMismatch = lru.ErrVersionMismatch
+	// ErrClosed возвращается операциями над кэшем после его закрытия
+	ErrClosed = lru.ErrClosed
+	// ErrTooLarge возвращается при попытке записать значение, размер которого превышает лимит кэша
+	ErrTooLarge = lru.ErrTooLarge
+	// ErrRejected возвращается при записи новой записи в переполненный кэш, если фильтр допуска
+	// счел ее менее популярной, чем запись, которую пришлось бы вытеснить
+	ErrRejected = lru.ErrRejected
+	// ErrConditionFailed возвращается Txn (в составе TxnError), если условие транзакции не выполнено
+	ErrConditionFailed = lru.ErrConditionFailed
+	// ErrNotNumeric возвращается Incr, если текущее значение записи или приращение не является числом
+	ErrNotNumeric = errors.New("repository: value is not a number")
+	// ErrOverflow возвращается Incr, если сумма целых значения записи и приращения не помещается в int64,
+	// а сумма дробных не является конечным числом
+	ErrOverflow = errors.New("repository: numeric overflow")
+	// ErrInvalidCursor возвращается Scan, если курсор не получен от предыдущего вызова Scan или порядок обхода неизвестен
+	ErrInvalidCursor = errors.New("repository: invalid scan cursor")
+	// ErrInvalidSnapshot возвращается Restore, если снимок поврежден или записан в неизвестном формате
+	ErrInvalidSnapshot = errors.New("repository: invalid snapshot")
+	// ErrInvalidLog возвращается OpenLog, если журнал изменений поврежден, записан в неизвестном формате
+	// или параметры журнала некорректны
+	ErrInvalidLog = errors.New("repository: invalid operation log")
 )
 
+// TxnError описывает причину отмены транзакции: номер невыполненного условия или операции и ошибку
+type TxnError = lru.TxnError
+
+// Loader загружает значение по ключу при промахе кэша и возвращает его вместе с TTL для записи в кэш.
+// Нулевой TTL означает TTL по умолчанию.
+type Loader func(ctx context.Context, key string) (value interface{}, ttl time.Duration, err error)
+
 // ILRUCache интерфейс LRU-кэша. Поддерживает только строковые ключи. Поддерживает только простые типы данных в значениях.
 type ILRUCache interface {
-	// Put запись данных в кэш
-	Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// Put запись данных в кэш, возвращает версию записанной записи
+	Put(ctx context.Context, data model.EntryPutData) (version uint64, err error)
+	// CompareAndSwap запись данных в кэш, только если текущая версия записи равна expectedVersion
+	CompareAndSwap(ctx context.Context, data model.EntryPutData, expectedVersion uint64) (version uint64, err error)
+	// PutIfAbsent запись данных в кэш, только если записи с таким ключом нет (или она истекла)
+	PutIfAbsent(ctx context.Context, data model.EntryPutData) (version uint64, err error)
+	// Replace запись данных в кэш, только если запись с таким ключом уже есть
+	Replace(ctx context.Context, data model.EntryPutData) (version uint64, err error)
+	// Incr атомарное увеличение числового значения записи на delta (int64 или float64).
+	// Отсутствующая запись создается с TTL ttl и значением delta. Возвращает запись с новым значением.
+	Incr(ctx context.Context, key string, delta interface{}, ttl time.Duration) (model.EntryGetData, error)
 	// Get получение данных из кэша по ключу
-	Get(ctx context.Context, key string) (value interface{}, expiresAt time.Time, err error)
+	Get(ctx context.Context, key string) (model.EntryGetData, error)
+	// MGet получение данных из кэша по нескольким ключам. Результаты располагаются на позициях соответствующих ключей,
+	// для промахов содержат ErrNotFound или ErrExpired.
+	MGet(ctx context.Context, keys []string) ([]model.EntryResult, error)
+	// MPut запись нескольких значений в кэш. Результаты располагаются на позициях соответствующих записей.
+	MPut(ctx context.Context, data []model.EntryPutData) ([]model.EntryResult, error)
+	// MDelete удаление данных по нескольким ключам. Результаты содержат удаленные значения или ошибки удаления.
+	MDelete(ctx context.Context, keys []string) ([]model.EntryResult, error)
+	// Txn атомарное выполнение транзакции: проверка условий и операции по порядку, все или ни одной.
+	// Результаты располагаются на позициях соответствующих операций; при отмене возвращается *TxnError.
+	Txn(ctx context.Context, data model.TxnData) ([]model.EntryResult, error)
+	// GetOrLoad получение данных из кэша по ключу, а при промахе - загрузка через loader и запись в кэш.
+	// Одновременные промахи по одному ключу ожидают одну загрузку.
+	GetOrLoad(ctx context.Context, key string, loader Loader) (model.EntryGetData, error)
+	// SetLoader регистрация загрузчика для фонового обновления записей (refresh-ahead и stale_ttl)
+	SetLoader(loader Loader)
 	// GetAll получение всего наполнения кэша в виде двух слайсов: слайса ключей и слайса значений. Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
 	GetAll(ctx context.Context) (keys []string, values []interface{}, err error)
+	// Scan постраничный обход кэша: не более limit записей в порядке order (model.ScanOrderLRU или model.ScanOrderMRU),
+	// следующих за позицией cursor (пустая строка - начало обхода). Курсор остается действительным при вытеснении записей.
+	Scan(ctx context.Context, cursor string, limit int, order string) (model.ScanPage, error)
+	// ScanPrefix получение записей, ключи которых начинаются с prefix, в виде двух слайсов, как в GetAll
+	ScanPrefix(ctx context.Context, prefix string) (keys []string, values []interface{}, err error)
 	// Evict ручное удаление данных по ключу
 	Evict(ctx context.Context, key string) (value interface{}, err error)
+	// EvictPrefix удаление всех записей, ключи которых начинаются с prefix, возвращает количество удаленных записей
+	EvictPrefix(ctx context.Context, prefix string) (n int, err error)
+	// EvictByTag удаление всех записей, помеченных тегом tag, возвращает количество удаленных записей
+	EvictByTag(ctx context.Context, tag string) (n int, err error)
 	// EvictAll ручная инвалидация всего кэша
 	EvictAll(ctx context.Context) error
+	// Stats получение текущего заполнения кэша
+	Stats(ctx context.Context) (model.CacheStats, error)
+	// Snapshot запись содержимого кэша в w (ключи, значения, даты истечения, параметры записей и порядок использования),
+	// возвращает количество записанных записей
+	Snapshot(ctx context.Context, w io.Writer) (n int, err error)
+	// Restore загрузка содержимого кэша из снимка, записанного Snapshot. Записи, срок которых прошел, пропускаются.
+	// Возвращает количество восстановленных записей.
+	Restore(ctx context.Context, r io.Reader) (n int, err error)
+	// OpenLog включение журнала изменений: изменения из существующего журнала применяются поверх текущего
+	// содержимого кэша, журнал переписывается из получившегося содержимого, и далее в него дописываются
+	// все изменения кэша. Возвращает количество примененных изменений.
+	OpenLog(ctx context.Context, cfg model.OpLogConfig) (n int, err error)
+	// CompactLog переписывает журнал изменений из текущего содержимого кэша, если журнал вырос вдвое
+	// с прошлого сжатия и превысил минимальный размер. Возвращает, было ли выполнено сжатие.
+	CompactLog(ctx context.Context) (compacted bool, err error)
+	// Close останавливает фоновые процессы кэша, после чего операции над ним возвращают ErrClosed
+	Close(ctx context.Context) error
 }
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+)
+
+// MGet обеспечивает получение данных из кэша по нескольким ключам.
+// Промахи возвращаются в результатах соответствующих ключей и не считаются ошибкой.
+func (s *service) MGet(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	for _, key := range keys {
+		if len(key) == 0 {
+			log.Error().Msg("некорректные данные для пакетного получения записей из кэша")
+			return nil, fmt.Errorf("некорректные входные данные")
+		}
+	}
+
+	results, err := s.cacheRepository.MGet(ctx, keys)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка пакетного получения записей из кэша")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// MPut обеспечивает запись нескольких значений в кэш.
+// Ошибки записи отдельных значений возвращаются в результатах соответствующих записей.
+func (s *service) MPut(ctx context.Context, data []model.EntryPutData) ([]model.EntryResult, error) {
+	for _, d := range data {
+		if err := d.Validate(); err != nil {
+			log.Error().Err(err).Msg("некорректные данные для пакетной записи в кэш")
+			return nil, err
+		}
+	}
+
+	results, err := s.cacheRepository.MPut(ctx, data)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка пакетной записи в кэш")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// MDelete обеспечивает удаление данных по нескольким ключам.
+// Отсутствующие ключи возвращаются в результатах соответствующих ключей и не считаются ошибкой.
+func (s *service) MDelete(ctx context.Context, keys []string) ([]model.EntryResult, error) {
+	for _, key := range keys {
+		if len(key) == 0 {
+			log.Error().Msg("некорректные данные для пакетного удаления записей из кэша")
+			return nil, fmt.Errorf("некорректные входные данные")
+		}
+	}
+
+	results, err := s.cacheRepository.MDelete(ctx, keys)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка пакетного удаления записей из кэша")
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// CompareAndSwap обеспечивает запись данных в кэш, только если текущая версия записи равна
+// expectedVersion. Возвращает версию записанной записи; при несовпадении версии -
+// repository.ErrVersionMismatch, для отсутствующих и истекших записей - repository.ErrNotFound
+// и repository.ErrExpired.
+func (s *service) CompareAndSwap(ctx context.Context, data model.EntryPutData, expectedVersion uint64) (version uint64, err error) {
+	if err := data.Validate(); err != nil {
+		log.Error().Err(err).Msg("некорректные данные для условной записи в кэш")
+		return 0, err
+	}
+
+	version, err = s.cacheRepository.CompareAndSwap(ctx, data, expectedVersion)
+	if errors.Is(err, repository.ErrVersionMismatch) || errors.Is(err, repository.ErrNotFound) {
+		log.Debug().Err(err).Msg("условие записи в кэш не выполнено")
+		return 0, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка условной записи в кэш")
+		return 0, err
+	}
+
+	return version, nil
+}
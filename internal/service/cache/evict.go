@@ -2,13 +2,20 @@ package cache
 
 import (
 	"context"
+	"errors"
 
 	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 )
 
-// Evict обеспечивает ручное удаление данных по ключу
+// Evict обеспечивает ручное удаление данных по ключу.
+// Для отсутствующих записей возвращает repository.ErrNotFound, для истекших - repository.ErrExpired.
 func (s *service) Evict(ctx context.Context, key string) (value interface{}, err error) {
 	value, err = s.cacheRepository.Evict(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Debug().Err(err).Msg("запись для удаления не найдена в кэше")
+		return nil, err
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("ошибка удаления записи из кэша")
 		return nil, err
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EvictByTag обеспечивает удаление всех записей, помеченных тегом tag
+func (s *service) EvictByTag(ctx context.Context, tag string) (n int, err error) {
+	if len(tag) == 0 {
+		log.Error().Msg("некорректный тег для удаления записей из кэша")
+		return 0, fmt.Errorf("некорректные входные данные")
+	}
+
+	n, err = s.cacheRepository.EvictByTag(ctx, tag)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка удаления записей кэша по тегу")
+		return 0, err
+	}
+
+	return n, nil
+}
@@ -2,18 +2,25 @@ package cache
 
 import (
 	"context"
-	"time"
+	"errors"
 
 	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
 )
 
-// Get обеспечивает получение данных из кэша по ключу
-func (s *service) Get(ctx context.Context, key string) (value interface{}, expiresAt time.Time, err error) {
-	value, expiresAt, err = s.cacheRepository.Get(ctx, key)
+// Get обеспечивает получение данных из кэша по ключу.
+// Для отсутствующих записей возвращает repository.ErrNotFound, для истекших - repository.ErrExpired.
+func (s *service) Get(ctx context.Context, key string) (model.EntryGetData, error) {
+	entry, err := s.cacheRepository.Get(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Debug().Err(err).Msg("запись не найдена в кэше")
+		return model.EntryGetData{}, err
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("ошибка получения записи из кэша")
-		return nil, time.Time{}, err
+		return model.EntryGetData{}, err
 	}
 
-	return value, expiresAt, nil
+	return entry, nil
 }
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// GetOrLoad обеспечивает получение данных из кэша по ключу, а при промахе - загрузку через loader
+// и запись в кэш. Одновременные промахи по одному ключу ожидают одну загрузку.
+func (s *service) GetOrLoad(ctx context.Context, key string, loader repository.Loader) (model.EntryGetData, error) {
+	if len(key) == 0 || loader == nil {
+		log.Error().Msg("некорректные данные для получения или загрузки записи кэша")
+		return model.EntryGetData{}, fmt.Errorf("некорректные входные данные")
+	}
+
+	entry, err := s.cacheRepository.GetOrLoad(ctx, key, loader)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка получения или загрузки записи кэша")
+		return model.EntryGetData{}, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// Incr обеспечивает атомарное увеличение числового значения записи на delta.
+// Для пустого ключа или отрицательного TTL возвращает model.ErrInvalidEntry, для нечисловых значений -
+// repository.ErrNotNumeric, при переполнении - repository.ErrOverflow.
+func (s *service) Incr(ctx context.Context, key string, delta interface{}, ttl time.Duration) (model.EntryGetData, error) {
+	if err := (model.EntryPutData{Key: key, TTL: ttl}).Validate(); err != nil {
+		log.Error().Err(err).Msg("некорректные данные для увеличения значения в кэше")
+		return model.EntryGetData{}, err
+	}
+
+	entry, err := s.cacheRepository.Incr(ctx, key, delta, ttl)
+	if errors.Is(err, repository.ErrNotNumeric) || errors.Is(err, repository.ErrOverflow) {
+		log.Debug().Err(err).Msg("значение записи в кэше нельзя увеличить")
+		return model.EntryGetData{}, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка увеличения значения в кэше")
+		return model.EntryGetData{}, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+)
+
+// OpenLog обеспечивает включение журнала изменений кэша
+func (s *service) OpenLog(ctx context.Context, cfg model.OpLogConfig) (n int, err error) {
+	n, err = s.cacheRepository.OpenLog(ctx, cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка открытия журнала изменений " + cfg.Path)
+		return n, err
+	}
+
+	return n, nil
+}
+
+// CompactLog обеспечивает сжатие журнала изменений кэша
+func (s *service) CompactLog(ctx context.Context) (compacted bool, err error) {
+	compacted, err = s.cacheRepository.CompactLog(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка сжатия журнала изменений")
+		return compacted, err
+	}
+
+	return compacted, nil
+}
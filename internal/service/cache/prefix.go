@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ScanPrefix обеспечивает получение записей, ключи которых начинаются с prefix, в виде двух слайсов:
+// слайса ключей и слайса значений.
+func (s *service) ScanPrefix(ctx context.Context, prefix string) (keys []string, values []interface{}, err error) {
+	keys, values, err = s.cacheRepository.ScanPrefix(ctx, prefix)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка получения записей кэша по префиксу")
+		return nil, nil, err
+	}
+
+	return keys, values, nil
+}
+
+// EvictPrefix обеспечивает удаление всех записей, ключи которых начинаются с prefix
+func (s *service) EvictPrefix(ctx context.Context, prefix string) (n int, err error) {
+	n, err = s.cacheRepository.EvictPrefix(ctx, prefix)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка удаления записей кэша по префиксу")
+		return 0, err
+	}
+
+	return n, nil
+}
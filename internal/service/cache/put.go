@@ -2,24 +2,23 @@ package cache
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
 )
 
-// Put обеспечивает запись данных в кэш
-func (s *service) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	if len(key) == 0 || ttl < 0 {
-		log.Error().Msg("некорректные данные для добавления в кэш")
-		return fmt.Errorf("некорректные входные данные")
+// Put обеспечивает запись данных в кэш и возвращает версию записанной записи
+func (s *service) Put(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	if err := data.Validate(); err != nil {
+		log.Error().Err(err).Msg("некорректные данные для добавления в кэш")
+		return 0, err
 	}
 
-	err := s.cacheRepository.Put(ctx, key, value, ttl)
+	version, err = s.cacheRepository.Put(ctx, data)
 	if err != nil {
 		log.Error().Err(err).Msg("ошибка добавления в кэш")
-		return err
+		return 0, err
 	}
 
-	return nil
+	return version, nil
 }
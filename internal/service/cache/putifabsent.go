@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// PutIfAbsent обеспечивает запись данных в кэш, только если записи с таким ключом нет (или она истекла).
+// Возвращает версию записанной записи или repository.ErrExists.
+func (s *service) PutIfAbsent(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	if err := data.Validate(); err != nil {
+		log.Error().Err(err).Msg("некорректные данные для добавления в кэш")
+		return 0, err
+	}
+
+	version, err = s.cacheRepository.PutIfAbsent(ctx, data)
+	if errors.Is(err, repository.ErrExists) {
+		log.Debug().Err(err).Msg("запись уже есть в кэше")
+		return 0, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка добавления в кэш")
+		return 0, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// Replace обеспечивает запись данных в кэш, только если запись с таким ключом уже есть.
+// Возвращает версию записанной записи или repository.ErrNotFound, repository.ErrExpired.
+func (s *service) Replace(ctx context.Context, data model.EntryPutData) (version uint64, err error) {
+	if err := data.Validate(); err != nil {
+		log.Error().Err(err).Msg("некорректные данные для замены записи в кэше")
+		return 0, err
+	}
+
+	version, err = s.cacheRepository.Replace(ctx, data)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Debug().Err(err).Msg("запись для замены не найдена в кэше")
+		return 0, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка замены записи в кэше")
+		return 0, err
+	}
+
+	return version, nil
+}
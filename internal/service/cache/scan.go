@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+)
+
+// Scan обеспечивает постраничный обход кэша: страницу не более чем из limit записей в порядке order,
+// следующих за позицией cursor, и курсор следующей страницы
+func (s *service) Scan(ctx context.Context, cursor string, limit int, order string) (model.ScanPage, error) {
+	page, err := s.cacheRepository.Scan(ctx, cursor, limit, order)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка постраничного обхода кэша")
+		return model.ScanPage{}, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,10 @@
+package cache
+
+import (
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// SetLoader регистрирует загрузчик, которым записи с refresh_ahead и stale_ttl обновляются в фоне
+func (s *service) SetLoader(loader repository.Loader) {
+	s.cacheRepository.SetLoader(loader)
+}
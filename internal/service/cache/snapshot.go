@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"io"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Snapshot обеспечивает запись снимка содержимого кэша в w
+func (s *service) Snapshot(ctx context.Context, w io.Writer) (n int, err error) {
+	n, err = s.cacheRepository.Snapshot(ctx, w)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка записи снимка кэша")
+		return n, err
+	}
+
+	return n, nil
+}
+
+// Restore обеспечивает загрузку содержимого кэша из снимка
+func (s *service) Restore(ctx context.Context, r io.Reader) (n int, err error) {
+	n, err = s.cacheRepository.Restore(ctx, r)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка загрузки снимка кэша")
+		return n, err
+	}
+
+	return n, nil
+}
@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+)
+
+// Stats обеспечивает получение текущего заполнения кэша
+func (s *service) Stats(ctx context.Context) (model.CacheStats, error) {
+	stats, err := s.cacheRepository.Stats(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка получения статистики кэша")
+		return model.CacheStats{}, err
+	}
+
+	return stats, nil
+}
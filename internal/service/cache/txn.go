@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// Txn обеспечивает атомарное выполнение транзакции.
+// Если условие не выполнено или операция завершилась ошибкой, возвращает *repository.TxnError.
+func (s *service) Txn(ctx context.Context, data model.TxnData) ([]model.EntryResult, error) {
+	for i, op := range data.Ops {
+		if err := op.Data.Validate(); err != nil {
+			log.Error().Err(err).Msg("некорректные данные для транзакции в кэше")
+			return nil, fmt.Errorf("операция %d: %w", i, err)
+		}
+	}
+
+	results, err := s.cacheRepository.Txn(ctx, data)
+	var txnErr *repository.TxnError
+	if errors.As(err, &txnErr) {
+		log.Debug().Err(err).Msg("транзакция в кэше отменена")
+		return nil, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка выполнения транзакции в кэше")
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,177 @@
+// Package namespace содержит определение структур и логику работы сервисного слоя пространств имен:
+// именованных кэшей golang-cahe-lru, у каждого из которых свои параметры.
+package namespace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+	def "github.com/vitbogit/golang-cache-lru/internal/service"
+	cacheService "github.com/vitbogit/golang-cache-lru/internal/service/cache"
+)
+
+var _ def.NamespaceService = (*service)(nil)
+
+// namePattern задает допустимые имена пространств имен (используются в маршрутах API)
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Factory создает кэш пространства имен с параметрами cfg (нулевые значения наследуются от кэша
+// по умолчанию) и возвращает его вместе с итоговыми параметрами
+type Factory func(cfg model.NamespaceConfig) (repository.ILRUCache, model.NamespaceConfig, error)
+
+// namespace задает пространство имен
+type namespace struct {
+	cfg             model.NamespaceConfig // Итоговые параметры кэша
+	cacheRepository repository.ILRUCache  // Кэш пространства имен
+	cacheService    def.CacheService      // Сервис кэша пространства имен
+}
+
+// service структура сервиса пространств имен
+type service struct {
+	mu         sync.RWMutex
+	factory    Factory
+	hooks      def.NamespaceHooks
+	namespaces map[string]*namespace
+}
+
+// NewService создает новый сервис пространств имен. Пространство имен по умолчанию (def.DefaultNamespace)
+// использует уже созданные кэш defaultRepository с параметрами defaultCfg и сервис defaultService,
+// остальные создаются через factory.
+func NewService(
+	defaultService def.CacheService,
+	defaultRepository repository.ILRUCache,
+	defaultCfg model.NamespaceConfig,
+	factory Factory,
+) *service {
+	return &service{
+		factory: factory,
+		namespaces: map[string]*namespace{
+			def.DefaultNamespace: {
+				cfg:             defaultCfg,
+				cacheRepository: defaultRepository,
+				cacheService:    defaultService,
+			},
+		},
+	}
+}
+
+// Namespace возвращает сервис кэша пространства имен по имени
+func (s *service) Namespace(_ context.Context, name string) (def.CacheService, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ns, ok := s.namespaces[name]
+	if !ok {
+		return nil, def.ErrNamespaceNotFound
+	}
+	return ns.cacheService, nil
+}
+
+// SetHooks задает действия при создании и удалении пространств имен. Действия применяются
+// к пространствам имен, создаваемым и удаляемым после вызова.
+func (s *service) SetHooks(hooks def.NamespaceHooks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = hooks
+}
+
+// Create создает пространство имен с собственным кэшем. Действие OnCreate (см. SetHooks) выполняется
+// до того, как пространство имен станет доступно, поэтому все его изменения попадают, например, в журнал.
+func (s *service) Create(ctx context.Context, name string, cfg model.NamespaceConfig) (model.NamespaceInfo, error) {
+	if !namePattern.MatchString(name) {
+		return model.NamespaceInfo{}, fmt.Errorf("%w: name must match %s", def.ErrInvalidNamespace, namePattern)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.namespaces[name]; ok {
+		return model.NamespaceInfo{}, def.ErrNamespaceExists
+	}
+
+	repo, cfg, err := s.factory(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("ошибка создания кэша пространства имен " + name)
+		return model.NamespaceInfo{}, fmt.Errorf("%w: %v", def.ErrInvalidNamespace, err)
+	}
+
+	ns := &namespace{
+		cfg:             cfg,
+		cacheRepository: repo,
+		cacheService:    cacheService.NewService(repo),
+	}
+	info := model.NamespaceInfo{Name: name, Config: cfg}
+	if s.hooks.OnCreate != nil {
+		s.hooks.OnCreate(ctx, info, ns.cacheService)
+	}
+	s.namespaces[name] = ns
+	log.Info().Msg("создано пространство имен " + name)
+
+	return info, nil
+}
+
+// Delete удаляет пространство имен, закрывает его кэш и выполняет действие OnDelete (см. SetHooks). Запросы, уже получившие сервис
+// удаленного пространства имен, завершаются с ошибкой repository.ErrClosed.
+func (s *service) Delete(ctx context.Context, name string) error {
+	if name == def.DefaultNamespace {
+		return def.ErrDefaultNamespace
+	}
+
+	// Кэш закрывается, а OnDelete выполняется под блокировкой, чтобы одновременное создание пространства
+	// имен с тем же именем не началось, пока, например, журнал удаленного не удален
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.namespaces[name]
+	if !ok {
+		return def.ErrNamespaceNotFound
+	}
+	delete(s.namespaces, name)
+
+	if err := ns.cacheRepository.Close(ctx); err != nil {
+		log.Error().Err(err).Msg("ошибка закрытия кэша пространства имен " + name)
+		return err
+	}
+	if s.hooks.OnDelete != nil {
+		s.hooks.OnDelete(ctx, name)
+	}
+	log.Info().Msg("удалено пространство имен " + name)
+
+	return nil
+}
+
+// List возвращает все пространства имен, упорядоченные по имени
+func (s *service) List(_ context.Context) ([]model.NamespaceInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]model.NamespaceInfo, 0, len(s.namespaces))
+	for name, ns := range s.namespaces {
+		infos = append(infos, model.NamespaceInfo{Name: name, Config: ns.cfg})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos, nil
+}
+
+// Close закрывает кэши всех пространств имен, включая пространство имен по умолчанию
+func (s *service) Close(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var errs []error
+	for name, ns := range s.namespaces {
+		if err := ns.cacheRepository.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
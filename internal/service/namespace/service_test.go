@@ -0,0 +1,295 @@
+package namespace
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vitbogit/golang-cache-lru/internal/config"
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+	cacheRepository "github.com/vitbogit/golang-cache-lru/internal/repository/cache"
+	def "github.com/vitbogit/golang-cache-lru/internal/service"
+	cacheService "github.com/vitbogit/golang-cache-lru/internal/service/cache"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru"
+)
+
+// baseConfig конфиг кэша по умолчанию, от которого наследуют пространства имен
+type baseConfig struct {
+	size, shards int
+	maxBytes     int64
+}
+
+func (cfg baseConfig) Size() int                                 { return cfg.size }
+func (cfg baseConfig) DefaultTTL() time.Duration                 { return time.Minute }
+func (cfg baseConfig) Shards() int                               { return cfg.shards }
+func (cfg baseConfig) JanitorInterval() time.Duration            { return time.Second }
+func (cfg baseConfig) JanitorBudget() int                        { return 10 }
+func (cfg baseConfig) MaxBytes() int64                           { return cfg.maxBytes }
+func (cfg baseConfig) EvictionPolicy() string                    { return "lru" }
+func (cfg baseConfig) Admission() bool                           { return false }
+func (cfg baseConfig) PromoteOnRead() bool                       { return true }
+func (cfg baseConfig) LoaderTimeout() time.Duration              { return 0 }
+func (cfg baseConfig) StorageEngine() string                     { return "heap" }
+func (cfg baseConfig) Namespaces() map[string]config.CacheConfig { return nil }
+
+// newTestService создает сервис пространств имен, кэши которого создаются так же, как в приложении:
+// параметры собираются config.NewNamespaceCacheConfig от конфига base
+func newTestService(t *testing.T, base config.CacheConfig) (*service, repository.ILRUCache) {
+	t.Helper()
+
+	defaultRepo, err := cacheRepository.NewCache(base.Size(), base.DefaultTTL(), lru.WithShards(base.Shards()))
+	require.NoError(t, err)
+
+	factory := func(nsCfg model.NamespaceConfig) (repository.ILRUCache, model.NamespaceConfig, error) {
+		cfg, err := config.NewNamespaceCacheConfig(base, config.CacheOverrides{
+			Size:           nsCfg.Size,
+			DefaultTTL:     nsCfg.DefaultTTL,
+			Shards:         nsCfg.Shards,
+			MaxBytes:       nsCfg.MaxBytes,
+			EvictionPolicy: nsCfg.EvictionPolicy,
+		})
+		if err != nil {
+			return nil, model.NamespaceConfig{}, err
+		}
+		repo, err := cacheRepository.NewCache(cfg.Size(), cfg.DefaultTTL(), lru.WithShards(cfg.Shards()))
+		if err != nil {
+			return nil, model.NamespaceConfig{}, err
+		}
+		return repo, model.NamespaceConfig{
+			Size:           cfg.Size(),
+			DefaultTTL:     cfg.DefaultTTL(),
+			Shards:         cfg.Shards(),
+			MaxBytes:       cfg.MaxBytes(),
+			EvictionPolicy: cfg.EvictionPolicy(),
+		}, nil
+	}
+
+	defaultCfg := model.NamespaceConfig{Size: base.Size(), DefaultTTL: base.DefaultTTL(), Shards: base.Shards(), EvictionPolicy: base.EvictionPolicy()}
+	s := NewService(cacheService.NewService(defaultRepo), defaultRepo, defaultCfg, factory)
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+	return s, defaultRepo
+}
+
+func TestService_CreateListDelete(t *testing.T) {
+	s, _ := newTestService(t, baseConfig{size: 100, shards: 4})
+	ctx := context.Background()
+
+	info, err := s.Create(ctx, "team-a", model.NamespaceConfig{Size: 50, EvictionPolicy: "lfu"})
+	require.NoError(t, err)
+	assert.Equal(t, model.NamespaceInfo{
+		Name:   "team-a",
+		Config: model.NamespaceConfig{Size: 50, DefaultTTL: time.Minute, Shards: 4, EvictionPolicy: "lfu"},
+	}, info)
+
+	// Кэш пространства имен независим от кэша по умолчанию
+	ns, err := s.Namespace(ctx, "team-a")
+	require.NoError(t, err)
+	_, err = ns.Put(ctx, model.EntryPutData{Key: "a", Value: "x"})
+	require.NoError(t, err)
+	defaultNs, err := s.Namespace(ctx, def.DefaultNamespace)
+	require.NoError(t, err)
+	_, err = defaultNs.Get(ctx, "a")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	infos, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, def.DefaultNamespace, infos[0].Name)
+	assert.Equal(t, "team-a", infos[1].Name)
+
+	// Удаленное пространство имен пропадает из списка, а его кэш закрывается
+	require.NoError(t, s.Delete(ctx, "team-a"))
+	_, err = s.Namespace(ctx, "team-a")
+	assert.ErrorIs(t, err, def.ErrNamespaceNotFound)
+	_, err = ns.Get(ctx, "a")
+	assert.ErrorIs(t, err, repository.ErrClosed)
+	assert.ErrorIs(t, s.Delete(ctx, "team-a"), def.ErrNamespaceNotFound)
+
+	infos, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, infos, 1)
+
+	// Имя удаленного пространства имен можно занять снова
+	_, err = s.Create(ctx, "team-a", model.NamespaceConfig{})
+	assert.NoError(t, err)
+}
+
+func TestService_CreateInvalid(t *testing.T) {
+	s, _ := newTestService(t, baseConfig{size: 100, shards: 4})
+	ctx := context.Background()
+
+	_, err := s.Create(ctx, "team-a", model.NamespaceConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		nsName  string
+		cfg     model.NamespaceConfig
+		wantErr error
+	}{
+		{"duplicate", "team-a", model.NamespaceConfig{}, def.ErrNamespaceExists},
+		{"default", def.DefaultNamespace, model.NamespaceConfig{}, def.ErrNamespaceExists},
+		{"empty name", "", model.NamespaceConfig{}, def.ErrInvalidNamespace},
+		{"slash in name", "team/a", model.NamespaceConfig{}, def.ErrInvalidNamespace},
+		{"long name", string(make([]byte, 65)), model.NamespaceConfig{}, def.ErrInvalidNamespace},
+		{"negative size", "team-b", model.NamespaceConfig{Size: -1}, def.ErrInvalidNamespace},
+		{"shards exceed size", "team-b", model.NamespaceConfig{Size: 2, Shards: 3}, def.ErrInvalidNamespace},
+		{"unknown policy", "team-b", model.NamespaceConfig{EvictionPolicy: "fifo"}, def.ErrInvalidNamespace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Create(ctx, tt.nsName, tt.cfg)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	infos, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, infos, 2)
+}
+
+func TestService_DeleteDefault(t *testing.T) {
+	s, defaultRepo := newTestService(t, baseConfig{size: 10, shards: 1})
+	ctx := context.Background()
+
+	assert.ErrorIs(t, s.Delete(ctx, def.DefaultNamespace), def.ErrDefaultNamespace)
+
+	// Кэш по умолчанию остается открытым
+	_, err := defaultRepo.Put(ctx, model.EntryPutData{Key: "a", Value: "x"})
+	assert.NoError(t, err)
+}
+
+func TestService_Close(t *testing.T) {
+	s, defaultRepo := newTestService(t, baseConfig{size: 10, shards: 1})
+	ctx := context.Background()
+
+	_, err := s.Create(ctx, "team-a", model.NamespaceConfig{})
+	require.NoError(t, err)
+	ns, err := s.Namespace(ctx, "team-a")
+	require.NoError(t, err)
+
+	// Закрываются кэши всех пространств имен, включая пространство имен по умолчанию
+	require.NoError(t, s.Close(ctx))
+	_, err = defaultRepo.Get(ctx, "a")
+	assert.ErrorIs(t, err, repository.ErrClosed)
+	_, err = ns.Get(ctx, "a")
+	assert.ErrorIs(t, err, repository.ErrClosed)
+}
+
+func TestService_ConfigInheritance(t *testing.T) {
+	s, _ := newTestService(t, baseConfig{size: 100, shards: 8, maxBytes: 4096})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		cfg  model.NamespaceConfig
+		want model.NamespaceConfig
+	}{
+		{
+			"all inherited",
+			model.NamespaceConfig{},
+			model.NamespaceConfig{Size: 100, DefaultTTL: time.Minute, Shards: 8, MaxBytes: 4096, EvictionPolicy: "lru"},
+		},
+		{
+			"all overridden",
+			model.NamespaceConfig{Size: 20, DefaultTTL: time.Hour, Shards: 2, MaxBytes: 100, EvictionPolicy: "arc"},
+			model.NamespaceConfig{Size: 20, DefaultTTL: time.Hour, Shards: 2, MaxBytes: 100, EvictionPolicy: "arc"},
+		},
+		{
+			// Унаследованное количество шардов уменьшается до размера кэша
+			"inherited shards clamped to size",
+			model.NamespaceConfig{Size: 3},
+			model.NamespaceConfig{Size: 3, DefaultTTL: time.Minute, Shards: 3, MaxBytes: 4096, EvictionPolicy: "lru"},
+		},
+		{
+			"inherited shards below size",
+			model.NamespaceConfig{Size: 8},
+			model.NamespaceConfig{Size: 8, DefaultTTL: time.Minute, Shards: 8, MaxBytes: 4096, EvictionPolicy: "lru"},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := s.Create(ctx, "ns"+string(rune('a'+i)), tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info.Config)
+		})
+	}
+
+	// Явно заданное количество шардов не уменьшается
+	_, err := s.Create(ctx, "explicit", model.NamespaceConfig{Size: 3, Shards: 4})
+	assert.ErrorIs(t, err, def.ErrInvalidNamespace)
+}
+
+func TestService_Hooks(t *testing.T) {
+	s, _ := newTestService(t, baseConfig{size: 10, shards: 1})
+	ctx := context.Background()
+
+	var created, deleted []string
+	s.SetHooks(def.NamespaceHooks{
+		OnCreate: func(ctx context.Context, info model.NamespaceInfo, cacheService def.CacheService) {
+			// Кэш пространства имен уже можно использовать
+			_, err := cacheService.Put(ctx, model.EntryPutData{Key: "a", Value: "x"})
+			assert.NoError(t, err)
+			assert.Equal(t, 10, info.Config.Size)
+			created = append(created, info.Name)
+		},
+		OnDelete: func(ctx context.Context, name string) {
+			deleted = append(deleted, name)
+		},
+	})
+	_, err := s.Create(ctx, "team-a", model.NamespaceConfig{})
+	require.NoError(t, err)
+	ns, err := s.Namespace(ctx, "team-a")
+	require.NoError(t, err)
+	_, err = ns.Get(ctx, "a")
+	assert.NoError(t, err)
+
+	// Неудачное создание и удаление не вызывают действий
+	_, err = s.Create(ctx, "team-a", model.NamespaceConfig{})
+	require.ErrorIs(t, err, def.ErrNamespaceExists)
+	require.ErrorIs(t, s.Delete(ctx, "team-b"), def.ErrNamespaceNotFound)
+
+	require.NoError(t, s.Delete(ctx, "team-a"))
+	assert.Equal(t, []string{"team-a"}, created)
+	assert.Equal(t, []string{"team-a"}, deleted)
+}
+
+func TestService_DeleteBlocksCreate(t *testing.T) {
+	s, _ := newTestService(t, baseConfig{size: 10, shards: 1})
+	ctx := context.Background()
+
+	// Создание пространства имен с тем же именем ждет завершения OnDelete удаленного
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	created := make(chan error)
+	s.SetHooks(def.NamespaceHooks{
+		OnCreate: func(_ context.Context, info model.NamespaceInfo, _ def.CacheService) {
+			record("create")
+		},
+		OnDelete: func(ctx context.Context, name string) {
+			go func() {
+				_, err := s.Create(ctx, name, model.NamespaceConfig{})
+				created <- err
+			}()
+			time.Sleep(50 * time.Millisecond)
+			record("delete")
+		},
+	})
+
+	_, err := s.Create(ctx, "team-a", model.NamespaceConfig{})
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(ctx, "team-a"))
+	require.NoError(t, <-created)
+	assert.Equal(t, []string{"create", "delete", "create"}, events)
+}
@@ -3,18 +3,103 @@ package service
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
+
+	"github.com/vitbogit/golang-cache-lru/internal/model"
+	"github.com/vitbogit/golang-cache-lru/internal/repository"
+)
+
+// DefaultNamespace имя пространства имен по умолчанию, доступного по маршрутам /api/lru
+const DefaultNamespace = "default"
+
+var (
+	// ErrNamespaceNotFound возвращается, если пространства имен с указанным именем нет
+	ErrNamespaceNotFound = errors.New("service: namespace not found")
+	// ErrNamespaceExists возвращается при создании пространства имен с уже занятым именем
+	ErrNamespaceExists = errors.New("service: namespace already exists")
+	// ErrInvalidNamespace возвращается при создании пространства имен с некорректным именем или параметрами кэша
+	ErrInvalidNamespace = errors.New("service: invalid namespace")
+	// ErrDefaultNamespace возвращается при попытке удалить пространство имен по умолчанию
+	ErrDefaultNamespace = errors.New("service: default namespace can not be deleted")
 )
 
 type CacheService interface {
-	// Put запись данных в кэш
-	Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// Put запись данных в кэш, возвращает версию записанной записи
+	Put(ctx context.Context, data model.EntryPutData) (version uint64, err error)
+	// CompareAndSwap запись данных в кэш, только если текущая версия записи равна expectedVersion
+	CompareAndSwap(ctx context.Context, data model.EntryPutData, expectedVersion uint64) (version uint64, err error)
+	// PutIfAbsent запись данных в кэш, только если записи с таким ключом нет (или она истекла)
+	PutIfAbsent(ctx context.Context, data model.EntryPutData) (version uint64, err error)
+	// Replace запись данных в кэш, только если запись с таким ключом уже есть
+	Replace(ctx context.Context, data model.EntryPutData) (version uint64, err error)
+	// Incr атомарное увеличение числового значения записи на delta (int64 или float64), возвращает запись с новым значением
+	Incr(ctx context.Context, key string, delta interface{}, ttl time.Duration) (model.EntryGetData, error)
 	// Get получение данных из кэша по ключу
-	Get(ctx context.Context, key string) (value interface{}, expiresAt time.Time, err error)
+	Get(ctx context.Context, key string) (model.EntryGetData, error)
+	// MGet получение данных из кэша по нескольким ключам с результатом для каждого ключа, включая промахи
+	MGet(ctx context.Context, keys []string) ([]model.EntryResult, error)
+	// MPut запись нескольких значений в кэш с результатом для каждой записи
+	MPut(ctx context.Context, data []model.EntryPutData) ([]model.EntryResult, error)
+	// MDelete удаление данных по нескольким ключам с результатом для каждого ключа
+	MDelete(ctx context.Context, keys []string) ([]model.EntryResult, error)
+	// Txn атомарное выполнение транзакции с результатом для каждой операции
+	Txn(ctx context.Context, data model.TxnData) ([]model.EntryResult, error)
+	// GetOrLoad получение данных из кэша по ключу, а при промахе - загрузка через loader и запись в кэш
+	GetOrLoad(ctx context.Context, key string, loader repository.Loader) (model.EntryGetData, error)
+	// SetLoader регистрация загрузчика для фонового обновления записей (refresh-ahead и stale_ttl)
+	SetLoader(loader repository.Loader)
 	// GetAll получение всего наполнения кэша в виде двух слайсов: слайса ключей и слайса значений. Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
 	GetAll(ctx context.Context) (keys []string, values []interface{}, err error)
+	// Scan постраничный обход кэша: страница не более чем из limit записей в порядке order и курсор следующей страницы
+	Scan(ctx context.Context, cursor string, limit int, order string) (model.ScanPage, error)
+	// ScanPrefix получение записей, ключи которых начинаются с prefix, в виде двух слайсов, как в GetAll
+	ScanPrefix(ctx context.Context, prefix string) (keys []string, values []interface{}, err error)
 	// Evict ручное удаление данных по ключу
 	Evict(ctx context.Context, key string) (value interface{}, err error)
+	// EvictPrefix удаление всех записей, ключи которых начинаются с prefix, возвращает количество удаленных записей
+	EvictPrefix(ctx context.Context, prefix string) (n int, err error)
+	// EvictByTag удаление всех записей, помеченных тегом tag, возвращает количество удаленных записей
+	EvictByTag(ctx context.Context, tag string) (n int, err error)
 	// EvictAll ручная инвалидация всего кэша
 	EvictAll(ctx context.Context) error
+	// Stats получение текущего заполнения кэша
+	Stats(ctx context.Context) (model.CacheStats, error)
+	// Snapshot запись снимка содержимого кэша в w, возвращает количество записанных записей
+	Snapshot(ctx context.Context, w io.Writer) (n int, err error)
+	// Restore загрузка содержимого кэша из снимка, пропуская истекшие записи, возвращает количество восстановленных записей
+	Restore(ctx context.Context, r io.Reader) (n int, err error)
+	// OpenLog включение журнала изменений с применением ранее записанных в него изменений,
+	// возвращает количество примененных изменений
+	OpenLog(ctx context.Context, cfg model.OpLogConfig) (n int, err error)
+	// CompactLog переписывание разросшегося журнала изменений из текущего содержимого кэша
+	CompactLog(ctx context.Context) (compacted bool, err error)
+}
+
+// NamespaceService управляет пространствами имен: именованными кэшами, у каждого из которых свои параметры
+type NamespaceService interface {
+	// Namespace получение сервиса кэша пространства имен по имени
+	Namespace(ctx context.Context, name string) (CacheService, error)
+	// Create создание пространства имен с параметрами кэша cfg, возвращает итоговые параметры
+	Create(ctx context.Context, name string, cfg model.NamespaceConfig) (model.NamespaceInfo, error)
+	// Delete удаление пространства имен вместе со всеми его записями
+	Delete(ctx context.Context, name string) error
+	// List получение всех пространств имен, упорядоченных по имени
+	List(ctx context.Context) ([]model.NamespaceInfo, error)
+	// Close закрытие кэшей всех пространств имен
+	Close(ctx context.Context) error
+	// SetHooks регистрация действий при создании и удалении пространств имен
+	SetHooks(hooks NamespaceHooks)
+}
+
+// NamespaceHooks задает действия при создании и удалении пространств имен (например, открытие и удаление
+// журнала изменений). Незаданные действия пропускаются.
+type NamespaceHooks struct {
+	// OnCreate вызывается для созданного пространства имен info до того, как оно станет доступно запросам.
+	// Вызывается под блокировкой сервиса пространств имен, поэтому не должно к нему обращаться.
+	OnCreate func(ctx context.Context, info model.NamespaceInfo, cacheService CacheService)
+	// OnDelete вызывается после удаления пространства имен и закрытия его кэша. Как и OnCreate, вызывается
+	// под блокировкой сервиса, поэтому пространство имен с тем же именем не создается, пока OnDelete не завершится.
+	OnDelete func(ctx context.Context, name string)
 }
@@ -1,11 +1,27 @@
 // Package cache предоставляет структуры, использующиеся сервисом golang-cache-lru в API-слое, то есть для взаимодействия с внешним миром.
 package cache
 
+import "encoding/json"
+
+// Режимы записи в кэш (поле mode в EntryPutData)
+const (
+	PutModeIfAbsent  = "nx" // Записать, только если ключа нет в кэше
+	PutModeIfPresent = "xx" // Записать, только если ключ уже есть в кэше
+)
+
 // EntryPutData представляет набор полей, используемых для создания записи в кэше.
 type EntryPutData struct {
 	Key        string      `json:"key"`         // Ключ
 	Value      interface{} `json:"value"`       // Значение
 	TTLSeconds int         `json:"ttl_seconds"` // TTL (в секундах)
+	Sliding    bool        `json:"sliding"`     // Продлевать TTL при каждом чтении (скользящее истечение)
+
+	StaleTTLSeconds     int `json:"stale_ttl_seconds"`     // Время после истечения, в течение которого запись отдается как устаревшая (в секундах)
+	RefreshAheadSeconds int `json:"refresh_ahead_seconds"` // Время до истечения, начиная с которого запись обновляется в фоне (в секундах)
+
+	Tags []string `json:"tags,omitempty"` // Теги для группового удаления записей через DELETE /api/lru?tag=
+
+	Mode string `json:"mode"` // Режим записи: "" - всегда, "nx" - только если ключа нет, "xx" - только если ключ есть
 }
 
 // EntryGetAllData описывает результат запроса на получение всех ключей и их значений их кэша.
@@ -16,9 +32,113 @@ type EntryGetAllData struct {
 	Values []interface{} `json:"values"`
 }
 
+// EntryScanData описывает страницу постраничного обхода кэша (GET /api/lru с параметрами cursor, limit и order).
+// Пары ключ-значения располагаются на соответствующих позициях в слайсах, как в EntryGetAllData.
+type EntryScanData struct {
+	Keys       []string      `json:"keys"`
+	Values     []interface{} `json:"values"`
+	NextCursor string        `json:"next_cursor,omitempty"` // Курсор следующей страницы, отсутствует на последней странице
+}
+
+// Порядки обхода записей (параметр order в GET /api/lru)
+const (
+	ScanOrderLRU = "lru" // От давно не использовавшихся записей к недавно использованным
+	ScanOrderMRU = "mru" // От недавно использованных записей к давно не использовавшимся
+)
+
+// EvictedData описывает результат удаления записей по префиксу или тегу.
+type EvictedData struct {
+	Evicted int `json:"evicted"` // Количество удаленных записей
+}
+
+// IncrData представляет набор полей для увеличения числового значения записи.
+type IncrData struct {
+	Delta      json.Number `json:"delta"`       // Приращение (целое или дробное, может быть отрицательным), по умолчанию 1
+	TTLSeconds int         `json:"ttl_seconds"` // Время жизни записи в секундах, если она создается
+}
+
 // EntryGetData представляет набор полей, которые сервис возвращает в качестве данных о записи в кэше.
 type EntryGetData struct {
 	Key       string      `json:"key"`
 	Value     interface{} `json:"value"`
 	ExpiresAt int64       `json:"expires_at"`
+	Stale     bool        `json:"stale"`   // Запись истекла и отдается как устаревшая, пока не обновится
+	Version   uint64      `json:"version"` // Версия записи, растет при каждой записи значения (также передается в ETag)
+}
+
+// KeysData представляет список ключей для пакетных операций _mget и _mdelete.
+type KeysData struct {
+	Keys []string `json:"keys"`
+}
+
+// EntriesPutData представляет список записей для пакетной записи _mput.
+type EntriesPutData struct {
+	Entries []EntryPutData `json:"entries"` // Записи, у каждой свой TTL и параметры; mode не поддерживается
+}
+
+// EntryResultData представляет результат пакетной операции над одной записью.
+// Пустые поля опускаются: например, для промаха возвращаются только key и status.
+type EntryResultData struct {
+	Key       string      `json:"key"`
+	Status    int         `json:"status"` // HTTP-код, который вернула бы операция над одной записью (200, 201, 404, 410 и т.д.)
+	Value     interface{} `json:"value,omitempty"`
+	ExpiresAt int64       `json:"expires_at,omitempty"`
+	Stale     bool        `json:"stale,omitempty"`
+	Version   uint64      `json:"version,omitempty"`
+}
+
+// BatchResultData представляет результаты пакетной операции в порядке ключей или записей запроса.
+type BatchResultData struct {
+	Results []EntryResultData `json:"results"`
+}
+
+// Типы операций и условий транзакции _txn
+const (
+	TxnOpPut   = "put"   // Запись значения
+	TxnOpEvict = "evict" // Удаление записи
+	TxnOpIncr  = "incr"  // Увеличение числового значения
+
+	TxnCondExists  = "exists"  // Запись есть и не истекла
+	TxnCondAbsent  = "absent"  // Записи нет или она истекла
+	TxnCondVersion = "version" // Версия записи равна version
+)
+
+// TxnOpData представляет операцию транзакции: поля записи как в POST /api/lru (mode не поддерживается)
+// и приращение для incr.
+type TxnOpData struct {
+	Op string `json:"op"` // Тип операции: "put", "evict" или "incr"
+	EntryPutData
+	Delta json.Number `json:"delta"` // Приращение для incr, по умолчанию 1
+}
+
+// TxnConditionData представляет условие транзакции.
+type TxnConditionData struct {
+	Cond    string `json:"cond"` // Тип условия: "exists", "absent" или "version"
+	Key     string `json:"key"`
+	Version uint64 `json:"version"` // Ожидаемая версия для "version"
+}
+
+// TxnData представляет транзакцию: условия и упорядоченный список операций.
+type TxnData struct {
+	Conditions []TxnConditionData `json:"conditions"`
+	Ops        []TxnOpData        `json:"ops"`
+}
+
+// TxnResultData представляет результат транзакции: результаты операций, если транзакция выполнена,
+// или номер невыполненного условия либо операции, из-за которых она отменена.
+type TxnResultData struct {
+	Results         []EntryResultData `json:"results,omitempty"`
+	FailedCondition *int              `json:"failed_condition,omitempty"`
+	FailedOp        *int              `json:"failed_op,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// CacheStatsData описывает текущее заполнение кэша.
+type CacheStatsData struct {
+	Len      int    `json:"len"`       // Количество записей
+	Size     int    `json:"size"`      // Максимальное количество записей
+	Bytes    int64  `json:"bytes"`     // Оценка суммарного размера записей в байтах
+	MaxBytes int64  `json:"max_bytes"` // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	Admitted uint64 `json:"admitted"`  // Количество новых записей, допущенных фильтром допуска в заполненный кэш
+	Rejected uint64 `json:"rejected"`  // Количество новых записей, отклоненных фильтром допуска
 }
@@ -0,0 +1,17 @@
+package cache
+
+// NamespaceData описывает пространство имен (именованный кэш) и параметры его кэша.
+// При создании не заданные параметры наследуются от кэша пространства имен по умолчанию.
+type NamespaceData struct {
+	Name              string `json:"name"`                          // Имя, используется в маршрутах /api/ns/{name}/lru
+	Size              int    `json:"cache_size,omitempty"`          // Максимальное количество записей
+	DefaultTTLSeconds int    `json:"default_ttl_seconds,omitempty"` // TTL по умолчанию (в секундах)
+	Shards            int    `json:"cache_shards,omitempty"`        // Количество шардов
+	MaxBytes          int64  `json:"cache_max_bytes,omitempty"`     // Лимит суммарного размера записей в байтах, 0 - без ограничения
+	EvictionPolicy    string `json:"eviction_policy,omitempty"`     // Политика вытеснения ("lru", "lfu" или "arc")
+}
+
+// NamespacesData описывает результат запроса на получение всех пространств имен.
+type NamespacesData struct {
+	Namespaces []NamespaceData `json:"namespaces"`
+}
@@ -0,0 +1,165 @@
+package lru
+
+import (
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// arcPolicy реализует Adaptive Replacement Cache (Megiddo, Modha).
+//
+// Записи шарда делятся на два списка: t1 (к записи обращались один раз) и t2 (к записи обращались
+// повторно). Для недавно вытесненных ключей хранятся "призраки" b1 и b2 - только ключи, без значений.
+// Попадание нового ключа в b1 говорит о том, что t1 стоит увеличить, а попадание в b2 - что стоит
+// увеличить t2. Целевой размер t1 (p) адаптируется на лету, поэтому политика сама подстраивается
+// под смесь нагрузок, где важны и давность, и частота обращений.
+type arcPolicy[K comparable, V any] struct {
+	capacity int // c - вместимость шарда в записях
+	target   int // p - целевой размер t1
+
+	t1, t2 *list.LruList[K, *list.Entry[K, V]] // записи шарда
+	b1, b2 *list.LruList[K, struct{}]          // ключи недавно вытесненных записей
+
+	t1Items, t2Items map[K]*list.Entry[K, *list.Entry[K, V]]
+	b1Items, b2Items map[K]*list.Entry[K, struct{}]
+
+	lastFromB2 bool              // последняя добавленная запись была призраком из b2
+	evicting   *list.Entry[K, V] // запись, которую кэш вытесняет прямо сейчас (см. OnEvict)
+}
+
+// NewARCPolicy создает политику вытеснения ARC для шарда вместимостью capacity записей
+func NewARCPolicy[K comparable, V any](_ *list.LruList[K, V], capacity int) EvictionPolicy[K, V] {
+	p := &arcPolicy[K, V]{capacity: capacity}
+	p.Reset()
+	return p
+}
+
+// OnInsert помещает новую запись в t1, а если ее ключ найден среди призраков - в t2,
+// адаптируя целевой размер t1
+func (p *arcPolicy[K, V]) OnInsert(e *list.Entry[K, V]) {
+	p.lastFromB2 = false
+	defer p.trimGhosts()
+
+	if ghost, inB1 := p.b1Items[e.Key]; inB1 {
+		p.target = min(p.capacity, p.target+max(p.b2.Length()/p.b1.Length(), 1))
+		p.b1.Remove(ghost)
+		delete(p.b1Items, e.Key)
+		p.t2Items[e.Key] = p.t2.PushFront(e.Key, e, time.Time{})
+		return
+	}
+
+	if ghost, inB2 := p.b2Items[e.Key]; inB2 {
+		p.target = max(0, p.target-max(p.b1.Length()/p.b2.Length(), 1))
+		p.b2.Remove(ghost)
+		delete(p.b2Items, e.Key)
+		p.t2Items[e.Key] = p.t2.PushFront(e.Key, e, time.Time{})
+		p.lastFromB2 = true
+		return
+	}
+
+	p.t1Items[e.Key] = p.t1.PushFront(e.Key, e, time.Time{})
+}
+
+// OnAccess переносит запись из t1 в t2 или в начало t2
+func (p *arcPolicy[K, V]) OnAccess(e *list.Entry[K, V]) {
+	if node, ok := p.t1Items[e.Key]; ok {
+		p.t1.Remove(node)
+		delete(p.t1Items, e.Key)
+		p.t2Items[e.Key] = p.t2.PushFront(e.Key, e, time.Time{})
+		return
+	}
+
+	if node, ok := p.t2Items[e.Key]; ok {
+		p.t2.MoveToFront(node)
+	}
+}
+
+// OnEvict запоминает вытесняемую запись, чтобы следующий за ним OnRemove сделал ее ключ призраком
+func (p *arcPolicy[K, V]) OnEvict(e *list.Entry[K, V]) {
+	p.evicting = e
+}
+
+// OnRemove удаляет запись из t1 или t2. Если запись была вытеснена (см. OnEvict),
+// ее ключ становится призраком в b1 или b2 соответственно.
+func (p *arcPolicy[K, V]) OnRemove(e *list.Entry[K, V]) {
+	evicted := e == p.evicting
+	p.evicting = nil
+
+	if node, ok := p.t1Items[e.Key]; ok {
+		p.t1.Remove(node)
+		delete(p.t1Items, e.Key)
+		if evicted {
+			p.b1Items[e.Key] = p.b1.PushFront(e.Key, struct{}{}, time.Time{})
+		}
+	} else if node, ok := p.t2Items[e.Key]; ok {
+		p.t2.Remove(node)
+		delete(p.t2Items, e.Key)
+		if evicted {
+			p.b2Items[e.Key] = p.b2.PushFront(e.Key, struct{}{}, time.Time{})
+		}
+	}
+
+	p.trimGhosts()
+}
+
+// Victim выбирает последнюю запись t1, если t1 превышает целевой размер, иначе последнюю запись t2
+func (p *arcPolicy[K, V]) Victim(exclude *list.Entry[K, V]) *list.Entry[K, V] {
+	t1Len := p.t1.Length()
+	if exclude != nil {
+		if _, ok := p.t1Items[exclude.Key]; ok {
+			t1Len--
+		}
+	}
+
+	first, second := p.t2, p.t1
+	if t1Len > 0 && (t1Len > p.target || (p.lastFromB2 && t1Len == p.target)) {
+		first, second = p.t1, p.t2
+	}
+
+	for _, l := range []*list.LruList[K, *list.Entry[K, V]]{first, second} {
+		for node := l.Back(); node != nil; node = node.PrevEntry() {
+			if node.Value != exclude {
+				return node.Value
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reset очищает все списки и сбрасывает целевой размер t1
+func (p *arcPolicy[K, V]) Reset() {
+	p.target = 0
+	p.t1, p.t2 = list.NewList[K, *list.Entry[K, V]](), list.NewList[K, *list.Entry[K, V]]()
+	p.b1, p.b2 = list.NewList[K, struct{}](), list.NewList[K, struct{}]()
+	p.t1Items = make(map[K]*list.Entry[K, *list.Entry[K, V]])
+	p.t2Items = make(map[K]*list.Entry[K, *list.Entry[K, V]])
+	p.b1Items = make(map[K]*list.Entry[K, struct{}])
+	p.b2Items = make(map[K]*list.Entry[K, struct{}])
+	p.lastFromB2 = false
+	p.evicting = nil
+}
+
+// trimGhosts ограничивает размер списков призраков: |t1| + |b1| <= c и |t1| + |t2| + |b1| + |b2| <= 2c
+func (p *arcPolicy[K, V]) trimGhosts() {
+	for p.b1.Length() > 0 && p.t1.Length()+p.b1.Length() > p.capacity {
+		p.removeGhost(p.b1, p.b1Items)
+	}
+
+	for p.t1.Length()+p.t2.Length()+p.b1.Length()+p.b2.Length() > 2*p.capacity {
+		if p.b2.Length() > 0 {
+			p.removeGhost(p.b2, p.b2Items)
+		} else if p.b1.Length() > 0 {
+			p.removeGhost(p.b1, p.b1Items)
+		} else {
+			return
+		}
+	}
+}
+
+// removeGhost удаляет старейший ключ из списка призраков
+func (p *arcPolicy[K, V]) removeGhost(l *list.LruList[K, struct{}], items map[K]*list.Entry[K, struct{}]) {
+	ghost := l.Back()
+	l.Remove(ghost)
+	delete(items, ghost.Key)
+}
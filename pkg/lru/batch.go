@@ -0,0 +1,180 @@
+package lru
+
+import "time"
+
+// PutEntry описывает одну запись для MPut
+type PutEntry[K comparable, V any] struct {
+	Key     K
+	Value   V
+	TTL     time.Duration // Нулевой TTL означает TTL по умолчанию (см. WithDefaultTTL)
+	Options []PutOption   // Параметры записи (например, Sliding)
+}
+
+// MGet возвращает записи по ключам. Результаты располагаются на позициях соответствующих ключей:
+// для найденных ключей заполняется items[i], для остальных errs[i] содержит ErrNotFound или ErrExpired.
+//
+// Каждый затронутый шард блокируется один раз на все свои ключи. Чтение обрабатывается так же,
+// как в GetItem, но обращения применяются сразу, без буфера чтений.
+func (c *Cache[K, V]) MGet(keys []K) (items []Item[V], errs []error) {
+	items, errs = make([]Item[V], len(keys)), make([]error, len(keys))
+	if c.closed.Load() {
+		fillErrors(errs, ErrClosed)
+		return items, errs
+	}
+
+	type refreshKey struct {
+		key K
+		cfg entrySettings
+	}
+	var refresh []refreshKey
+
+	for shardIdx, idxs := range c.groupByShard(len(keys), func(i int) K { return keys[i] }) {
+		if len(idxs) == 0 {
+			continue
+		}
+
+		s := c.shards[shardIdx]
+		s.mu.Lock()
+		now := time.Now()
+		for _, i := range idxs {
+			ent, err := s.get(keys[i], now)
+			if err != nil {
+				s.recordAccess(keys[i])
+				errs[i] = err
+				continue
+			}
+
+			if !ent.Expired(now) {
+				s.slide(ent, now)
+			}
+			s.access(ent)
+
+			items[i] = itemOf(ent, now)
+			if ent.RefreshDue(now) {
+				refresh = append(refresh, refreshKey{key: ent.Key, cfg: entrySettingsOf(ent)})
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	for _, r := range refresh {
+		c.refresh(r.key, r.cfg)
+	}
+
+	return items, errs
+}
+
+// MPut записывает несколько значений и возвращает записанные записи. Результаты располагаются
+// на позициях соответствующих записей: errs[i] содержит ошибку записи entries[i] (например, ErrTooLarge).
+// Для записей, отклоненных фильтром допуска (см. WithAdmission), errs[i] содержит ErrRejected.
+//
+// Каждый затронутый шард блокируется один раз на все свои записи. Пакет не атомарен: записи
+// из разных шардов применяются по очереди, а записи одного пакета могут вытеснить друг друга.
+func (c *Cache[K, V]) MPut(entries []PutEntry[K, V]) (items []Item[V], errs []error) {
+	items, errs = make([]Item[V], len(entries)), make([]error, len(entries))
+	if c.closed.Load() {
+		fillErrors(errs, ErrClosed)
+		return items, errs
+	}
+
+	// Проверка параметров и оценка стоимости выполняются до lock
+	cfgs, costs := make([]entrySettings, len(entries)), make([]int64, len(entries))
+	for i, e := range entries {
+		cfgs[i] = newEntrySettings(e.Options)
+		if e.TTL < 0 {
+			errs[i] = ErrInvalidTTL
+			continue
+		}
+		if err := cfgs[i].validate(); err != nil {
+			errs[i] = err
+			continue
+		}
+		costs[i] = c.entryCost(e.Key, e.Value)
+	}
+
+	for shardIdx, idxs := range c.groupByShard(len(entries), func(i int) K { return entries[i].Key }) {
+		if len(idxs) == 0 {
+			continue
+		}
+
+		s := c.shards[shardIdx]
+		s.mu.Lock()
+		now := time.Now()
+		for _, i := range idxs {
+			if errs[i] != nil {
+				continue
+			}
+
+			e, ttl := entries[i], entries[i].TTL
+			if ttl == 0 {
+				ttl = c.defaultTTL
+			}
+
+			ent, err := s.put(e.Key, e.Value, costs[i], c.expiresAt(now, ttl), ttl, cfgs[i])
+			if ent == nil {
+				errs[i] = err
+				continue
+			}
+			items[i] = itemOf(ent, now)
+		}
+		s.mu.Unlock()
+	}
+
+	return items, errs
+}
+
+// MEvict удаляет записи по ключам и возвращает их значения. Результаты располагаются на позициях
+// соответствующих ключей, errs[i] обрабатывается так же, как ошибка Evict.
+//
+// Каждый затронутый шард блокируется один раз на все свои ключи.
+func (c *Cache[K, V]) MEvict(keys []K) (values []V, errs []error) {
+	values, errs = make([]V, len(keys)), make([]error, len(keys))
+	if c.closed.Load() {
+		fillErrors(errs, ErrClosed)
+		return values, errs
+	}
+
+	for shardIdx, idxs := range c.groupByShard(len(keys), func(i int) K { return keys[i] }) {
+		if len(idxs) == 0 {
+			continue
+		}
+
+		s := c.shards[shardIdx]
+		s.mu.Lock()
+		now := time.Now()
+		for _, i := range idxs {
+			ent, ok := s.items[keys[i]]
+			if !ok {
+				errs[i] = ErrNotFound
+				continue
+			}
+
+			s.evict(ent)
+			if ent.Dead(now) {
+				errs[i] = ErrExpired
+				continue
+			}
+			values[i] = ent.Value
+		}
+		s.mu.Unlock()
+	}
+
+	return values, errs
+}
+
+// groupByShard распределяет позиции n ключей по шардам, сохраняя порядок ключей внутри шарда
+func (c *Cache[K, V]) groupByShard(n int, keyAt func(i int) K) [][]int {
+	groups := make([][]int, len(c.shards))
+	for i := 0; i < n; i++ {
+		shardIdx := c.shardIndex(keyAt(i))
+		groups[shardIdx] = append(groups[shardIdx], i)
+	}
+	return groups
+}
+
+// fillErrors записывает err во все позиции errs
+func fillErrors(errs []error, err error) {
+	for i := range errs {
+		errs[i] = err
+	}
+}
@@ -0,0 +1,29 @@
+package lru
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// defaultCost оценивает стоимость записи в байтах как длину ключа плюс размер значения,
+// закодированного в JSON. Строки и слайсы байтов учитываются по длине без кодирования.
+func defaultCost[K comparable, V any](key K, value V) int64 {
+	return sizeOf(key) + sizeOf(value)
+}
+
+// sizeOf оценивает размер произвольного значения в байтах
+func sizeOf(v interface{}) int64 {
+	switch t := v.(type) {
+	case string:
+		return int64(len(t))
+	case []byte:
+		return int64(len(t))
+	case nil:
+		return 0
+	}
+
+	if encoded, err := json.Marshal(v); err == nil {
+		return int64(len(encoded))
+	}
+	return int64(len(fmt.Sprint(v)))
+}
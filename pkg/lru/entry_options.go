@@ -0,0 +1,95 @@
+package lru
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// PutOption задает параметр отдельной записи при записи через Put
+type PutOption func(*entrySettings)
+
+// entrySettings содержит параметры записи, собранные из опций
+type entrySettings struct {
+	sliding      bool
+	staleTTL     time.Duration
+	refreshAhead time.Duration
+	tags         []string
+}
+
+// newEntrySettings собирает параметры записи из опций
+func newEntrySettings(opts []PutOption) entrySettings {
+	var cfg entrySettings
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// validate проверяет параметры записи
+func (cfg entrySettings) validate() error {
+	if cfg.staleTTL < 0 || cfg.refreshAhead < 0 {
+		return ErrInvalidTTL
+	}
+	for _, tag := range cfg.tags {
+		if len(tag) == 0 {
+			return fmt.Errorf("%w: tag can not be empty", ErrInvalidOption)
+		}
+	}
+	return nil
+}
+
+// entrySettingsOf возвращает параметры, с которыми был записан элемент
+func entrySettingsOf[K comparable, V any](ent *list.Entry[K, V]) entrySettings {
+	return entrySettings{
+		sliding:      ent.Sliding,
+		staleTTL:     ent.StaleTTL,
+		refreshAhead: ent.RefreshAhead,
+		tags:         ent.Tags,
+	}
+}
+
+// applyEntrySettings задает элементу, записанному с TTL ttl, параметры записи
+func applyEntrySettings[K comparable, V any](ent *list.Entry[K, V], ttl time.Duration, cfg entrySettings) {
+	ent.TTL = ttl
+	ent.Sliding = cfg.sliding
+	ent.StaleTTL = cfg.staleTTL
+	ent.RefreshAhead = cfg.refreshAhead
+	ent.Tags = cfg.tags
+}
+
+// Sliding включает скользящее истечение записи: каждое успешное чтение продлевает запись
+// на TTL, с которым она была сохранена. Без этой опции TTL отсчитывается от момента записи,
+// и чтение на него не влияет.
+func Sliding() PutOption {
+	return func(s *entrySettings) {
+		s.sliding = true
+	}
+}
+
+// StaleTTL разрешает отдавать запись в течение staleTTL после истечения. Такая запись
+// возвращается с признаком Item.Stale, а обращение к ней запускает фоновое обновление
+// зарегистрированным загрузчиком (см. SetLoader).
+func StaleTTL(staleTTL time.Duration) PutOption {
+	return func(s *entrySettings) {
+		s.staleTTL = staleTTL
+	}
+}
+
+// RefreshAhead включает упреждающее обновление записи: обращение к ней в последние refreshAhead
+// до истечения возвращает текущее значение и запускает одно фоновое обновление зарегистрированным
+// загрузчиком (см. SetLoader), чтобы запись не истекала под нагрузкой.
+func RefreshAhead(refreshAhead time.Duration) PutOption {
+	return func(s *entrySettings) {
+		s.refreshAhead = refreshAhead
+	}
+}
+
+// Tags помечает запись тегами. Все записи с тегом удаляются одним вызовом EvictByTag,
+// даже если у их ключей нет общего префикса. Повторяющиеся теги учитываются один раз.
+func Tags(tags ...string) PutOption {
+	return func(s *entrySettings) {
+		s.tags = uniqueTags(append(s.tags, tags...))
+	}
+}
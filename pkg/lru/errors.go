@@ -0,0 +1,34 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound возвращается, если записи с указанным ключом нет в кэше
+	ErrNotFound = errors.New("lru: key not found")
+	// ErrExpired возвращается, если запись с указанным ключом истекла, но еще не удалена фоновой
+	// очисткой. ErrExpired оборачивает ErrNotFound, поэтому проверка errors.Is(err, ErrNotFound)
+	// срабатывает для обеих ошибок.
+	ErrExpired = fmt.Errorf("%w: entry expired", ErrNotFound)
+	// ErrInvalidTTL возвращается при попытке записать значение с отрицательным TTL
+	ErrInvalidTTL = errors.New("lru: ttl can not be negative")
+	// ErrInvalidOption возвращается конструктором при некорректных параметрах кэша
+	ErrInvalidOption = errors.New("lru: invalid option")
+	// ErrClosed возвращается любыми операциями над кэшем после вызова Close
+	ErrClosed = errors.New("lru: cache is closed")
+	// ErrTooLarge возвращается при попытке записать значение, стоимость которого превышает лимит шарда
+	ErrTooLarge = errors.New("lru: entry cost exceeds cache capacity")
+	// ErrExists возвращается PutIfAbsent, если запись с указанным ключом уже есть в кэше
+	ErrExists = errors.New("lru: key already exists")
+	// ErrVersionMismatch возвращается CompareAndSwap, если версия записи отличается от ожидаемой
+	ErrVersionMismatch = errors.New("lru: entry version mismatch")
+	// ErrConditionFailed возвращается Txn (в составе TxnError), если условие транзакции не выполнено
+	ErrConditionFailed = errors.New("lru: transaction condition failed")
+	// ErrRejected возвращается при записи новой записи в переполненный шард, если фильтр допуска
+	// (см. WithAdmission) счел ее менее популярной, чем запись, которую пришлось бы вытеснить
+	ErrRejected = errors.New("lru: entry rejected by admission filter")
+	// ErrLoaderPanic возвращается GetOrLoad, если загрузчик завершился паникой
+	ErrLoaderPanic = errors.New("lru: loader panicked")
+)
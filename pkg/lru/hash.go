@@ -0,0 +1,44 @@
+package lru
+
+import (
+	"fmt"
+	"hash/maphash"
+)
+
+// newHasher возвращает хэш-функцию ключей по умолчанию. Строки и целые числа хэшируются
+// напрямую, остальные типы - через их строковое представление.
+func newHasher[K comparable]() func(K) uint64 {
+	seed := maphash.MakeSeed()
+
+	return func(key K) uint64 {
+		switch k := any(key).(type) {
+		case string:
+			return maphash.String(seed, k)
+		case int:
+			return mix(uint64(k))
+		case int64:
+			return mix(uint64(k))
+		case int32:
+			return mix(uint64(k))
+		case uint:
+			return mix(uint64(k))
+		case uint64:
+			return mix(k)
+		case uint32:
+			return mix(uint64(k))
+		default:
+			return maphash.String(seed, fmt.Sprint(k))
+		}
+	}
+}
+
+// mix перемешивает биты целого числа (финализатор splitmix64),
+// чтобы последовательные ключи равномерно распределялись по шардам
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,105 @@
+package lru
+
+import (
+	clist "container/list"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// lfuPolicy вытесняет запись с наименьшим количеством обращений, а среди записей с одинаковым
+// количеством - ту, что дольше всех находится на этом уровне.
+//
+// Записи хранятся в "корзинах" по частоте обращений, корзины упорядочены по возрастанию частоты.
+// При обращении запись переносится в соседнюю корзину, поэтому все операции выполняются за O(1).
+type lfuPolicy[K comparable, V any] struct {
+	buckets *clist.List // *lfuBucket по возрастанию частоты
+	items   map[*list.Entry[K, V]]*lfuItem
+}
+
+// lfuBucket задает корзину записей с одинаковой частотой обращений
+type lfuBucket struct {
+	freq    uint64
+	entries *clist.List // записи, в начале - недавно попавшие в корзину
+}
+
+// lfuItem задает положение записи в корзинах
+type lfuItem struct {
+	bucket *clist.Element // элемент списка корзин
+	elem   *clist.Element // элемент списка записей корзины
+}
+
+// NewLFUPolicy создает политику вытеснения LFU
+func NewLFUPolicy[K comparable, V any](_ *list.LruList[K, V], _ int) EvictionPolicy[K, V] {
+	p := &lfuPolicy[K, V]{}
+	p.Reset()
+	return p
+}
+
+// OnInsert помещает новую запись в корзину с частотой 1
+func (p *lfuPolicy[K, V]) OnInsert(e *list.Entry[K, V]) {
+	front := p.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = p.buckets.PushFront(&lfuBucket{freq: 1, entries: clist.New()})
+	}
+
+	p.items[e] = &lfuItem{
+		bucket: front,
+		elem:   front.Value.(*lfuBucket).entries.PushFront(e),
+	}
+}
+
+// OnAccess переносит запись в корзину со следующей частотой
+func (p *lfuPolicy[K, V]) OnAccess(e *list.Entry[K, V]) {
+	item, ok := p.items[e]
+	if !ok {
+		return
+	}
+
+	cur := item.bucket.Value.(*lfuBucket)
+	next := item.bucket.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != cur.freq+1 {
+		next = p.buckets.InsertAfter(&lfuBucket{freq: cur.freq + 1, entries: clist.New()}, item.bucket)
+	}
+
+	p.detach(item)
+	item.bucket = next
+	item.elem = next.Value.(*lfuBucket).entries.PushFront(e)
+}
+
+// OnRemove удаляет запись из корзин
+func (p *lfuPolicy[K, V]) OnRemove(e *list.Entry[K, V]) {
+	item, ok := p.items[e]
+	if !ok {
+		return
+	}
+
+	p.detach(item)
+	delete(p.items, e)
+}
+
+// Victim возвращает старейшую запись из корзины с наименьшей частотой
+func (p *lfuPolicy[K, V]) Victim(exclude *list.Entry[K, V]) *list.Entry[K, V] {
+	for b := p.buckets.Front(); b != nil; b = b.Next() {
+		for el := b.Value.(*lfuBucket).entries.Back(); el != nil; el = el.Prev() {
+			if e := el.Value.(*list.Entry[K, V]); e != exclude {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// Reset удаляет все записи из корзин
+func (p *lfuPolicy[K, V]) Reset() {
+	p.buckets = clist.New()
+	p.items = make(map[*list.Entry[K, V]]*lfuItem)
+}
+
+// detach удаляет запись из ее корзины, а опустевшую корзину - из списка корзин
+func (p *lfuPolicy[K, V]) detach(item *lfuItem) {
+	bucket := item.bucket.Value.(*lfuBucket)
+	bucket.entries.Remove(item.elem)
+	if bucket.entries.Len() == 0 {
+		p.buckets.Remove(item.bucket)
+	}
+}
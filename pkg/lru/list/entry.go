@@ -0,0 +1,96 @@
+package list
+
+import "time"
+
+// Entry определяет запись в кэше
+type Entry[K comparable, V any] struct {
+	// Следующий и предыдущий указатели в двусвязном списке элементов.
+	next, prev *Entry[K, V]
+
+	// Родительский список
+	list *LruList[K, V]
+
+	// Позиция в очереди истечения (-1, если элемента в очереди нет)
+	expiryIndex int
+
+	// Отметка порядка в списке, выданная при последнем перемещении в начало списка
+	stamp uint64
+
+	// Ключ
+	Key K
+
+	// Значение
+	Value V
+
+	// Дата истечения (нулевое значение означает, что запись не истекает)
+	ExpiresAt time.Time
+
+	// Стоимость записи (например, размер в байтах)
+	Cost int64
+
+	// Версия записи, увеличивается при каждой записи значения
+	Version uint64
+
+	// TTL, с которым запись была сохранена
+	TTL time.Duration
+
+	// Продлевается ли TTL записи при каждом чтении (скользящее истечение)
+	Sliding bool
+
+	// Время после ExpiresAt, в течение которого запись еще отдается как устаревшая
+	StaleTTL time.Duration
+
+	// Время до ExpiresAt, начиная с которого запись обновляется в фоне
+	RefreshAhead time.Duration
+
+	// Теги записи для группового удаления
+	Tags []string
+}
+
+// Stamp возвращает отметку порядка элемента в списке: чем больше отметка, тем ближе элемент к началу списка
+func (e *Entry[K, V]) Stamp() uint64 {
+	return e.stamp
+}
+
+// NextEntry возвращает следующий элемент
+func (e *Entry[K, V]) NextEntry() *Entry[K, V] {
+	if n := e.next; e.list != nil && n != &e.list.root {
+		return n
+	}
+	return nil
+}
+
+// PrevEntry возвращает предыдущий элемент
+func (e *Entry[K, V]) PrevEntry() *Entry[K, V] {
+	if p := e.prev; e.list != nil && p != &e.list.root {
+		return p
+	}
+	return nil
+}
+
+// Expired сообщает, истекла ли запись на момент now
+func (e *Entry[K, V]) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// Deadline возвращает дату, после которой запись не может быть отдана даже как устаревшая
+func (e *Entry[K, V]) Deadline() time.Time {
+	if e.ExpiresAt.IsZero() {
+		return e.ExpiresAt
+	}
+	return e.ExpiresAt.Add(e.StaleTTL)
+}
+
+// Dead сообщает, прошел ли на момент now Deadline записи
+func (e *Entry[K, V]) Dead(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.Deadline())
+}
+
+// RefreshDue сообщает, пора ли на момент now обновить запись: она истекла
+// или до истечения осталось меньше RefreshAhead
+func (e *Entry[K, V]) RefreshDue(now time.Time) bool {
+	if e.ExpiresAt.IsZero() {
+		return false
+	}
+	return !now.Before(e.ExpiresAt.Add(-e.RefreshAhead))
+}
@@ -0,0 +1,69 @@
+package list
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrevEntry(t *testing.T) {
+	Entry1 := &Entry[string, interface{}]{}
+	assert.Nil(t, Entry1.PrevEntry())
+
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+
+	assert.False(t, (&Entry[string, int]{}).Expired(now))
+	assert.False(t, (&Entry[string, int]{ExpiresAt: now.Add(time.Second)}).Expired(now))
+	assert.True(t, (&Entry[string, int]{ExpiresAt: now.Add(-time.Second)}).Expired(now))
+}
+
+func TestDeadAndRefreshDue(t *testing.T) {
+	now := time.Now()
+
+	assert.False(t, (&Entry[string, int]{}).Dead(now))
+	assert.False(t, (&Entry[string, int]{}).RefreshDue(now))
+
+	// Истекшая запись с запасом StaleTTL еще не удаляется, но требует обновления
+	stale := &Entry[string, int]{ExpiresAt: now.Add(-time.Second), StaleTTL: time.Minute}
+	assert.True(t, stale.Expired(now))
+	assert.False(t, stale.Dead(now))
+	assert.True(t, stale.RefreshDue(now))
+	assert.Equal(t, now.Add(59*time.Second), stale.Deadline())
+
+	// Запись обновляется заранее, если до истечения осталось меньше RefreshAhead
+	fresh := &Entry[string, int]{ExpiresAt: now.Add(time.Second), RefreshAhead: 2 * time.Second}
+	assert.False(t, fresh.Expired(now))
+	assert.True(t, fresh.RefreshDue(now))
+	fresh.RefreshAhead = 0
+	assert.False(t, fresh.RefreshDue(now))
+}
+
+func TestStampAndNextEntry(t *testing.T) {
+	l := NewList[string, int]()
+	a := l.PushFront("a", 1, time.Time{})
+	b := l.PushFront("b", 2, time.Time{})
+	c := l.PushFront("c", 3, time.Time{})
+
+	// Отметки убывают от начала списка к концу
+	assert.Equal(t, c, l.Front())
+	assert.Equal(t, b, c.NextEntry())
+	assert.Equal(t, a, b.NextEntry())
+	assert.Nil(t, a.NextEntry())
+	assert.Greater(t, c.Stamp(), b.Stamp())
+	assert.Greater(t, b.Stamp(), a.Stamp())
+
+	// Перемещение в начало выдает новую, наибольшую отметку
+	l.MoveToFront(a)
+	assert.Equal(t, a, l.Front())
+	assert.Greater(t, a.Stamp(), c.Stamp())
+
+	// Очистка списка не сбрасывает отметки
+	last := a.Stamp()
+	l.Init()
+	assert.Nil(t, l.Front())
+	assert.Greater(t, l.PushFront("d", 4, time.Time{}).Stamp(), last)
+}
@@ -0,0 +1,93 @@
+package list
+
+import "container/heap"
+
+// ExpiryQueue реализует очередь элементов по возрастанию даты удаления (min-heap по Deadline).
+//
+// Позиция элемента в куче хранится в самом элементе, поэтому удаление и обновление даты истечения
+// произвольного элемента выполняются за O(log n), а получение ближайшего к истечению - за O(1).
+type ExpiryQueue[K comparable, V any] struct {
+	entries expiryHeap[K, V]
+}
+
+// NewExpiryQueue создает новую очередь истечения
+func NewExpiryQueue[K comparable, V any]() *ExpiryQueue[K, V] {
+	return new(ExpiryQueue[K, V]).Init()
+}
+
+// Init инициализирует (или чистит) очередь истечения
+func (q *ExpiryQueue[K, V]) Init() *ExpiryQueue[K, V] {
+	for _, e := range q.entries {
+		e.expiryIndex = -1
+	}
+	q.entries = q.entries[:0]
+	return q
+}
+
+// Length возвращает количество элементов в очереди
+func (q *ExpiryQueue[K, V]) Length() int {
+	return len(q.entries)
+}
+
+// Push добавляет элемент в очередь
+func (q *ExpiryQueue[K, V]) Push(e *Entry[K, V]) {
+	heap.Push(&q.entries, e)
+}
+
+// Remove удаляет элемент из очереди, если он в ней находится
+func (q *ExpiryQueue[K, V]) Remove(e *Entry[K, V]) {
+	if !q.contains(e) {
+		return
+	}
+	heap.Remove(&q.entries, e.expiryIndex)
+}
+
+// Fix восстанавливает порядок в очереди после изменения ExpiresAt или StaleTTL у элемента
+func (q *ExpiryQueue[K, V]) Fix(e *Entry[K, V]) {
+	if !q.contains(e) {
+		return
+	}
+	heap.Fix(&q.entries, e.expiryIndex)
+}
+
+// Front возвращает элемент с ближайшей датой истечения или nil
+func (q *ExpiryQueue[K, V]) Front() *Entry[K, V] {
+	if len(q.entries) == 0 {
+		return nil
+	}
+	return q.entries[0]
+}
+
+// contains проверяет, что элемент находится именно в этой очереди
+func (q *ExpiryQueue[K, V]) contains(e *Entry[K, V]) bool {
+	return e.expiryIndex >= 0 && e.expiryIndex < len(q.entries) && q.entries[e.expiryIndex] == e
+}
+
+// expiryHeap имплементирует heap.Interface для элементов кэша
+type expiryHeap[K comparable, V any] []*Entry[K, V]
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool { return h[i].Deadline().Before(h[j].Deadline()) }
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].expiryIndex = i
+	h[j].expiryIndex = j
+}
+
+func (h *expiryHeap[K, V]) Push(x interface{}) {
+	e := x.(*Entry[K, V])
+	e.expiryIndex = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[K, V]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.expiryIndex = -1
+	*h = old[:n-1]
+	return e
+}
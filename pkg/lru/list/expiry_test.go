@@ -0,0 +1,41 @@
+package list
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiryQueue(t *testing.T) {
+	now := time.Now()
+	l := NewList[string, int]()
+	q := NewExpiryQueue[string, int]()
+
+	e1 := l.PushFront("1", 1, now.Add(3*time.Second))
+	e2 := l.PushFront("2", 2, now.Add(1*time.Second))
+	e3 := l.PushFront("3", 3, now.Add(2*time.Second))
+
+	q.Push(e1)
+	q.Push(e2)
+	q.Push(e3)
+	assert.Equal(t, 3, q.Length())
+	assert.Equal(t, e2, q.Front())
+
+	// Обновление даты истечения
+	e2.ExpiresAt = now.Add(4 * time.Second)
+	q.Fix(e2)
+	assert.Equal(t, e3, q.Front())
+
+	// Удаление произвольного элемента
+	q.Remove(e3)
+	assert.Equal(t, e1, q.Front())
+
+	// Повторное удаление ничего не ломает
+	q.Remove(e3)
+	assert.Equal(t, 2, q.Length())
+
+	q.Init()
+	assert.Nil(t, q.Front())
+	assert.Equal(t, 0, q.Length())
+}
@@ -0,0 +1,122 @@
+// Package list содержит определение двухсвязного списка, очереди истечения и их элементов для использования в LRU-кэше
+package list
+
+import "time"
+
+// LruList реализует двухсвязный список
+type LruList[K comparable, V any] struct {
+	root  Entry[K, V] // служебный элемент списка
+	len   int         // размер списка без служебного элемента root
+	stamp uint64      // последняя выданная отметка порядка, растет монотонно и не сбрасывается в Init
+}
+
+// Init инициализирует (или чистит) двухсвязный список
+func (l *LruList[K, V]) Init() *LruList[K, V] {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+	return l
+}
+
+// NewList создает новый двухсвязный список
+func NewList[K comparable, V any]() *LruList[K, V] {
+	return new(LruList[K, V]).Init()
+}
+
+// Length возвращает длину двухсвязного списка
+func (l *LruList[K, V]) Length() int {
+	return l.len
+}
+
+// Front возвращает первый элемент списка или nil
+func (l *LruList[K, V]) Front() *Entry[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back возвращает последний элемент списка или nil
+func (l *LruList[K, V]) Back() *Entry[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// lazyInit инициализирует список, проверяя, есть ли указатель хотя бы на один элемент
+func (l *LruList[K, V]) lazyInit() {
+	if l.root.next == nil {
+		l.Init()
+	}
+}
+
+// insert вставляет элемент e после at в список
+func (l *LruList[K, V]) insert(e, at *Entry[K, V]) *Entry[K, V] {
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e // меняет at
+	e.next.prev = e
+	e.list = l
+	l.len++
+	return e
+}
+
+// insertValue оборачивает функцию insert для возможности "собрать" внутри нее новый элемент с
+// заданными для него значениями
+func (l *LruList[K, V]) insertValue(k K, v V, expiresAt time.Time, at *Entry[K, V]) *Entry[K, V] {
+	return l.insert(&Entry[K, V]{Value: v, Key: k, ExpiresAt: expiresAt, expiryIndex: -1}, at)
+}
+
+// Remove удаляет e из списка
+func (l *LruList[K, V]) Remove(e *Entry[K, V]) V {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.next = nil
+	e.prev = nil
+	e.list = nil
+	l.len--
+
+	return e.Value
+}
+
+// move ставит e перед at
+func (l *LruList[K, V]) move(e, at *Entry[K, V]) {
+	if e == at {
+		return
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+}
+
+// PushFront оборачивает функцию insertValue для добавления в начало списка
+func (l *LruList[K, V]) PushFront(k K, v V, expiresAt time.Time) *Entry[K, V] {
+	l.lazyInit()
+
+	e := l.insertValue(k, v, expiresAt, &l.root)
+	e.stamp = l.nextStamp()
+	return e
+}
+
+// MoveToFront перемещает e в начало списка
+func (l *LruList[K, V]) MoveToFront(e *Entry[K, V]) {
+	if e == nil || e.list != l || l.root.next == e {
+		return
+	}
+
+	l.move(e, &l.root)
+	e.stamp = l.nextStamp()
+}
+
+// nextStamp возвращает отметку для элемента, попадающего в начало списка. Отметки элементов
+// убывают от начала списка к концу, поэтому по отметке можно найти место в списке,
+// даже если сам элемент уже удален.
+func (l *LruList[K, V]) nextStamp() uint64 {
+	l.stamp++
+	return l.stamp
+}
@@ -0,0 +1,169 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Loader загружает значение по ключу при промахе кэша (см. GetOrLoad) и возвращает его
+// вместе с TTL для записи в кэш. Нулевой TTL означает TTL по умолчанию.
+//
+// Загрузчику следует учитывать отмену ctx. По истечении таймаута загрузки (см. WithLoaderTimeout)
+// ожидающие вызовы получают context.DeadlineExceeded, не дожидаясь, пока загрузчик вернет управление.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, time.Duration, error)
+
+// loadCall описывает выполняющуюся загрузку одного ключа
+type loadCall[V any] struct {
+	done      chan struct{} // закрывается по завершении загрузки
+	deadline  time.Time     // крайний срок загрузки, нулевой - без ограничения
+	value     V
+	expiresAt time.Time
+	err       error
+}
+
+// loadGroup объединяет одновременные загрузки одного ключа в одну
+type loadGroup[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*loadCall[V]
+}
+
+// GetOrLoad возвращает значение и дату истечения записи по ключу, а при промахе загружает
+// значение вызовом loader и записывает его в кэш с параметрами opts.
+//
+// Одновременные промахи по одному ключу ожидают единственную загрузку, поэтому истечение
+// популярной записи не приводит к лавине одинаковых вычислений. Ошибка загрузчика возвращается
+// всем ожидающим и не кэшируется: следующий промах запустит новую загрузку. Если значение
+// не помещается в кэш (см. WithMaxCost), оно возвращается без записи в кэш. Если значение отклонено
+// фильтром допуска (см. WithAdmission), оно тоже не записывается, но возвращается вместе с ErrRejected.
+//
+// Загрузка не прерывается при отмене ctx отдельного вызова, так как ее результат нужен
+// всем ожидающим; сам вызов при отмене ctx возвращает ctx.Err(), а по истечении таймаута
+// загрузки - context.DeadlineExceeded.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V], opts ...PutOption) (value V, expiresAt time.Time, err error) {
+	value, expiresAt, err = c.Get(key)
+	if !errors.Is(err, ErrNotFound) {
+		return value, expiresAt, err
+	}
+
+	call, started := c.loads.join(key, c.loaderTimeout)
+	if started {
+		go c.load(context.WithoutCancel(ctx), key, loader, call, newEntrySettings(opts), true)
+	}
+
+	var timeout <-chan time.Time
+	if !call.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(call.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.expiresAt, call.err
+	case <-ctx.Done():
+		return value, time.Time{}, ctx.Err()
+	case <-timeout:
+		// Зависшая загрузка больше не объединяет промахи: следующий запустит новую
+		c.loads.forget(key, call)
+		return value, time.Time{}, context.DeadlineExceeded
+	}
+}
+
+// SetLoader регистрирует загрузчик, которым обновляются записи в фоне (см. RefreshAhead и StaleTTL).
+// Ошибки фонового обновления отбрасываются: запись продолжает отдаваться, пока не истечет.
+// Вызов с nil отменяет регистрацию.
+func (c *Cache[K, V]) SetLoader(loader Loader[K, V]) {
+	if loader == nil {
+		c.loader.Store(nil)
+		return
+	}
+	c.loader.Store(&loader)
+}
+
+// refresh запускает фоновое обновление ключа зарегистрированным загрузчиком с сохранением
+// параметров записи cfg, если загрузчик задан и ключ еще не загружается
+func (c *Cache[K, V]) refresh(key K, cfg entrySettings) {
+	loader := c.loader.Load()
+	if loader == nil || c.closed.Load() {
+		return
+	}
+
+	if call, started := c.loads.join(key, c.loaderTimeout); started {
+		go c.load(context.Background(), key, *loader, call, cfg, false)
+	}
+}
+
+// join возвращает загрузку ключа, регистрируя новую с таймаутом timeout (0 - без ограничения),
+// если ключ еще не загружается. started сообщает, что загрузка зарегистрирована этим вызовом
+// и ее нужно запустить.
+func (g *loadGroup[K, V]) join(key K, timeout time.Duration) (call *loadCall[V], started bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		return call, false
+	}
+
+	call = &loadCall[V]{done: make(chan struct{})}
+	if timeout > 0 {
+		call.deadline = time.Now().Add(timeout)
+	}
+	g.calls[key] = call
+	return call, true
+}
+
+// forget удаляет загрузку call из группы, если ключ key все еще загружается ею
+func (g *loadGroup[K, V]) forget(key K, call *loadCall[V]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// load выполняет загрузку ключа, записывает значение в кэш с параметрами cfg и сохраняет
+// результат в call. Если установлен recheck, перед загрузкой проверяется, не появилось ли
+// значение в кэше.
+func (c *Cache[K, V]) load(ctx context.Context, key K, loader Loader[K, V], call *loadCall[V], cfg entrySettings, recheck bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+
+		// Загрузка удаляется из группы после записи в кэш, чтобы следующие вызовы
+		// получили уже закэшированное значение
+		c.loads.forget(key, call)
+		close(call.done)
+	}()
+
+	// Значение могло быть записано, пока загрузка не была зарегистрирована
+	if recheck {
+		if value, expiresAt, err := c.Get(key); !errors.Is(err, ErrNotFound) {
+			call.value, call.expiresAt, call.err = value, expiresAt, err
+			return
+		}
+	}
+
+	if !call.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, call.deadline)
+		defer cancel()
+	}
+
+	value, ttl, err := loader(ctx, key)
+	if err != nil {
+		call.err = err
+		return
+	}
+
+	var item Item[V]
+	item, call.err = c.put(key, value, ttl, cfg, nil)
+	call.value, call.expiresAt = value, item.ExpiresAt
+	if errors.Is(call.err, ErrTooLarge) {
+		call.err = nil
+	}
+}
@@ -0,0 +1,556 @@
+// Package lru содержит встраиваемую реализацию потокобезопасного LRU-кэша с поддержкой TTL
+// и типизированными ключами и значениями.
+//
+// Этот же кэш используется в качестве хранилища сервисом golang-cache-lru.
+package lru
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/radix"
+)
+
+// Cache имплементирует потокобезопасный LRU-кэш с поддержкой TTL.
+//
+// Ключи распределяются по хэшу между независимыми шардами (см. WithShards), у каждого из которых
+// свой двухсвязный список, своя мапа, своя блокировка и своя доля общего размера кэша.
+// Операции над одним ключом блокируют только его шард, а GetAll и EvictAll
+// блокируют все шарды сразу, чтобы результат был согласованным.
+type Cache[K comparable, V any] struct {
+	shards     []*shard[K, V]
+	hasher     func(K) uint64
+	cost       func(K, V) int64 // nil, если стоимость записей не ограничена
+	size       int
+	maxCost    int64
+	defaultTTL time.Duration
+
+	loads         loadGroup[K, V]              // выполняющиеся загрузки GetOrLoad и фоновые обновления
+	loader        atomic.Pointer[Loader[K, V]] // загрузчик для фоновых обновлений, nil - не задан
+	loaderTimeout time.Duration                // таймаут загрузки одного ключа, 0 - без ограничения
+
+	observer func(Mutation[K, V]) // получатель сообщений об изменениях, nil - не задан
+
+	closed    atomic.Bool   // признак закрытого кэша
+	closeOnce sync.Once     // защита от повторного закрытия done
+	done      chan struct{} // закрывается в Close для остановки фоновой горутины
+	stopped   chan struct{} // закрывается фоновой горутиной при завершении
+}
+
+// New создает новый кэш размера size. Возвращает ошибку при некорректных параметрах.
+//
+// Вместе с кэшем запускается фоновая горутина, удаляющая expired записи (см. WithJanitor).
+// Чтобы остановить ее, кэш необходимо закрыть вызовом Close.
+func New[K comparable, V any](size int, opts ...Option) (*Cache[K, V], error) {
+	cfg := defaultSettings()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if err := cfg.validate(size); err != nil {
+		return nil, err
+	}
+
+	c := &Cache[K, V]{
+		shards:     make([]*shard[K, V], cfg.shards),
+		size:       size,
+		maxCost:    cfg.maxCost,
+		defaultTTL: cfg.defaultTTL,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+
+		loads:         loadGroup[K, V]{calls: make(map[K]*loadCall[V])},
+		loaderTimeout: cfg.loaderTimeout,
+	}
+
+	switch hasher := cfg.hasher.(type) {
+	case nil:
+		c.hasher = newHasher[K]()
+	case func(K) uint64:
+		c.hasher = hasher
+	default:
+		return nil, fmt.Errorf("%w: hasher type %T does not match key type", ErrInvalidOption, cfg.hasher)
+	}
+
+	switch cost := cfg.cost.(type) {
+	case nil:
+		if cfg.maxCost > 0 {
+			c.cost = defaultCost[K, V]
+		}
+	case func(K, V) int64:
+		c.cost = cost
+	default:
+		return nil, fmt.Errorf("%w: cost function type %T does not match key and value types", ErrInvalidOption, cfg.cost)
+	}
+
+	switch observer := cfg.observer.(type) {
+	case nil:
+	case func(Mutation[K, V]):
+		c.observer = observer
+	default:
+		return nil, fmt.Errorf("%w: observer type %T does not match key and value types", ErrInvalidOption, cfg.observer)
+	}
+
+	var indexKey func(K) string
+	if cfg.prefixIndex {
+		if _, ok := any(*new(K)).(string); !ok {
+			return nil, fmt.Errorf("%w: prefix index requires string keys", ErrInvalidOption)
+		}
+		indexKey = func(key K) string { return any(key).(string) }
+	}
+
+	var newPolicy PolicyFactory[K, V]
+	switch factory := cfg.policyFactory.(type) {
+	case nil:
+		var err error
+		if newPolicy, err = newPolicyFactory[K, V](cfg.policy); err != nil {
+			return nil, err
+		}
+	case PolicyFactory[K, V]:
+		newPolicy = factory
+	default:
+		return nil, fmt.Errorf("%w: eviction policy type %T does not match key and value types", ErrInvalidOption, cfg.policyFactory)
+	}
+
+	// Общие размер и лимит стоимости делятся между шардами,
+	// остаток распределяется по единице на первые шарды
+	for i := range c.shards {
+		shardSize := size / cfg.shards
+		if i < size%cfg.shards {
+			shardSize++
+		}
+		shardMaxCost := cfg.maxCost / int64(cfg.shards)
+		if int64(i) < cfg.maxCost%int64(cfg.shards) {
+			shardMaxCost++
+		}
+		c.shards[i] = newShard(shardSize, shardMaxCost, newPolicy, cfg.promote)
+		c.shards[i].observer = c.observer
+		if cfg.admission {
+			c.shards[i].admission = newTinyLFU(shardSize, c.hasher)
+		}
+		if cfg.prefixIndex {
+			c.shards[i].index = radix.New[*list.Entry[K, V]]()
+			c.shards[i].indexKey = indexKey
+		}
+	}
+
+	// Тикер, который будет раз в janitorInterval времени
+	// запускать deleteExpired() для удаления старых элементов
+	go func(done <-chan struct{}) {
+		defer close(c.stopped)
+
+		ticker := time.NewTicker(cfg.janitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				for _, s := range c.shards {
+					s.deleteExpired(cfg.janitorBudget)
+				}
+			}
+		}
+	}(c.done)
+
+	return c, nil
+}
+
+// Close останавливает фоновую очистку и закрывает кэш. После закрытия все операции
+// возвращают ErrClosed. Close ожидает завершения фоновой горутины, но не дольше, чем
+// живет ctx. Повторный вызов Close безопасен.
+func (c *Cache[K, V]) Close(ctx context.Context) error {
+	c.closed.Store(true)
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	select {
+	case <-c.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Put записывает значение в кэш. Нулевой ttl означает TTL по умолчанию (см. WithDefaultTTL).
+// Параметры отдельной записи (например, Sliding) передаются опциями opts.
+// Если стоимость записи превышает лимит (см. WithMaxCost), возвращает ErrTooLarge,
+// а если новая запись отклонена фильтром допуска (см. WithAdmission) - ErrRejected.
+func (c *Cache[K, V]) Put(key K, value V, ttl time.Duration, opts ...PutOption) error {
+	_, err := c.put(key, value, ttl, newEntrySettings(opts), nil)
+	return err
+}
+
+// PutItem записывает значение в кэш так же, как Put, и возвращает записанную запись
+// с ее датой истечения и версией. Если запись отклонена фильтром допуска (см. WithAdmission),
+// возвращает ErrRejected.
+func (c *Cache[K, V]) PutItem(key K, value V, ttl time.Duration, opts ...PutOption) (Item[V], error) {
+	return c.put(key, value, ttl, newEntrySettings(opts), nil)
+}
+
+// CompareAndSwap записывает значение, только если текущая версия записи равна expectedVersion,
+// и возвращает записанную запись с новой версией. Если версия записи отличается, возвращает
+// ErrVersionMismatch; для отсутствующих и истекших записей - ErrNotFound и ErrExpired.
+// Проверка и запись выполняются атомарно под блокировкой шарда.
+func (c *Cache[K, V]) CompareAndSwap(key K, expectedVersion uint64, value V, ttl time.Duration, opts ...PutOption) (Item[V], error) {
+	return c.put(key, value, ttl, newEntrySettings(opts), func(ent *list.Entry[K, V], err error) error {
+		if err != nil {
+			return err
+		}
+		if ent.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+		return nil
+	})
+}
+
+// PutIfAbsent записывает значение, только если записи с таким ключом нет или она истекла,
+// и возвращает записанную запись. Если запись уже есть, возвращает ErrExists.
+// Проверка и запись выполняются атомарно под блокировкой шарда.
+func (c *Cache[K, V]) PutIfAbsent(key K, value V, ttl time.Duration, opts ...PutOption) (Item[V], error) {
+	return c.put(key, value, ttl, newEntrySettings(opts), func(ent *list.Entry[K, V], err error) error {
+		if err == nil && !ent.Expired(time.Now()) {
+			return ErrExists
+		}
+		return nil
+	})
+}
+
+// Replace записывает значение, только если запись с таким ключом уже есть и не истекла,
+// и возвращает записанную запись. Для отсутствующих и истекших записей (в том числе
+// устаревших, см. StaleTTL) возвращает ErrNotFound и ErrExpired.
+// Проверка и запись выполняются атомарно под блокировкой шарда.
+func (c *Cache[K, V]) Replace(key K, value V, ttl time.Duration, opts ...PutOption) (Item[V], error) {
+	return c.put(key, value, ttl, newEntrySettings(opts), func(ent *list.Entry[K, V], err error) error {
+		if err == nil && ent.Expired(time.Now()) {
+			return ErrExpired
+		}
+		return err
+	})
+}
+
+// precondition проверяет текущую запись перед условной записью. ent и err - результат
+// поиска записи в шарде (см. shard.get); ненулевая ошибка отменяет запись и возвращается вызывающему.
+type precondition[K comparable, V any] func(ent *list.Entry[K, V], err error) error
+
+// put записывает значение с параметрами cfg в кэш, если выполняется условие cond
+// (nil - запись безусловная), и возвращает записанную запись
+func (c *Cache[K, V]) put(key K, value V, ttl time.Duration, cfg entrySettings, cond precondition[K, V]) (item Item[V], err error) {
+	if c.closed.Load() {
+		return item, ErrClosed
+	}
+	if ttl < 0 {
+		return item, ErrInvalidTTL
+	}
+	if err := cfg.validate(); err != nil {
+		return item, err
+	}
+
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+
+	// Оценка стоимости может быть дорогой (например, кодирование в JSON), поэтому выполняется до lock
+	cost := c.entryCost(key, value)
+
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if cond != nil {
+		if err := cond(s.get(key, now)); err != nil {
+			return item, err
+		}
+	}
+
+	ent, err := s.put(key, value, cost, c.expiresAt(now, ttl), ttl, cfg)
+	if ent == nil {
+		return item, err
+	}
+
+	return itemOf(ent, now), nil
+}
+
+// Item описывает запись кэша, возвращаемую GetItem
+type Item[V any] struct {
+	Value     V
+	ExpiresAt time.Time // Дата истечения, нулевая - запись не истекает
+	Stale     bool      // Запись истекла и отдается в течение StaleTTL как устаревшая
+	Version   uint64    // Версия записи, увеличивается при каждой записи значения
+}
+
+// Get возвращает значение и дату истечения записи по ключу.
+// Для отсутствующих записей возвращает ErrNotFound, для истекших, но еще не удаленных - ErrExpired.
+// Устаревшие записи (см. StaleTTL)
+// возвращаются как обычные; отличить их позволяет GetItem.
+func (c *Cache[K, V]) Get(key K) (value V, expiresAt time.Time, err error) {
+	item, err := c.GetItem(key)
+	return item.Value, item.ExpiresAt, err
+}
+
+// GetItem возвращает запись по ключу. Для отсутствующих записей возвращает ErrNotFound,
+// для истекших, но еще не удаленных - ErrExpired.
+//
+// Чтение не ждет других читателей: шард блокируется на чтение, а обращение откладывается
+// в буфер шарда. Буфер применяется пачкой при заполнении или при следующей записи в шард,
+// поэтому перенос записи в начало списка (см. WithPromotion) происходит с задержкой.
+// Исключение - записи со скользящим истечением (см. Sliding): их TTL продлевается сразу,
+// для чего шард блокируется на запись.
+//
+// Чтение записи, которую пора обновить (см. RefreshAhead и StaleTTL), запускает ее фоновое
+// обновление зарегистрированным загрузчиком.
+func (c *Cache[K, V]) GetItem(key K) (item Item[V], err error) {
+	if c.closed.Load() {
+		return item, ErrClosed
+	}
+
+	s := c.shard(key)
+	s.mu.RLock()
+
+	now := time.Now()
+	ent, err := s.get(key, now)
+	if err != nil {
+		s.mu.RUnlock()
+
+		// Фильтр допуска учитывает и обращения к отсутствующим ключам
+		if s.admission != nil {
+			s.mu.Lock()
+			s.recordAccess(key)
+			s.mu.Unlock()
+		}
+		return item, err
+	}
+	if ent.Sliding && !ent.Expired(now) {
+		s.mu.RUnlock()
+		return c.getSliding(s, key, now)
+	}
+
+	item = itemOf(ent, now)
+	refresh, refreshCfg := ent.RefreshDue(now), entrySettingsOf(ent)
+	full := s.reads.add(ent)
+	s.mu.RUnlock()
+
+	if full {
+		s.mu.Lock()
+		s.applyReads()
+		s.mu.Unlock()
+	}
+	if refresh {
+		c.refresh(key, refreshCfg)
+	}
+
+	return item, nil
+}
+
+// getSliding читает запись со скользящим истечением под блокировкой шарда на запись
+// и продлевает ее TTL
+func (c *Cache[K, V]) getSliding(s *shard[K, V], key K, now time.Time) (item Item[V], err error) {
+	s.mu.Lock()
+
+	// Запись могла измениться, пока шард не был заблокирован
+	ent, err := s.get(key, now)
+	if err != nil {
+		s.recordAccess(key)
+		s.mu.Unlock()
+		return item, err
+	}
+
+	if !ent.Expired(now) {
+		s.slide(ent, now)
+	}
+	s.access(ent)
+
+	item = itemOf(ent, now)
+	refresh, refreshCfg := ent.RefreshDue(now), entrySettingsOf(ent)
+	s.mu.Unlock()
+
+	if refresh {
+		c.refresh(key, refreshCfg)
+	}
+
+	return item, nil
+}
+
+// itemOf возвращает запись кэша, соответствующую элементу, на момент now
+func itemOf[K comparable, V any](ent *list.Entry[K, V], now time.Time) Item[V] {
+	return Item[V]{
+		Value:     ent.Value,
+		ExpiresAt: ent.ExpiresAt,
+		Stale:     ent.Expired(now),
+		Version:   ent.Version,
+	}
+}
+
+// Evict удаляет запись по ключу и возвращает ее значение.
+// Для отсутствующих записей возвращает ErrNotFound. Истекшая, но еще не удаленная запись
+// удаляется, но ее значение не возвращается, а вызов возвращает ErrExpired.
+func (c *Cache[K, V]) Evict(key K) (value V, err error) {
+	if c.closed.Load() {
+		return value, ErrClosed
+	}
+
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ent, ok := s.items[key]
+	if !ok {
+		return value, ErrNotFound
+	}
+
+	s.evict(ent)
+	if ent.Dead(time.Now()) {
+		return value, ErrExpired
+	}
+	return ent.Value, nil
+}
+
+// GetAll возвращает все наполнение кэша в виде двух слайсов: слайса ключей и слайса значений.
+// Пары ключ-значения из кэша располагаются на соответствующих позициях в слайсах.
+//
+// Все шарды блокируются на время сбора, поэтому результат соответствует одному моменту времени.
+// Внутри каждого шарда пары идут от старейшей к новейшей, шарды следуют друг за другом.
+func (c *Cache[K, V]) GetAll() (keys []K, values []V, err error) {
+	if c.closed.Load() {
+		return nil, nil, ErrClosed
+	}
+
+	c.lockAll()
+	defer c.unlockAll()
+
+	total := 0
+	for _, s := range c.shards {
+		total += len(s.items)
+	}
+
+	keys = make([]K, 0, total)
+	values = make([]V, 0, total)
+
+	now := time.Now()
+	for _, s := range c.shards {
+		keys, values = s.appendAll(keys, values, now)
+	}
+
+	return keys, values, nil
+}
+
+// EvictAll удаляет все записи из кэша
+func (c *Cache[K, V]) EvictAll() error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	c.lockAll()
+	defer c.unlockAll()
+
+	for _, s := range c.shards {
+		s.clear()
+	}
+	if c.observer != nil {
+		c.observer(Mutation[K, V]{Kind: MutationClear})
+	}
+
+	return nil
+}
+
+// Len возвращает количество записей в кэше, включая истекшие, но еще не удаленные
+func (c *Cache[K, V]) Len() int {
+	c.lockAll()
+	defer c.unlockAll()
+
+	total := 0
+	for _, s := range c.shards {
+		total += len(s.items)
+	}
+	return total
+}
+
+// Stats описывает текущее заполнение кэша
+type Stats struct {
+	Len     int   // Количество записей, включая истекшие, но еще не удаленные
+	Size    int   // Максимальное количество записей
+	Cost    int64 // Суммарная стоимость записей
+	MaxCost int64 // Лимит суммарной стоимости записей, 0 - без ограничения
+
+	Admitted uint64 // Количество новых записей, допущенных фильтром допуска в переполненный кэш
+	Rejected uint64 // Количество новых записей, отклоненных фильтром допуска
+}
+
+// Stats возвращает текущее заполнение кэша
+func (c *Cache[K, V]) Stats() (Stats, error) {
+	if c.closed.Load() {
+		return Stats{}, ErrClosed
+	}
+
+	c.lockAll()
+	defer c.unlockAll()
+
+	stats := Stats{
+		Size:    c.size,
+		MaxCost: c.maxCost,
+	}
+	for _, s := range c.shards {
+		stats.Len += len(s.items)
+		stats.Cost += s.cost
+		if s.admission != nil {
+			stats.Admitted += s.admission.admitted
+			stats.Rejected += s.admission.rejected
+		}
+	}
+
+	return stats, nil
+}
+
+// entryCost оценивает стоимость записи. Если лимит стоимости не задан
+// и функция оценки не указана, стоимость не считается.
+func (c *Cache[K, V]) entryCost(key K, value V) int64 {
+	if c.cost == nil {
+		return 0
+	}
+	return c.cost(key, value)
+}
+
+// expiresAt вычисляет дату истечения записи с указанным ttl.
+// Нулевая дата означает, что запись не истекает.
+func (c *Cache[K, V]) expiresAt(now time.Time, ttl time.Duration) time.Time {
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl == 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// shard возвращает шард, отвечающий за ключ
+func (c *Cache[K, V]) shard(key K) *shard[K, V] {
+	return c.shards[c.shardIndex(key)]
+}
+
+// shardIndex возвращает номер шарда, отвечающего за ключ
+func (c *Cache[K, V]) shardIndex(key K) int {
+	if len(c.shards) == 1 {
+		return 0
+	}
+	return int(c.hasher(key) % uint64(len(c.shards)))
+}
+
+// lockAll блокирует все шарды. Порядок блокировки всегда одинаковый, чтобы избежать deadlock.
+func (c *Cache[K, V]) lockAll() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+	}
+}
+
+// unlockAll разблокирует все шарды
+func (c *Cache[K, V]) unlockAll() {
+	for i := len(c.shards) - 1; i >= 0; i-- {
+		c.shards[i].mu.Unlock()
+	}
+}
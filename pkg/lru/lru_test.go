@@ -0,0 +1,1128 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+func TestNew_InvalidOptions(t *testing.T) {
+	_, err := New[string, int](0)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = New[string, int](2, WithShards(3))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = New[string, int](2, WithJanitor(0, 10))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = New[string, int](2, WithHasher(func(int) uint64 { return 0 }))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = New[string, int](2, WithPolicy("random"))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = New[int, int](2, WithPrefixIndex())
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestCache_PutGetEvict(t *testing.T) {
+	c, err := New[string, int](2)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", 1, 0))
+	require.NoError(t, c.Put("b", 2, time.Minute))
+	assert.ErrorIs(t, c.Put("c", 3, -time.Second), ErrInvalidTTL)
+
+	value, expiresAt, err := c.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+	assert.True(t, expiresAt.IsZero(), "без TTL по умолчанию запись не истекает")
+
+	// Вытеснение давно не использовавшегося элемента: "a" только что прочитан
+	require.NoError(t, c.Put("c", 3, 0))
+	_, _, err = c.Get("b")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	value, err = c.Evict("a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	_, err = c.Evict("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCache_WithoutPromotion(t *testing.T) {
+	c, err := New[string, int](2, WithPromotion(false))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", 1, 0))
+	require.NoError(t, c.Put("b", 2, 0))
+	_, _, err = c.Get("a")
+	require.NoError(t, err)
+
+	// Чтение не влияет на порядок, вытесняется первая добавленная запись
+	require.NoError(t, c.Put("c", 3, 0))
+	_, _, err = c.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, err = c.Get("b")
+	assert.NoError(t, err)
+}
+
+func TestCache_ConcurrentReads(t *testing.T) {
+	c, err := New[int, int](readBufferSize, WithShards(2))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	for i := 0; i < readBufferSize; i++ {
+		require.NoError(t, c.Put(i, i, 0))
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10*readBufferSize; i++ {
+				value, _, err := c.Get(i % readBufferSize)
+				assert.NoError(t, err)
+				assert.Equal(t, i%readBufferSize, value)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Накопленные чтения применяются при записи и не теряют записи
+	require.NoError(t, c.Put(readBufferSize, 0, 0))
+	assert.Equal(t, readBufferSize, c.Len())
+}
+
+func TestCache_Expiration(t *testing.T) {
+	c, err := New[string, int](10, WithDefaultTTL(10*time.Millisecond), WithJanitor(time.Millisecond, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", 1, 0))
+	require.NoError(t, c.Put("b", 2, time.Minute))
+
+	assert.Eventually(t, func() bool {
+		return c.Len() == 1
+	}, time.Second, time.Millisecond)
+
+	_, _, err = c.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCache_ExpiredError(t *testing.T) {
+	c, err := New[string, *int](10, WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", nil, time.Millisecond))
+	require.NoError(t, c.Put("b", nil, 0))
+	time.Sleep(5 * time.Millisecond)
+
+	// Истекшая, но еще не удаленная запись отличается от отсутствующей
+	_, _, err = c.Get("a")
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = c.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrExpired)
+
+	// nil - обычное значение
+	value, _, err := c.Get("b")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	_, err = c.Evict("a")
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = c.Evict("a")
+	assert.NotErrorIs(t, err, ErrExpired)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCache_SlidingExpiration(t *testing.T) {
+	c, err := New[string, int](10, WithJanitor(time.Millisecond, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("session", 1, 50*time.Millisecond, Sliding()))
+	require.NoError(t, c.Put("absolute", 2, 50*time.Millisecond))
+
+	// Регулярные чтения продлевают только запись со скользящим истечением
+	for i := 0; i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		_, expiresAt, err := c.Get("session")
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(50*time.Millisecond), expiresAt, 10*time.Millisecond)
+	}
+	_, _, err = c.Get("absolute")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Без чтений запись истекает и удаляется фоновой очисткой
+	assert.Eventually(t, func() bool {
+		return c.Len() == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestCache_ShardedGetAll(t *testing.T) {
+	c, err := New[int, int](100, WithShards(4))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	for i := 0; i < 50; i++ {
+		require.NoError(t, c.Put(i, i*10, 0))
+	}
+
+	keys, values, err := c.GetAll()
+	require.NoError(t, err)
+	assert.Len(t, keys, 50)
+	for i := range keys {
+		assert.Equal(t, keys[i]*10, values[i])
+	}
+
+	require.NoError(t, c.EvictAll())
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCache_ShardSizes(t *testing.T) {
+	c, err := New[string, int](10, WithShards(3))
+	require.NoError(t, err)
+
+	// Остаток от деления размера распределяется по одному элементу на первые шарды
+	sizes := make([]int, 0, len(c.shards))
+	for _, s := range c.shards {
+		sizes = append(sizes, s.size)
+	}
+	assert.Equal(t, []int{4, 3, 3}, sizes)
+}
+
+func TestCache_ShardDistribution(t *testing.T) {
+	const shards, keys = 4, 4000
+	// Размер с запасом, чтобы неравномерность распределения не вызывала вытеснений
+	c, err := New[string, int](2*keys, WithShards(shards))
+	require.NoError(t, err)
+
+	for i := range keys {
+		require.NoError(t, c.Put(fmt.Sprintf("key-%d", i), i, 0))
+	}
+
+	// Каждый ключ хранится в своем шарде, а ключи распределены между шардами примерно поровну
+	for _, s := range c.shards {
+		assert.InDelta(t, keys/shards, len(s.items), keys/shards/4)
+		for key := range s.items {
+			assert.Same(t, s, c.shard(key))
+		}
+	}
+	assert.Equal(t, keys, c.Len())
+
+	for i := range keys {
+		value, _, err := c.Get(fmt.Sprintf("key-%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, i, value)
+	}
+}
+
+func TestCache_ConcurrentShards(t *testing.T) {
+	const size = 100
+	c, err := New[string, int](size, WithShards(8))
+	require.NoError(t, err)
+
+	// Одновременные операции над разными шардами и над всеми шардами сразу (запускать с -race)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := fmt.Sprintf("key-%d", (g*1000+i)%300)
+				switch i % 4 {
+				case 0, 1:
+					assert.NoError(t, c.Put(key, i, 0))
+				case 2:
+					if _, _, err := c.Get(key); err != nil {
+						assert.ErrorIs(t, err, ErrNotFound)
+					}
+				case 3:
+					if _, err := c.Evict(key); err != nil {
+						assert.ErrorIs(t, err, ErrNotFound)
+					}
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			keys, values, err := c.GetAll()
+			assert.NoError(t, err)
+			assert.Len(t, values, len(keys))
+			assert.LessOrEqual(t, len(keys), size)
+			if i%10 == 0 {
+				assert.NoError(t, c.EvictAll())
+			}
+		}
+	}()
+	wg.Wait()
+
+	for _, s := range c.shards {
+		assert.LessOrEqual(t, len(s.items), s.size)
+		assert.Equal(t, len(s.items), s.evictList.Length())
+	}
+}
+
+func TestCache_Close(t *testing.T) {
+	c, err := New[string, int](2, WithJanitor(time.Millisecond, 10))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Put("a", 1, 0))
+	require.NoError(t, c.Close(context.Background()))
+	require.NoError(t, c.Close(context.Background()), "повторное закрытие безопасно")
+
+	assert.ErrorIs(t, c.Put("a", 1, 0), ErrClosed)
+	_, _, err = c.Get("a")
+	assert.ErrorIs(t, err, ErrClosed)
+	_, err = c.Evict("a")
+	assert.ErrorIs(t, err, ErrClosed)
+	_, _, err = c.GetAll()
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, c.EvictAll(), ErrClosed)
+}
+
+func TestCache_MaxCost(t *testing.T) {
+	c, err := New[string, string](10, WithMaxCost(10), WithCost(func(k string, v string) int64 {
+		return int64(len(v))
+	}))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", "1234", 0))
+	require.NoError(t, c.Put("b", "1234", 0))
+	assert.ErrorIs(t, c.Put("c", "12345678901", 0), ErrTooLarge)
+
+	// Не помещается по стоимости - вытесняется старейшая запись
+	require.NoError(t, c.Put("c", "1234", 0))
+	_, _, err = c.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Рост существующей записи также вытесняет старейшие
+	require.NoError(t, c.Put("c", "12345678", 0))
+	_, _, err = c.Get("b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Len: 1, Size: 10, Cost: 8, MaxCost: 10}, stats)
+}
+
+func TestDefaultCost(t *testing.T) {
+	assert.Equal(t, int64(3+5), defaultCost("key", "value"))
+	assert.Equal(t, int64(3+len(`{"a":1}`)), defaultCost[string, interface{}]("key", map[string]int{"a": 1}))
+}
+
+func TestCache_LFUPolicy(t *testing.T) {
+	c, err := New[string, int](3, WithPolicy(PolicyLFU))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", 1, 0))
+	require.NoError(t, c.Put("b", 2, 0))
+	require.NoError(t, c.Put("c", 3, 0))
+
+	// "a" и "c" используются чаще, чем "b"
+	for i := 0; i < 3; i++ {
+		_, _, err = c.Get("a")
+		require.NoError(t, err)
+	}
+	_, _, err = c.Get("c")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Put("d", 4, 0))
+	_, _, err = c.Get("b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Новая запись "d" реже всех, поэтому вытесняется следующей
+	require.NoError(t, c.Put("e", 5, 0))
+	_, _, err = c.Get("d")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"a", "c", "e"} {
+		_, _, err = c.Get(key)
+		assert.NoError(t, err, key)
+	}
+}
+
+func TestCache_Admission(t *testing.T) {
+	c, err := New[string, int](2, WithAdmission())
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", 1, 0))
+	require.NoError(t, c.Put("b", 2, 0))
+	for i := 0; i < 5; i++ {
+		_, _, _ = c.Get("a")
+		_, _, _ = c.Get("b")
+	}
+
+	// Однократный ключ не вытесняет популярные, а отказ возвращается ошибкой
+	item, err := c.PutItem("scan", 3, 0)
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Zero(t, item.Version)
+	_, _, err = c.Get("scan")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Ключ, к которому обращаются чаще, допускается
+	for i := 0; i < 10; i++ {
+		_, _, _ = c.Get("hot")
+	}
+	require.NoError(t, c.Put("hot", 4, 0))
+	_, _, err = c.Get("hot")
+	assert.NoError(t, err)
+
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.Admitted)
+	assert.Equal(t, uint64(1), stats.Rejected)
+}
+
+func TestTinyLFU_Estimate(t *testing.T) {
+	// Окно сброса частот намного больше количества обращений в тесте
+	f := newTinyLFU[string](1000, newHasher[string]())
+
+	f.record("once")
+	for i := 0; i < 10; i++ {
+		f.record("hot")
+	}
+
+	assert.Equal(t, uint8(0), f.estimate("never"))
+	assert.Equal(t, uint8(1), f.estimate("once"))
+	assert.Equal(t, uint8(10), f.estimate("hot"))
+	assert.True(t, f.admit("hot", "once"))
+	assert.False(t, f.admit("once", "hot"))
+}
+
+func TestCache_AdmissionLargeCapacity(t *testing.T) {
+	const size = 1000
+	c, err := New[string, int](size, WithAdmission(), WithShards(1))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	for i := 0; i < size; i++ {
+		require.NoError(t, c.Put(fmt.Sprintf("k%d", i), i, 0))
+	}
+
+	// Ключи, встреченные однажды, не вытесняют записи (кроме редких ложных срабатываний
+	// doorkeeper и sketch), а часто запрашиваемый ключ - вытесняет
+	rejected := 0
+	for i := 0; i < 100; i++ {
+		if errors.Is(c.Put(fmt.Sprintf("scan%d", i), 0, 0), ErrRejected) {
+			rejected++
+		}
+	}
+	assert.Greater(t, rejected, 80)
+	for i := 0; i < 10; i++ {
+		_, _, _ = c.Get("hot")
+	}
+	require.NoError(t, c.Put("hot", 1, 0))
+	_, _, err = c.Get("hot")
+	assert.NoError(t, err)
+}
+
+func TestCache_ARCPolicy(t *testing.T) {
+	c, err := New[string, int](3, WithPolicy(PolicyARC), WithDefaultTTL(time.Minute))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	// "a" используется повторно и попадает в t2
+	require.NoError(t, c.Put("a", 1, 0))
+	_, _, err = c.Get("a")
+	require.NoError(t, err)
+
+	// Поток однократных ключей вытесняет только записи из t1
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Put(fmt.Sprint("scan", i), i, 0))
+	}
+	_, _, err = c.Get("a")
+	assert.NoError(t, err)
+
+	// Ключ, недавно вытесненный из t1, при повторной записи попадает в t2
+	require.NoError(t, c.Put("scan7", 7, 0))
+	require.NoError(t, c.Put("b", 2, 0))
+	require.NoError(t, c.Put("c", 3, 0))
+	_, _, err = c.Get("scan7")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, c.Len())
+}
+
+func TestARCPolicy_Ghosts(t *testing.T) {
+	order := list.NewList[string, int]()
+	p := NewARCPolicy[string, int](order, 2).(*arcPolicy[string, int])
+
+	insert := func(key string) *list.Entry[string, int] {
+		e := order.PushFront(key, 0, time.Time{})
+		p.OnInsert(e)
+		return e
+	}
+	remove := func(e *list.Entry[string, int]) {
+		order.Remove(e)
+		p.OnRemove(e)
+	}
+
+	// Кандидат, предложенный фильтру допуска и отклоненный им, при ручном удалении не становится призраком
+	a := insert("a")
+	insert("b")
+	require.Equal(t, a, p.Victim(nil))
+	remove(a)
+	assert.Zero(t, p.b1.Length())
+
+	// Вытесненная запись становится призраком
+	c := insert("c")
+	victim := p.Victim(c)
+	p.OnEvict(victim)
+	remove(victim)
+	assert.Equal(t, 1, p.b1.Length())
+
+	// Вставки в неполный шард (без вытеснений) тоже ограничивают призраков: |t1| + |b1| <= c
+	remove(c)
+	insert("d")
+	insert("e")
+	assert.Equal(t, 2, p.t1.Length())
+	assert.Zero(t, p.b1.Length())
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c, err := New[string, int](10, WithLoaderTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, time.Duration, error) {
+		calls.Add(1)
+		<-release
+		return 42, time.Minute, nil
+	}
+
+	// Одновременные промахи ожидают одну загрузку
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, expiresAt, err := c.GetOrLoad(context.Background(), "a", loader)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+			assert.False(t, expiresAt.IsZero())
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Загруженное значение записано в кэш
+	value, _, err := c.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	// Ошибка загрузчика возвращается, но не кэшируется
+	errLoad := errors.New("load failed")
+	_, _, err = c.GetOrLoad(context.Background(), "b", func(context.Context, string) (int, time.Duration, error) {
+		return 0, 0, errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+	_, _, err = c.Get("b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Загрузка ограничена таймаутом
+	_, _, err = c.GetOrLoad(context.Background(), "c", func(ctx context.Context, _ string) (int, time.Duration, error) {
+		<-ctx.Done()
+		return 0, 0, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Ожидающие не зависят от загрузчика, который не учитывает отмену ctx
+	stuck := make(chan struct{})
+	defer close(stuck)
+	ignoring := func(context.Context, string) (int, time.Duration, error) {
+		<-stuck
+		return 0, 0, nil
+	}
+	start := time.Now()
+	_, _, err = c.GetOrLoad(context.Background(), "e", ignoring)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Следующий промах не ждет зависшую загрузку, а запускает новую
+	value, _, err = c.GetOrLoad(context.Background(), "e", func(context.Context, string) (int, time.Duration, error) {
+		return 5, 0, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, value)
+
+	// Ожидающий с собственным ctx освобождается по его отмене
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = c.GetOrLoad(ctx, "f", ignoring)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Паника загрузчика превращается в ошибку
+	_, _, err = c.GetOrLoad(context.Background(), "d", func(context.Context, string) (int, time.Duration, error) {
+		panic("boom")
+	})
+	assert.ErrorIs(t, err, ErrLoaderPanic)
+}
+
+func TestCache_RefreshAheadAndStale(t *testing.T) {
+	c, err := New[string, int](10, WithJanitor(time.Millisecond, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	var calls atomic.Int32
+	c.SetLoader(func(context.Context, string) (int, time.Duration, error) {
+		return int(calls.Add(1)) * 10, 100 * time.Millisecond, nil
+	})
+
+	// В окне упреждающего обновления отдается текущее значение и запускается одно обновление
+	require.NoError(t, c.Put("a", 1, 30*time.Millisecond, RefreshAhead(20*time.Millisecond)))
+	time.Sleep(15 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		value, _, err := c.Get("a")
+		require.NoError(t, err)
+		assert.Contains(t, []int{1, 10}, value)
+	}
+	assert.Eventually(t, func() bool {
+		value, _, _ := c.Get("a")
+		return value == 10
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// После истечения запись отдается как устаревшая, пока не пройдет StaleTTL
+	c.SetLoader(nil)
+	require.NoError(t, c.Put("b", 2, 10*time.Millisecond, StaleTTL(time.Minute)))
+	time.Sleep(20 * time.Millisecond)
+	item, err := c.GetItem("b")
+	require.NoError(t, err)
+	assert.Equal(t, 2, item.Value)
+	assert.True(t, item.Stale)
+
+	// Зарегистрированный загрузчик обновляет устаревшую запись в фоне
+	c.SetLoader(func(context.Context, string) (int, time.Duration, error) {
+		return 20, time.Minute, nil
+	})
+	_, err = c.GetItem("b")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		item, err := c.GetItem("b")
+		return err == nil && item.Value == 20 && !item.Stale
+	}, time.Second, time.Millisecond)
+
+	// Без StaleTTL истекшая запись не отдается
+	require.NoError(t, c.Put("c", 3, 5*time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	_, err = c.GetItem("c")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCache_CompareAndSwap(t *testing.T) {
+	c, err := New[string, int](10)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	_, err = c.CompareAndSwap("a", 1, 1, 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	first, err := c.PutItem("a", 1, 0)
+	require.NoError(t, err)
+	assert.NotZero(t, first.Version)
+
+	// Успешная замена увеличивает версию
+	second, err := c.CompareAndSwap("a", first.Version, 2, 0)
+	require.NoError(t, err)
+	assert.Greater(t, second.Version, first.Version)
+
+	// Замена по устаревшей версии отклоняется
+	_, err = c.CompareAndSwap("a", first.Version, 3, 0)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	item, err := c.GetItem("a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, item.Value)
+	assert.Equal(t, second.Version, item.Version)
+
+	// Версия не повторяется после удаления и повторной записи ключа
+	_, err = c.Evict("a")
+	require.NoError(t, err)
+	third, err := c.PutItem("a", 1, 0)
+	require.NoError(t, err)
+	assert.Greater(t, third.Version, second.Version)
+}
+
+func TestCache_PutIfAbsentAndReplace(t *testing.T) {
+	c, err := New[string, int](10, WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	_, err = c.Replace("a", 1, 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Первая запись побеждает
+	_, err = c.PutIfAbsent("a", 1, 0)
+	require.NoError(t, err)
+	_, err = c.PutIfAbsent("a", 2, 0)
+	assert.ErrorIs(t, err, ErrExists)
+
+	item, err := c.Replace("a", 3, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, item.Value)
+
+	// Истекшая запись считается отсутствующей
+	require.NoError(t, c.Put("b", 1, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, err = c.Replace("b", 2, 0)
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = c.PutIfAbsent("b", 2, 0)
+	assert.NoError(t, err)
+
+	value, _, err := c.Get("b")
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+}
+
+func TestCache_Update(t *testing.T) {
+	c, err := New[string, int](10, WithShards(2), WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	incr := func(item Item[int], err error) (int, error) {
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		return item.Value + 1, nil
+	}
+
+	// Одновременные обновления не теряются
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Update("a", incr, time.Hour)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	item, err := c.GetItem("a")
+	require.NoError(t, err)
+	assert.Equal(t, 100, item.Value)
+
+	// Дата истечения существующей записи сохраняется
+	updated, err := c.Update("a", incr, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, item.ExpiresAt, updated.ExpiresAt)
+
+	// Ошибка функции отменяет запись
+	errStop := errors.New("stop")
+	_, err = c.Update("a", func(Item[int], error) (int, error) { return 0, errStop }, 0)
+	assert.ErrorIs(t, err, errStop)
+	value, _, err := c.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, 101, value)
+
+	// Истекшая запись создается заново
+	require.NoError(t, c.Put("b", 5, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	updated, err = c.Update("b", incr, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Value)
+	assert.False(t, updated.Stale)
+}
+
+func TestCache_Batch(t *testing.T) {
+	c, err := New[string, int](40, WithShards(4), WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	items, errs := c.MPut([]PutEntry[string, int]{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 2, TTL: time.Millisecond},
+		{Key: "c", Value: 3, TTL: -1},
+		{Key: "d", Value: 4, TTL: time.Hour, Options: []PutOption{Sliding()}},
+	})
+	require.Len(t, items, 4)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.ErrorIs(t, errs[2], ErrInvalidTTL)
+	assert.NoError(t, errs[3])
+	assert.NotZero(t, items[0].Version)
+	assert.False(t, items[3].ExpiresAt.IsZero())
+
+	time.Sleep(5 * time.Millisecond)
+
+	// Результаты на позициях ключей, включая промахи
+	items, errs = c.MGet([]string{"d", "missing", "b", "a"})
+	assert.NoError(t, errs[0])
+	assert.Equal(t, 4, items[0].Value)
+	assert.ErrorIs(t, errs[1], ErrNotFound)
+	assert.ErrorIs(t, errs[2], ErrExpired)
+	assert.NoError(t, errs[3])
+	assert.Equal(t, 1, items[3].Value)
+
+	values, errs := c.MEvict([]string{"a", "b", "missing"})
+	assert.NoError(t, errs[0])
+	assert.Equal(t, 1, values[0])
+	assert.ErrorIs(t, errs[1], ErrExpired)
+	assert.ErrorIs(t, errs[2], ErrNotFound)
+	assert.Equal(t, 1, c.Len())
+
+	require.NoError(t, c.Close(context.Background()))
+	_, errs = c.MGet([]string{"d"})
+	assert.ErrorIs(t, errs[0], ErrClosed)
+}
+
+func TestCache_Txn(t *testing.T) {
+	c, err := New[string, int](40, WithShards(4), WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	item, err := c.PutItem("user", 1, 0)
+	require.NoError(t, err)
+	require.NoError(t, c.Put("index:old", 1, 0))
+
+	incr := func(item Item[int], err error) (int, error) {
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		return item.Value + 1, nil
+	}
+
+	results, err := c.Txn(
+		[]TxnCond[string]{
+			{Type: TxnVersion, Key: "user", Version: item.Version},
+			{Type: TxnAbsent, Key: "index:new"},
+		},
+		[]TxnOp[string, int]{
+			{Type: TxnPut, Key: "user", Value: 2},
+			{Type: TxnEvict, Key: "index:old"},
+			{Type: TxnPut, Key: "index:new", Value: 2},
+			{Type: TxnUpdate, Key: "index:new", Update: incr},
+			{Type: TxnEvict, Key: "missing"},
+		})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.Greater(t, results[0].Item.Version, item.Version)
+	assert.Equal(t, 1, results[1].Item.Value)
+	assert.Equal(t, 3, results[3].Item.Value)
+	assert.ErrorIs(t, results[4].Err, ErrNotFound)
+
+	value, _, err := c.Get("index:new")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+	_, _, err = c.Get("index:old")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Невыполненное условие отменяет транзакцию целиком
+	_, err = c.Txn(
+		[]TxnCond[string]{{Type: TxnVersion, Key: "user", Version: item.Version}},
+		[]TxnOp[string, int]{{Type: TxnPut, Key: "other", Value: 1}})
+	var txnErr *TxnError
+	require.ErrorAs(t, err, &txnErr)
+	assert.ErrorIs(t, err, ErrConditionFailed)
+	assert.Equal(t, 0, txnErr.Cond)
+	_, _, err = c.Get("other")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Ошибка операции отменяет и предыдущие операции
+	errStop := errors.New("stop")
+	_, err = c.Txn(nil, []TxnOp[string, int]{
+		{Type: TxnEvict, Key: "user"},
+		{Type: TxnUpdate, Key: "index:new", Update: func(Item[int], error) (int, error) { return 0, errStop }},
+	})
+	require.ErrorAs(t, err, &txnErr)
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, txnErr.Op)
+	value, _, err = c.Get("user")
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+}
+
+func TestCache_Prefix(t *testing.T) {
+	c, err := New[string, int](30, WithShards(3), WithPrefixIndex(), WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	for i, key := range []string{"user:1:profile", "user:1:settings", "user:12:profile", "user:2:profile", "order:1"} {
+		require.NoError(t, c.Put(key, i, 0))
+	}
+	require.NoError(t, c.Put("user:1:token", 9, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	keys, values, err := c.ScanPrefix("user:1:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1:profile", "user:1:settings"}, keys)
+	assert.Equal(t, []int{0, 1}, values)
+
+	// Истекшая запись удаляется, но не учитывается
+	n, err := c.EvictPrefix("user:1:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 3, c.Len())
+
+	keys, _, err = c.ScanPrefix("user:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:12:profile", "user:2:profile"}, keys)
+
+	// Вытесненные записи удаляются и из индекса
+	for i := 0; i < 50; i++ {
+		require.NoError(t, c.Put(fmt.Sprintf("tmp:%d", i), i, 0))
+	}
+	keys, _, err = c.ScanPrefix("")
+	require.NoError(t, err)
+	assert.Len(t, keys, c.Len())
+
+	require.NoError(t, c.EvictAll())
+	keys, _, err = c.ScanPrefix("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	// Без индекса поиск по префиксу недоступен
+	plain, err := New[string, int](10)
+	require.NoError(t, err)
+	defer plain.Close(context.Background())
+	_, _, err = plain.ScanPrefix("user:")
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestCache_EvictByTag(t *testing.T) {
+	c, err := New[string, int](30, WithShards(3), WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("product:1:page", 1, 0, Tags("product:1")))
+	require.NoError(t, c.Put("api:product:1", 2, 0, Tags("product:1", "api", "product:1")))
+	require.NoError(t, c.Put("search:shoes", 3, 0, Tags("product:1", "product:2")))
+	require.NoError(t, c.Put("product:2:page", 4, 0, Tags("product:2")))
+	require.NoError(t, c.Put("expired", 5, time.Millisecond, Tags("product:1")))
+
+	// Перезапись без тега убирает запись из индекса
+	require.NoError(t, c.Put("product:1:page", 1, 0))
+	time.Sleep(5 * time.Millisecond)
+
+	n, err := c.EvictByTag("product:1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, _, err = c.Get("product:1:page")
+	assert.NoError(t, err)
+	_, _, err = c.Get("search:shoes")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Удаленные записи пропадают и из индексов других тегов
+	n, err = c.EvictByTag("product:2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = c.EvictByTag("api")
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, c.Len())
+
+	for _, s := range c.shards {
+		assert.Empty(t, s.tags)
+	}
+
+	err = c.Put("a", 1, 0, Tags(""))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestCache_Scan(t *testing.T) {
+	c, err := New[string, int](20, WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Put(fmt.Sprintf("k%d", i), i, 0))
+	}
+
+	scanAll := func(order ScanOrder, limit int) (pages [][]string) {
+		var cursor Cursor[string]
+		for !cursor.Done() {
+			keys, _, next, err := c.Scan(cursor, limit, order)
+			require.NoError(t, err)
+			pages = append(pages, keys)
+			cursor = next
+		}
+		return pages
+	}
+
+	assert.Equal(t, [][]string{{"k0", "k1", "k2", "k3"}, {"k4", "k5", "k6", "k7"}, {"k8", "k9"}}, scanAll(ScanLRU, 4))
+	assert.Equal(t, [][]string{{"k9", "k8", "k7", "k6"}, {"k5", "k4", "k3", "k2"}, {"k1", "k0"}}, scanAll(ScanMRU, 4))
+
+	// Курсор остается действительным после удаления последней отданной записи
+	keys, values, cursor, err := c.Scan(Cursor[string]{}, 4, ScanLRU)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, values)
+	_, err = c.Evict(keys[3])
+	require.NoError(t, err)
+
+	// Запись, использованная во время обхода, переносится в конец порядка и не теряется
+	require.NoError(t, c.Put("k1", 11, 0))
+	require.NoError(t, c.Put("k5", 15, 0))
+
+	keys, _, cursor, err = c.Scan(cursor, 10, ScanLRU)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k4", "k6", "k7", "k8", "k9", "k1", "k5"}, keys)
+	assert.True(t, cursor.Done())
+
+	// Истекшие записи пропускаются
+	require.NoError(t, c.Put("tmp", 0, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	keys, _, _, err = c.Scan(Cursor[string]{}, 1, ScanMRU)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k5"}, keys)
+
+	_, _, _, err = c.Scan(Cursor[string]{}, 0, ScanLRU)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	_, _, _, err = c.Scan(Cursor[string]{Shard: 1}, 1, ScanLRU)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	// С несколькими шардами обход возвращает каждую запись ровно один раз
+	sharded, err := New[int, int](1000, WithShards(4))
+	require.NoError(t, err)
+	defer sharded.Close(context.Background())
+	for i := 0; i < 100; i++ {
+		require.NoError(t, sharded.Put(i, i, 0))
+	}
+
+	seen := make(map[int]int)
+	for cursor := (Cursor[int]{}); !cursor.Done(); {
+		keys, _, next, err := sharded.Scan(cursor, 7, ScanMRU)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(keys), 7)
+		for _, key := range keys {
+			seen[key]++
+		}
+		cursor = next
+	}
+	assert.Len(t, seen, 100)
+	for key, n := range seen {
+		assert.Equal(t, 1, n, key)
+	}
+}
+
+func TestCache_ExportImport(t *testing.T) {
+	c, err := New[string, int](10, WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.Put(fmt.Sprintf("k%d", i), i, time.Hour))
+	}
+	require.NoError(t, c.Put("session", 10, time.Minute, Sliding(), Tags("user:1")))
+	require.NoError(t, c.Put("stale", 11, time.Millisecond, StaleTTL(time.Hour)))
+	require.NoError(t, c.Put("dead", 12, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	var records []Record[string, int]
+	require.NoError(t, c.Export(func(rec Record[string, int]) error {
+		records = append(records, rec)
+		return nil
+	}))
+
+	// Истекшая запись без StaleTTL не сохраняется, устаревшая - сохраняется
+	byKey := make(map[string]Record[string, int])
+	for _, rec := range records {
+		byKey[rec.Key] = rec
+	}
+	assert.Len(t, records, 7)
+	assert.NotContains(t, byKey, "dead")
+	assert.True(t, byKey["session"].Sliding)
+	assert.Equal(t, time.Minute, byKey["session"].TTL)
+	assert.Equal(t, []string{"user:1"}, byKey["session"].Tags)
+
+	restored, err := New[string, int](10, WithJanitor(time.Hour, 10))
+	require.NoError(t, err)
+	defer restored.Close(context.Background())
+
+	for _, rec := range records {
+		require.NoError(t, restored.Import(rec))
+	}
+	assert.Equal(t, 7, restored.Len())
+
+	// Порядок использования восстанавливается
+	var order []string
+	require.NoError(t, restored.Export(func(rec Record[string, int]) error {
+		order = append(order, rec.Key)
+		return nil
+	}))
+	var expected []string
+	for _, rec := range records {
+		expected = append(expected, rec.Key)
+	}
+	assert.Equal(t, expected, order)
+
+	// Дата истечения, параметры и теги сохраняются
+	item, err := restored.GetItem("stale")
+	require.NoError(t, err)
+	assert.True(t, item.Stale)
+	_, expiresAt, err := restored.Get("k1")
+	require.NoError(t, err)
+	assert.Equal(t, byKey["k1"].ExpiresAt, expiresAt)
+
+	n, err := restored.EvictByTag("user:1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Запись, срок которой прошел, не восстанавливается
+	assert.ErrorIs(t, restored.Import(Record[string, int]{Key: "old", ExpiresAt: time.Now().Add(-time.Second)}), ErrExpired)
+	assert.ErrorIs(t, restored.Import(Record[string, int]{Key: "bad", TTL: -1}), ErrInvalidTTL)
+}
+
+func TestCache_Observer(t *testing.T) {
+	var mutations []Mutation[string, int]
+	c, err := New[string, int](2, WithJanitor(time.Hour, 10), WithObserver(func(m Mutation[string, int]) {
+		mutations = append(mutations, m)
+	}))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Put("a", 1, time.Hour, Tags("t")))
+	require.NoError(t, c.Put("b", 2, 0))
+	require.NoError(t, c.Put("c", 3, 0)) // вытесняет a, о вытеснении не сообщается
+	_, err = c.Evict("b")
+	require.NoError(t, err)
+	_, err = c.Evict("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, c.EvictAll())
+
+	require.Len(t, mutations, 5)
+	assert.Equal(t, MutationPut, mutations[0].Kind)
+	assert.Equal(t, "a", mutations[0].Record.Key)
+	assert.Equal(t, 1, mutations[0].Record.Value)
+	assert.Equal(t, time.Hour, mutations[0].Record.TTL)
+	assert.False(t, mutations[0].Record.ExpiresAt.IsZero())
+	assert.Equal(t, []string{"t"}, mutations[0].Record.Tags)
+	assert.Equal(t, "c", mutations[2].Record.Key)
+	assert.Equal(t, Mutation[string, int]{Kind: MutationEvict, Record: Record[string, int]{Key: "b"}}, mutations[3])
+	assert.Equal(t, MutationClear, mutations[4].Kind)
+
+	// Наблюдатель с другими типами ключа и значения отклоняется
+	_, err = New[string, int](2, WithObserver(func(Mutation[int, int]) {}))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
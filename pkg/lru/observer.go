@@ -0,0 +1,19 @@
+package lru
+
+// MutationKind задает вид изменения кэша, о котором сообщается наблюдателю (см. WithObserver)
+type MutationKind int
+
+const (
+	MutationPut   MutationKind = iota // Запись сохранена, Record содержит ее новое состояние
+	MutationEvict                     // Запись удалена по запросу, в Record заполнен только Key
+	MutationClear                     // Кэш очищен EvictAll, Record не заполнен
+)
+
+// Mutation описывает изменение кэша. Сообщается обо всех записях значений (Put, MPut, Update, Txn,
+// GetOrLoad и фоновые обновления, Import) и обо всех удалениях по запросу (Evict, MEvict, EvictPrefix,
+// EvictByTag, Txn, EvictAll). Вытеснение при переполнении, удаление истекших записей и продление
+// скользящего TTL при чтении не сообщаются: их можно воспроизвести по самим изменениям.
+type Mutation[K comparable, V any] struct {
+	Kind   MutationKind
+	Record Record[K, V]
+}
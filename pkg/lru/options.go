@@ -0,0 +1,182 @@
+package lru
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultJanitorInterval = 100 * time.Millisecond // Период фоновой очистки по умолчанию
+	defaultJanitorBudget   = 1000                   // Лимит удалений за проход фоновой очистки по умолчанию
+)
+
+// Option задает параметр кэша при создании через New.
+//
+// Option не параметризован типами ключа и значения, чтобы их не приходилось указывать явно
+// при каждом вызове. Опции, зависящие от типов (например, WithHasher), проверяются в New.
+type Option func(*settings)
+
+// settings содержит параметры кэша, собранные из опций
+type settings struct {
+	defaultTTL      time.Duration
+	shards          int
+	janitorInterval time.Duration
+	janitorBudget   int
+	hasher          interface{} // func(K) uint64
+	maxCost         int64
+	cost            interface{} // func(K, V) int64
+	policy          Policy
+	policyFactory   interface{} // PolicyFactory[K, V]
+	admission       bool
+	prefixIndex     bool
+	promote         bool
+	loaderTimeout   time.Duration
+	observer        interface{} // func(Mutation[K, V])
+}
+
+// defaultSettings возвращает параметры кэша по умолчанию
+func defaultSettings() settings {
+	return settings{
+		shards:          1,
+		janitorInterval: defaultJanitorInterval,
+		janitorBudget:   defaultJanitorBudget,
+		promote:         true,
+	}
+}
+
+// validate проверяет параметры кэша размера size
+func (s *settings) validate(size int) error {
+	switch {
+	case size <= 0:
+		return fmt.Errorf("%w: size must be > 0", ErrInvalidOption)
+	case s.defaultTTL < 0:
+		return fmt.Errorf("%w: default ttl can not be negative", ErrInvalidOption)
+	case s.shards <= 0:
+		return fmt.Errorf("%w: shards must be > 0", ErrInvalidOption)
+	case s.shards > size:
+		return fmt.Errorf("%w: shards can not exceed size", ErrInvalidOption)
+	case s.janitorInterval <= 0:
+		return fmt.Errorf("%w: janitor interval must be > 0", ErrInvalidOption)
+	case s.janitorBudget <= 0:
+		return fmt.Errorf("%w: janitor budget must be > 0", ErrInvalidOption)
+	case s.maxCost < 0:
+		return fmt.Errorf("%w: max cost can not be negative", ErrInvalidOption)
+	case s.maxCost > 0 && s.maxCost < int64(s.shards):
+		return fmt.Errorf("%w: max cost can not be less than shards count", ErrInvalidOption)
+	case s.loaderTimeout < 0:
+		return fmt.Errorf("%w: loader timeout can not be negative", ErrInvalidOption)
+	}
+	return nil
+}
+
+// WithDefaultTTL задает TTL, используемый при записи с нулевым TTL.
+// По умолчанию TTL не задан, и такие записи не истекают.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(s *settings) {
+		s.defaultTTL = ttl
+	}
+}
+
+// WithShards задает количество шардов. Ключи распределяются по хэшу между независимыми
+// шардами со своими блокировками, размер кэша делится между шардами поровну.
+func WithShards(shards int) Option {
+	return func(s *settings) {
+		s.shards = shards
+	}
+}
+
+// WithJanitor задает период фоновой очистки expired записей и максимальное
+// количество записей, удаляемых из одного шарда за проход.
+func WithJanitor(interval time.Duration, budget int) Option {
+	return func(s *settings) {
+		s.janitorInterval = interval
+		s.janitorBudget = budget
+	}
+}
+
+// WithHasher задает хэш-функцию ключей для распределения по шардам.
+// По умолчанию используется hash/maphash.
+func WithHasher[K comparable](hasher func(K) uint64) Option {
+	return func(s *settings) {
+		s.hasher = hasher
+	}
+}
+
+// WithMaxCost ограничивает суммарную стоимость записей в кэше (например, в байтах).
+// При превышении лимита записи вытесняются из конца списка, пока суммарная стоимость не уложится
+// в лимит. Ограничение работает вместе с ограничением по количеству записей, лимит делится между
+// шардами поровну. Нулевое значение (по умолчанию) снимает ограничение.
+func WithMaxCost(maxCost int64) Option {
+	return func(s *settings) {
+		s.maxCost = maxCost
+	}
+}
+
+// WithCost задает функцию оценки стоимости записи. По умолчанию стоимость равна длине ключа
+// плюс размер значения, закодированного в JSON.
+func WithCost[K comparable, V any](cost func(K, V) int64) Option {
+	return func(s *settings) {
+		s.cost = cost
+	}
+}
+
+// WithPolicy задает встроенную политику вытеснения. По умолчанию используется PolicyLRU.
+func WithPolicy(policy Policy) Option {
+	return func(s *settings) {
+		s.policy = policy
+	}
+}
+
+// WithEvictionPolicy задает собственную политику вытеснения. Фабрика вызывается для каждого шарда.
+func WithEvictionPolicy[K comparable, V any](factory PolicyFactory[K, V]) Option {
+	return func(s *settings) {
+		s.policyFactory = factory
+	}
+}
+
+// WithAdmission включает фильтр допуска W-TinyLFU. Когда шард переполнен, новая запись
+// допускается, только если по оценке к ней обращаются чаще, чем к записи, которую пришлось бы
+// вытеснить. Это защищает популярные записи от вытеснения однократными ключами и сканированиями.
+// Количество допущенных и отклоненных записей доступно в Stats.
+func WithAdmission() Option {
+	return func(s *settings) {
+		s.admission = true
+	}
+}
+
+// WithPrefixIndex включает упорядоченный индекс ключей (radix tree) для ScanPrefix и EvictPrefix,
+// поддерживаемый вместе с мапой записей. Поиск по префиксу затрагивает только подходящие записи,
+// а не весь кэш, ценой дополнительной памяти и работы при записи и удалении.
+// Индекс доступен только для кэша со строковыми ключами.
+func WithPrefixIndex() Option {
+	return func(s *settings) {
+		s.prefixIndex = true
+	}
+}
+
+// WithPromotion задает, переносит ли чтение запись в начало списка использования.
+// По умолчанию перенос включен, и вытесняются давно не читавшиеся записи (LRU).
+// Если перенос выключен, записи вытесняются в порядке добавления или перезаписи (FIFO).
+func WithPromotion(promote bool) Option {
+	return func(s *settings) {
+		s.promote = promote
+	}
+}
+
+// WithLoaderTimeout ограничивает время загрузки одного ключа в GetOrLoad: контекст загрузчика
+// отменяется по истечении timeout. Нулевое значение (по умолчанию) снимает ограничение.
+func WithLoaderTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.loaderTimeout = timeout
+	}
+}
+
+// WithObserver задает функцию, которой сообщается о каждом изменении кэша (см. Mutation), например,
+// для записи журнала изменений. Функция вызывается под блокировкой шарда измененной записи, поэтому
+// порядок сообщений об изменениях одного ключа совпадает с порядком самих изменений; функция должна
+// выполняться быстро и не обращаться к кэшу.
+func WithObserver[K comparable, V any](observer func(m Mutation[K, V])) Option {
+	return func(s *settings) {
+		s.observer = observer
+	}
+}
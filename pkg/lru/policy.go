@@ -0,0 +1,91 @@
+package lru
+
+import (
+	"fmt"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// Policy задает название встроенной политики вытеснения
+type Policy string
+
+const (
+	PolicyLRU Policy = "lru" // Вытеснение давно не использовавшихся записей (по умолчанию)
+	PolicyLFU Policy = "lfu" // Вытеснение редко используемых записей
+	PolicyARC Policy = "arc" // Adaptive Replacement Cache, баланс между давностью и частотой обращений
+)
+
+// EvictionPolicy определяет, какую запись вытеснить при переполнении шарда.
+//
+// Экземпляр политики создается на каждый шард, все методы вызываются под блокировкой шарда.
+// Список order, передаваемый при создании политики, содержит записи шарда в порядке
+// использования (в начале - новейшие) и поддерживается самим кэшем.
+type EvictionPolicy[K comparable, V any] interface {
+	// OnInsert вызывается после добавления новой записи
+	OnInsert(e *list.Entry[K, V])
+	// OnAccess вызывается после успешного чтения или перезаписи записи
+	OnAccess(e *list.Entry[K, V])
+	// OnRemove вызывается после удаления записи по любой причине (вытеснение, истечение, ручное удаление)
+	OnRemove(e *list.Entry[K, V])
+	// Victim возвращает запись, которую следует вытеснить, не считая exclude, или nil, если вытеснять нечего
+	Victim(exclude *list.Entry[K, V]) *list.Entry[K, V]
+	// Reset сбрасывает состояние политики при очистке шарда
+	Reset()
+}
+
+// EvictionObserver может дополнительно реализовать политика, которой нужно отличать вытеснение
+// записи от других причин удаления. Victim лишь предлагает кандидата (например, фильтру допуска),
+// а OnEvict вызывается непосредственно перед OnRemove, только если запись действительно вытесняется.
+type EvictionObserver[K comparable, V any] interface {
+	// OnEvict вызывается перед OnRemove для записи, вытесненной по выбору политики
+	OnEvict(e *list.Entry[K, V])
+}
+
+// PolicyFactory создает экземпляр политики вытеснения для шарда с порядком использования order
+// и вместимостью capacity записей
+type PolicyFactory[K comparable, V any] func(order *list.LruList[K, V], capacity int) EvictionPolicy[K, V]
+
+// newPolicyFactory возвращает фабрику встроенной политики вытеснения по ее названию
+func newPolicyFactory[K comparable, V any](policy Policy) (PolicyFactory[K, V], error) {
+	switch policy {
+	case PolicyLRU, "":
+		return NewLRUPolicy[K, V], nil
+	case PolicyLFU:
+		return NewLFUPolicy[K, V], nil
+	case PolicyARC:
+		return NewARCPolicy[K, V], nil
+	default:
+		return nil, fmt.Errorf("%w: unknown eviction policy %q", ErrInvalidOption, policy)
+	}
+}
+
+// lruPolicy вытесняет запись, которая дольше всех не использовалась, то есть последнюю в списке order
+type lruPolicy[K comparable, V any] struct {
+	order *list.LruList[K, V]
+}
+
+// NewLRUPolicy создает политику вытеснения LRU
+func NewLRUPolicy[K comparable, V any](order *list.LruList[K, V], _ int) EvictionPolicy[K, V] {
+	return &lruPolicy[K, V]{order: order}
+}
+
+// OnInsert ничего не делает: порядок использования поддерживается кэшем
+func (p *lruPolicy[K, V]) OnInsert(*list.Entry[K, V]) {}
+
+// OnAccess ничего не делает: порядок использования поддерживается кэшем
+func (p *lruPolicy[K, V]) OnAccess(*list.Entry[K, V]) {}
+
+// OnRemove ничего не делает: порядок использования поддерживается кэшем
+func (p *lruPolicy[K, V]) OnRemove(*list.Entry[K, V]) {}
+
+// Victim возвращает последнюю запись списка order
+func (p *lruPolicy[K, V]) Victim(exclude *list.Entry[K, V]) *list.Entry[K, V] {
+	victim := p.order.Back()
+	if victim != nil && victim == exclude {
+		victim = victim.PrevEntry()
+	}
+	return victim
+}
+
+// Reset ничего не делает: порядок использования поддерживается кэшем
+func (p *lruPolicy[K, V]) Reset() {}
@@ -0,0 +1,95 @@
+package lru
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// ScanPrefix возвращает не истекшие записи, ключи которых начинаются с prefix, в виде двух слайсов,
+// как GetAll. Ключи упорядочены лексикографически. Требует индекса ключей (см. WithPrefixIndex),
+// без него возвращает ErrInvalidOption.
+//
+// Шарды блокируются на чтение по очереди, поэтому, в отличие от GetAll, результат не обязательно
+// соответствует одному моменту времени. Чтение не влияет на порядок вытеснения.
+func (c *Cache[K, V]) ScanPrefix(prefix string) (keys []K, values []V, err error) {
+	if err := c.checkPrefixIndex(); err != nil {
+		return nil, nil, err
+	}
+
+	type pair struct {
+		key   string
+		ent   *list.Entry[K, V]
+		value V
+	}
+	var pairs []pair
+
+	for _, s := range c.shards {
+		s.mu.RLock()
+		now := time.Now()
+		s.index.WalkPrefix(prefix, func(key string, ent *list.Entry[K, V]) bool {
+			if !ent.Expired(now) {
+				pairs = append(pairs, pair{key: key, ent: ent, value: ent.Value})
+			}
+			return true
+		})
+		s.mu.RUnlock()
+	}
+
+	// Внутри шарда ключи уже упорядочены, остается объединить шарды
+	if len(c.shards) > 1 {
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+	}
+
+	keys, values = make([]K, len(pairs)), make([]V, len(pairs))
+	for i, p := range pairs {
+		keys[i], values[i] = p.ent.Key, p.value
+	}
+	return keys, values, nil
+}
+
+// EvictPrefix удаляет все записи, ключи которых начинаются с prefix, и возвращает количество
+// удаленных не истекших записей. Требует индекса ключей (см. WithPrefixIndex), без него
+// возвращает ErrInvalidOption.
+//
+// Шарды блокируются по очереди, каждый один раз; работа пропорциональна количеству удаляемых записей.
+func (c *Cache[K, V]) EvictPrefix(prefix string) (n int, err error) {
+	if err := c.checkPrefixIndex(); err != nil {
+		return 0, err
+	}
+
+	var matched []*list.Entry[K, V]
+	for _, s := range c.shards {
+		s.mu.Lock()
+		now := time.Now()
+
+		// Дерево нельзя менять во время обхода, поэтому записи сначала собираются
+		matched = matched[:0]
+		s.index.WalkPrefix(prefix, func(_ string, ent *list.Entry[K, V]) bool {
+			matched = append(matched, ent)
+			return true
+		})
+		for _, ent := range matched {
+			if !ent.Dead(now) {
+				n++
+			}
+			s.evict(ent)
+		}
+		s.mu.Unlock()
+	}
+
+	return n, nil
+}
+
+// checkPrefixIndex проверяет, что кэш открыт и ведет индекс ключей
+func (c *Cache[K, V]) checkPrefixIndex() error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	if c.shards[0].index == nil {
+		return fmt.Errorf("%w: prefix index is not enabled", ErrInvalidOption)
+	}
+	return nil
+}
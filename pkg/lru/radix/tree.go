@@ -0,0 +1,203 @@
+// Package radix содержит сжатое префиксное дерево (radix tree) со строковыми ключами,
+// используемое кэшем для поиска записей по префиксу ключа.
+package radix
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tree реализует сжатое префиксное дерево. Каждое ребро хранит подстроку ключа, а узлы
+// с единственным потомком и без значения сливаются с потомком, поэтому поиск по префиксу
+// затрагивает только узлы на пути к префиксу и узлы подходящих ключей.
+//
+// Tree не потокобезопасно, синхронизация выполняется вызывающим (в кэше - блокировкой шарда).
+type Tree[T any] struct {
+	root node[T]
+	size int
+}
+
+// node описывает узел дерева
+type node[T any] struct {
+	label    string     // подстрока ключа на ребре от родителя
+	children []*node[T] // потомки, упорядоченные по первому байту label
+	value    T
+	leaf     bool // в узле заканчивается ключ
+}
+
+// New создает пустое дерево
+func New[T any]() *Tree[T] {
+	return &Tree[T]{}
+}
+
+// Len возвращает количество ключей в дереве
+func (t *Tree[T]) Len() int {
+	return t.size
+}
+
+// Insert добавляет ключ со значением или заменяет значение существующего ключа
+func (t *Tree[T]) Insert(key string, value T) {
+	n, search := &t.root, key
+	for {
+		if len(search) == 0 {
+			if !n.leaf {
+				t.size++
+			}
+			n.value, n.leaf = value, true
+			return
+		}
+
+		idx, child := n.child(search[0])
+		if child == nil {
+			n.addChild(&node[T]{label: search, value: value, leaf: true})
+			t.size++
+			return
+		}
+
+		common := commonPrefix(search, child.label)
+		if common == len(child.label) {
+			n, search = child, search[common:]
+			continue
+		}
+
+		// Ключ расходится с ребром посередине: ребро делится общим узлом
+		split := &node[T]{label: search[:common], children: []*node[T]{child}}
+		child.label = child.label[common:]
+		n.children[idx] = split
+
+		if search = search[common:]; len(search) == 0 {
+			split.value, split.leaf = value, true
+		} else {
+			split.addChild(&node[T]{label: search, value: value, leaf: true})
+		}
+		t.size++
+		return
+	}
+}
+
+// Get возвращает значение по ключу
+func (t *Tree[T]) Get(key string) (value T, ok bool) {
+	n, search := &t.root, key
+	for len(search) > 0 {
+		_, child := n.child(search[0])
+		if child == nil || !strings.HasPrefix(search, child.label) {
+			return value, false
+		}
+		n, search = child, search[len(child.label):]
+	}
+	return n.value, n.leaf
+}
+
+// Delete удаляет ключ и сообщает, был ли он в дереве
+func (t *Tree[T]) Delete(key string) bool {
+	var (
+		parent *node[T]
+		n      = &t.root
+		search = key
+	)
+	for len(search) > 0 {
+		_, child := n.child(search[0])
+		if child == nil || !strings.HasPrefix(search, child.label) {
+			return false
+		}
+		parent, n, search = n, child, search[len(child.label):]
+	}
+	if !n.leaf {
+		return false
+	}
+
+	var zero T
+	n.value, n.leaf = zero, false
+	t.size--
+
+	// Узел без значения и потомков удаляется, после чего его родитель может слиться
+	// с единственным оставшимся потомком
+	if parent != nil && len(n.children) == 0 {
+		parent.removeChild(n.label[0])
+		n = parent
+	}
+	if n != &t.root && !n.leaf && len(n.children) == 1 {
+		n.mergeChild()
+	}
+	return true
+}
+
+// WalkPrefix вызывает fn для всех ключей, начинающихся с prefix, в лексикографическом порядке.
+// Обход останавливается, если fn возвращает false.
+func (t *Tree[T]) WalkPrefix(prefix string, fn func(key string, value T) bool) {
+	n, search, path := &t.root, prefix, ""
+	for len(search) > 0 {
+		_, child := n.child(search[0])
+		switch {
+		case child == nil:
+			return
+		case strings.HasPrefix(search, child.label):
+			search = search[len(child.label):]
+		case strings.HasPrefix(child.label, search):
+			// Префикс заканчивается посередине ребра: подходят все ключи поддерева
+			search = ""
+		default:
+			return
+		}
+		n, path = child, path+child.label
+	}
+	n.walk(path, fn)
+}
+
+// walk обходит поддерево узла с ключом path, возвращает false, если обход остановлен
+func (n *node[T]) walk(path string, fn func(key string, value T) bool) bool {
+	if n.leaf && !fn(path, n.value) {
+		return false
+	}
+	for _, child := range n.children {
+		if !child.walk(path+child.label, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// child возвращает позицию и потомка, ребро к которому начинается с байта b
+func (n *node[T]) child(b byte) (int, *node[T]) {
+	idx := sort.Search(len(n.children), func(i int) bool { return n.children[i].label[0] >= b })
+	if idx < len(n.children) && n.children[idx].label[0] == b {
+		return idx, n.children[idx]
+	}
+	return idx, nil
+}
+
+// addChild добавляет потомка, сохраняя порядок потомков
+func (n *node[T]) addChild(child *node[T]) {
+	idx, _ := n.child(child.label[0])
+	n.children = append(n.children, nil)
+	copy(n.children[idx+1:], n.children[idx:])
+	n.children[idx] = child
+}
+
+// removeChild удаляет потомка, ребро к которому начинается с байта b
+func (n *node[T]) removeChild(b byte) {
+	idx, child := n.child(b)
+	if child == nil {
+		return
+	}
+	copy(n.children[idx:], n.children[idx+1:])
+	n.children[len(n.children)-1] = nil
+	n.children = n.children[:len(n.children)-1]
+}
+
+// mergeChild сливает узел без значения с его единственным потомком
+func (n *node[T]) mergeChild() {
+	child := n.children[0]
+	n.label += child.label
+	n.children = child.children
+	n.value, n.leaf = child.value, child.leaf
+}
+
+// commonPrefix возвращает длину общего префикса строк
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
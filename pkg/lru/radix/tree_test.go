@@ -0,0 +1,87 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collect возвращает ключи дерева с префиксом prefix
+func collect(t *Tree[int], prefix string) []string {
+	keys := []string{}
+	t.WalkPrefix(prefix, func(key string, _ int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func TestTree(t *testing.T) {
+	tree := New[int]()
+	for i, key := range []string{"user:1:profile", "user:1:settings", "user:12:profile", "user:2", "user", "order:1"} {
+		tree.Insert(key, i)
+	}
+	tree.Insert("user:2", 10)
+	assert.Equal(t, 6, tree.Len())
+
+	value, ok := tree.Get("user:2")
+	assert.True(t, ok)
+	assert.Equal(t, 10, value)
+	_, ok = tree.Get("user:")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"user:1:profile", "user:1:settings"}, collect(tree, "user:1:"))
+	assert.Equal(t, []string{"user:12:profile", "user:1:profile", "user:1:settings"}, collect(tree, "user:1"))
+	assert.Equal(t, []string{"user", "user:12:profile", "user:1:profile", "user:1:settings", "user:2"}, collect(tree, "us"))
+	assert.Equal(t, []string{}, collect(tree, "user:3"))
+	assert.Len(t, collect(tree, ""), 6)
+
+	// Остановка обхода
+	count := 0
+	tree.WalkPrefix("user", func(string, int) bool {
+		count++
+		return count < 2
+	})
+	assert.Equal(t, 2, count)
+
+	assert.True(t, tree.Delete("user"))
+	assert.False(t, tree.Delete("user"))
+	assert.False(t, tree.Delete("user:1"))
+	assert.True(t, tree.Delete("user:1:profile"))
+	assert.Equal(t, []string{"user:12:profile", "user:1:settings", "user:2"}, collect(tree, "user"))
+	assert.Equal(t, 4, tree.Len())
+}
+
+func TestTree_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	tree := New[int]()
+	expected := make(map[string]int)
+
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("%d:%d", rnd.Intn(20), rnd.Intn(50))
+		if rnd.Intn(3) == 0 {
+			_, ok := expected[key]
+			assert.Equal(t, ok, tree.Delete(key))
+			delete(expected, key)
+			continue
+		}
+		tree.Insert(key, i)
+		expected[key] = i
+	}
+	assert.Equal(t, len(expected), tree.Len())
+
+	for _, prefix := range []string{"", "1", "1:", "13:4", "7:7", "x"} {
+		keys := []string{}
+		for key := range expected {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		assert.Equal(t, keys, collect(tree, prefix), prefix)
+	}
+}
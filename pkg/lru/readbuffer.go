@@ -0,0 +1,52 @@
+package lru
+
+import (
+	"sync/atomic"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// readBufferSize задает количество обращений, накапливаемых шардом перед применением
+const readBufferSize = 64
+
+// readBuffer накапливает обращения на чтение, чтобы применять их к порядку использования
+// и политике вытеснения пачками под одной блокировкой на запись.
+//
+// Запись в буфер выполняется без блокировок конкурентно несколькими читателями (под lock
+// на чтение шарда), а применение - под lock на запись, поэтому запись и применение не пересекаются.
+// Обращения, пришедшие в заполненный буфер, отбрасываются: порядок использования становится
+// приблизительным, но чтение никогда не ждет блокировку на запись из-за буфера.
+type readBuffer[K comparable, V any] struct {
+	head  atomic.Uint64
+	slots [readBufferSize]atomic.Pointer[list.Entry[K, V]]
+}
+
+// add записывает обращение к элементу и сообщает, заполнил ли он буфер
+func (b *readBuffer[K, V]) add(e *list.Entry[K, V]) bool {
+	n := b.head.Add(1) - 1
+	if n >= readBufferSize {
+		return false
+	}
+
+	b.slots[n].Store(e)
+	return n == readBufferSize-1
+}
+
+// drain вызывает apply для каждого накопленного обращения в порядке поступления и очищает буфер
+func (b *readBuffer[K, V]) drain(apply func(e *list.Entry[K, V])) {
+	n := min(b.head.Load(), readBufferSize)
+	for i := uint64(0); i < n; i++ {
+		if e := b.slots[i].Swap(nil); e != nil {
+			apply(e)
+		}
+	}
+	b.head.Store(0)
+}
+
+// reset отбрасывает накопленные обращения
+func (b *readBuffer[K, V]) reset() {
+	for i := range b.slots {
+		b.slots[i].Store(nil)
+	}
+	b.head.Store(0)
+}
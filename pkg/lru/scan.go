@@ -0,0 +1,115 @@
+package lru
+
+import (
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// ScanOrder задает порядок обхода записей шарда в Scan
+type ScanOrder int
+
+const (
+	ScanLRU ScanOrder = iota // От давно не использовавшихся записей к недавно использованным
+	ScanMRU                  // От недавно использованных записей к давно не использовавшимся
+)
+
+// Cursor задает позицию постраничного обхода кэша в Scan. Нулевое значение - начало обхода.
+//
+// Позиция внутри шарда задается отметкой порядка последней отданной записи (см. list.Entry.Stamp),
+// а не самой записью, поэтому курсор остается действительным, даже если запись уже вытеснена или удалена.
+type Cursor[K comparable] struct {
+	Shard int    // Номер обходимого шарда, -1 - обход завершен
+	Stamp uint64 // Отметка порядка последней отданной записи шарда, 0 - шард еще не обходился
+	Key   K      // Ключ последней отданной записи, позволяет продолжить обход без поиска по отметке
+}
+
+// Done сообщает, завершен ли обход
+func (c Cursor[K]) Done() bool {
+	return c.Shard < 0
+}
+
+// Scan возвращает страницу из не более чем limit не истекших записей, следующих за позицией cursor,
+// и курсор для получения следующей страницы (Done, если записей больше нет).
+//
+// Шарды обходятся по очереди, внутри шарда записи следуют в порядке order; общего порядка
+// использования для всех шардов кэш не ведет. Блокировка на чтение берется на один шард на время
+// сбора его части страницы, поэтому запись в кэш не блокируется на время всего обхода.
+//
+// При обходе в порядке ScanLRU каждая запись, находившаяся в кэше весь обход, будет возвращена хотя бы
+// один раз: запись, использованная во время обхода, переносится в конец порядка и может быть возвращена
+// повторно. При обходе в порядке ScanMRU такая запись может быть пропущена. Курсор нужно передавать
+// с тем же порядком, с которым он получен.
+func (c *Cache[K, V]) Scan(cursor Cursor[K], limit int, order ScanOrder) (keys []K, values []V, next Cursor[K], err error) {
+	if c.closed.Load() {
+		return nil, nil, next, ErrClosed
+	}
+	if limit <= 0 || (order != ScanLRU && order != ScanMRU) || cursor.Shard >= len(c.shards) {
+		return nil, nil, next, ErrInvalidOption
+	}
+	if cursor.Done() {
+		return nil, nil, cursor, nil
+	}
+
+	keys = make([]K, 0, limit)
+	values = make([]V, 0, limit)
+
+	now := time.Now()
+	next = cursor
+	for next.Shard < len(c.shards) {
+		s := c.shards[next.Shard]
+
+		s.mu.RLock()
+		var last *list.Entry[K, V]
+		keys, values, last = s.scan(next, limit-len(keys), order, keys, values, now)
+		if last != nil {
+			next.Stamp, next.Key = last.Stamp(), last.Key
+		}
+		s.mu.RUnlock()
+
+		if len(keys) == limit {
+			return keys, values, next, nil
+		}
+		next = Cursor[K]{Shard: next.Shard + 1}
+	}
+
+	return keys, values, Cursor[K]{Shard: -1}, nil
+}
+
+// scan дописывает в слайсы не более limit не истекших на момент now записей шарда, следующих
+// в порядке order за позицией cursor. Возвращает последнюю просмотренную запись
+// (nil, если ни одной записи не просмотрено).
+func (s *shard[K, V]) scan(cursor Cursor[K], limit int, order ScanOrder, keys []K, values []V, now time.Time) ([]K, []V, *list.Entry[K, V]) {
+	// Отметки растут от конца списка к началу: обход ScanLRU идет к началу списка, ScanMRU - к концу
+	step, after := (*list.Entry[K, V]).PrevEntry, func(ent *list.Entry[K, V]) bool { return ent.Stamp() > cursor.Stamp }
+	ent := s.evictList.Back()
+	if order == ScanMRU {
+		step, after = (*list.Entry[K, V]).NextEntry, func(ent *list.Entry[K, V]) bool { return ent.Stamp() < cursor.Stamp }
+		ent = s.evictList.Front()
+	}
+
+	if cursor.Stamp > 0 {
+		if last, ok := s.items[cursor.Key]; ok && last.Stamp() == cursor.Stamp {
+			// Последняя отданная запись не перемещалась: обход продолжается сразу за ней
+			ent = step(last)
+		} else {
+			// Иначе пропускаются записи до отметки курсора
+			for ent != nil && !after(ent) {
+				ent = step(ent)
+			}
+		}
+	}
+
+	var last *list.Entry[K, V]
+	for ; ent != nil && limit > 0; ent = step(ent) {
+		last = ent
+		if ent.Expired(now) {
+			continue
+		}
+
+		keys = append(keys, ent.Key)
+		values = append(values, ent.Value)
+		limit--
+	}
+	return keys, values, last
+}
@@ -0,0 +1,297 @@
+package lru
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/radix"
+)
+
+// shard задает независимую часть кэша со своей блокировкой.
+// Все методы shard, кроме deleteExpired, подразумевают, что lock на запись уже вызван.
+//
+// Чтение выполняется под lock на чтение: обращения не меняют порядок использования сразу,
+// а накапливаются в буфере reads и применяются пачкой (см. applyReads).
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+
+	size      int
+	maxCost   int64                   // лимит суммарной стоимости записей, 0 - без ограничения
+	cost      int64                   // текущая суммарная стоимость записей
+	evictList *list.LruList[K, V]     // записи в порядке использования
+	expiry    *list.ExpiryQueue[K, V] // очередь элементов по дате истечения
+	items     map[K]*list.Entry[K, V]
+	policy    EvictionPolicy[K, V] // политика выбора вытесняемых записей
+	admission *tinyLFU[K]          // фильтр допуска новых записей, nil - допускаются все
+
+	index    *radix.Tree[*list.Entry[K, V]] // упорядоченный индекс ключей для поиска по префиксу, nil - не ведется
+	indexKey func(K) string                 // строковое представление ключа для индекса
+
+	tags map[string]map[*list.Entry[K, V]]struct{} // обратный индекс тег - записи, nil - тегов еще не было
+
+	version uint64 // последняя выданная версия записи, растет монотонно в пределах шарда
+
+	observer func(Mutation[K, V]) // получатель сообщений об изменениях, nil - не задан
+
+	promote bool             // переносить ли прочитанные записи в начало списка
+	reads   readBuffer[K, V] // обращения на чтение, еще не примененные к порядку использования
+}
+
+// newShard создает новый шард размера size с лимитом стоимости maxCost и политикой вытеснения,
+// создаваемой newPolicy. Если promote установлен, чтение переносит запись в начало списка.
+func newShard[K comparable, V any](size int, maxCost int64, newPolicy PolicyFactory[K, V], promote bool) *shard[K, V] {
+	s := &shard[K, V]{
+		promote:   promote,
+		size:      size,
+		maxCost:   maxCost,
+		evictList: list.NewList[K, V](),
+		expiry:    list.NewExpiryQueue[K, V](),
+		items:     make(map[K]*list.Entry[K, V]),
+	}
+	s.policy = newPolicy(s.evictList, size)
+
+	return s
+}
+
+// put записывает значение стоимостью cost с TTL ttl и параметрами cfg, при необходимости
+// вытесняя элементы, выбранные политикой вытеснения, и возвращает записанный элемент.
+// Возвращает ErrTooLarge, если стоимость записи превышает лимит шарда.
+//
+// Если задан фильтр допуска, новая запись в переполненный шард может быть отклонена;
+// в этом случае put ничего не меняет и возвращает ErrRejected (решение также отражается в статистике).
+func (s *shard[K, V]) put(key K, value V, cost int64, expiresAt time.Time, ttl time.Duration, cfg entrySettings) (*list.Entry[K, V], error) {
+	return s.store(key, value, cost, expiresAt, ttl, cfg, true)
+}
+
+// store записывает значение так же, как put. Если admit не установлен, фильтр допуска не применяется,
+// и новая запись всегда попадает в шард (например, в транзакции, которая не может быть частично отклонена).
+func (s *shard[K, V]) store(key K, value V, cost int64, expiresAt time.Time, ttl time.Duration, cfg entrySettings, admit bool) (*list.Entry[K, V], error) {
+	if s.maxCost > 0 && cost > s.maxCost {
+		return nil, ErrTooLarge
+	}
+
+	// Накопленные чтения применяются до выбора вытесняемых записей
+	s.applyReads()
+	s.recordAccess(key)
+
+	// Перезапись существующего элемента
+	if ent, ok := s.items[key]; ok {
+		s.evictList.MoveToFront(ent)
+		ent.Value = value
+		s.cost += cost - ent.Cost
+		ent.Cost = cost
+		ent.Version = s.nextVersion()
+		s.untag(ent)
+		applyEntrySettings(ent, ttl, cfg)
+		s.tag(ent)
+		s.setExpiresAt(ent, expiresAt)
+		s.policy.OnAccess(ent)
+		s.evictOverflow(ent)
+		s.notifyPut(ent)
+		return ent, nil
+	}
+
+	// Фильтр допуска сравнивает популярность новой записи и записи, которую придется вытеснить
+	if admit && s.admission != nil && s.full(cost) {
+		if victim := s.policy.Victim(nil); victim != nil && !s.admission.admit(key, victim.Key) {
+			return nil, ErrRejected
+		}
+	}
+
+	// Добавление в список
+	ent := s.evictList.PushFront(key, value, time.Time{}) // может "переполнить" список
+	ent.Cost = cost
+	s.cost += cost
+
+	// Добавление в мапу, индекс и очередь истечения
+	s.items[key] = ent
+	if s.index != nil {
+		s.index.Insert(s.indexKey(key), ent)
+	}
+	ent.Version = s.nextVersion()
+	applyEntrySettings(ent, ttl, cfg)
+	s.tag(ent)
+	s.setExpiresAt(ent, expiresAt)
+	s.policy.OnInsert(ent)
+
+	// Удаление лишних элементов
+	s.evictOverflow(ent)
+	s.notifyPut(ent)
+
+	return ent, nil
+}
+
+// nextVersion возвращает версию для очередной записи значения. Ключ всегда попадает в один
+// и тот же шард, поэтому версии записи по ключу растут даже после ее удаления и повторной записи.
+func (s *shard[K, V]) nextVersion() uint64 {
+	s.version++
+	return s.version
+}
+
+// full сообщает, потребуется ли вытеснение для добавления новой записи стоимостью cost
+func (s *shard[K, V]) full(cost int64) bool {
+	return len(s.items) >= s.size || (s.maxCost > 0 && s.cost+cost > s.maxCost)
+}
+
+// recordAccess учитывает обращение к ключу в фильтре допуска, если он задан
+func (s *shard[K, V]) recordAccess(key K) {
+	if s.admission != nil {
+		s.admission.record(key)
+	}
+}
+
+// applyReads применяет накопленные обращения на чтение к порядку использования, политике
+// вытеснения и фильтру допуска. Обращения к уже удаленным элементам пропускаются.
+func (s *shard[K, V]) applyReads() {
+	s.reads.drain(func(ent *list.Entry[K, V]) {
+		if s.items[ent.Key] == ent {
+			s.access(ent)
+		}
+	})
+}
+
+// access учитывает обращение на чтение к элементу
+func (s *shard[K, V]) access(ent *list.Entry[K, V]) {
+	if s.promote {
+		s.evictList.MoveToFront(ent)
+	}
+	s.policy.OnAccess(ent)
+	s.recordAccess(ent.Key)
+}
+
+// slide продлевает TTL элемента со скользящим истечением, отсчитывая его от now
+func (s *shard[K, V]) slide(ent *list.Entry[K, V], now time.Time) {
+	if ent.Sliding && ent.TTL > 0 {
+		s.setExpiresAt(ent, now.Add(ent.TTL))
+	}
+}
+
+// evictOverflow вытесняет выбранные политикой элементы, кроме protected, пока шард
+// не уложится в лимиты по количеству записей и по их суммарной стоимости
+func (s *shard[K, V]) evictOverflow(protected *list.Entry[K, V]) {
+	for s.evictList.Length() > s.size || (s.maxCost > 0 && s.cost > s.maxCost) {
+		victim := s.policy.Victim(protected)
+		if victim == nil {
+			return
+		}
+		if observer, ok := s.policy.(EvictionObserver[K, V]); ok {
+			observer.OnEvict(victim)
+		}
+		s.removeElement(victim)
+	}
+}
+
+// setExpiresAt меняет дату истечения элемента и его положение в очереди истечения.
+// Неистекающие элементы в очереди не хранятся.
+func (s *shard[K, V]) setExpiresAt(ent *list.Entry[K, V], expiresAt time.Time) {
+	wasQueued := !ent.ExpiresAt.IsZero()
+	ent.ExpiresAt = expiresAt
+
+	switch {
+	case wasQueued && expiresAt.IsZero():
+		s.expiry.Remove(ent)
+	case wasQueued:
+		s.expiry.Fix(ent)
+	case !expiresAt.IsZero():
+		s.expiry.Push(ent)
+	}
+}
+
+// get возвращает элемент, который на момент now еще можно отдать (в том числе как устаревший).
+// Если элемента нет, возвращает ErrNotFound, а если он истек, но еще не удален - ErrExpired.
+func (s *shard[K, V]) get(key K, now time.Time) (*list.Entry[K, V], error) {
+	ent, ok := s.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if ent.Dead(now) {
+		return nil, ErrExpired
+	}
+	return ent, nil
+}
+
+// appendAll дописывает в слайсы все не истекшие на момент now пары ключ-значение,
+// начиная со старейшей
+func (s *shard[K, V]) appendAll(keys []K, values []V, now time.Time) ([]K, []V) {
+	for ent := s.evictList.Back(); ent != nil; ent = ent.PrevEntry() {
+		// Дополнительная проверка на expired
+		if ent.Expired(now) {
+			continue
+		}
+
+		keys = append(keys, ent.Key)
+		values = append(values, ent.Value)
+	}
+	return keys, values
+}
+
+// clear очищает шард
+func (s *shard[K, V]) clear() {
+	// Очистка мапы значений
+	for k := range s.items {
+		delete(s.items, k)
+	}
+
+	// Очистка двухсвязного списка и очереди истечения
+	s.evictList.Init()
+	s.expiry.Init()
+	if s.index != nil {
+		s.index = radix.New[*list.Entry[K, V]]()
+	}
+	s.tags = nil
+	s.policy.Reset()
+	s.reads.reset()
+	s.cost = 0
+}
+
+// evict удаляет элемент по запросу пользователя и, в отличие от вытеснения и удаления истекших
+// записей, сообщает об удалении наблюдателю
+func (s *shard[K, V]) evict(e *list.Entry[K, V]) {
+	s.removeElement(e)
+	if s.observer != nil {
+		s.observer(Mutation[K, V]{Kind: MutationEvict, Record: Record[K, V]{Key: e.Key}})
+	}
+}
+
+// notifyPut сообщает наблюдателю о записи элемента
+func (s *shard[K, V]) notifyPut(e *list.Entry[K, V]) {
+	if s.observer != nil {
+		s.observer(Mutation[K, V]{Kind: MutationPut, Record: recordOf(e)})
+	}
+}
+
+// removeElement удаляет указанный элемент
+func (s *shard[K, V]) removeElement(e *list.Entry[K, V]) {
+	s.evictList.Remove(e)  // удаление из списка
+	s.expiry.Remove(e)     // удаление из очереди истечения
+	delete(s.items, e.Key) // удаление из мапы
+	s.policy.OnRemove(e)   // удаление из политики вытеснения
+	s.cost -= e.Cost
+
+	if s.index != nil {
+		s.index.Delete(s.indexKey(e.Key)) // удаление из индекса ключей
+	}
+	s.untag(e) // удаление из индекса тегов
+}
+
+// deleteExpired вызывается специальной горутиной для удаления expired элементов.
+//
+// Элементы берутся из очереди истечения, начиная с ближайшего к удалению, поэтому проход
+// затрагивает только действительно истекшие элементы, но не более budget штук за раз.
+// Устаревшие элементы (см. StaleTTL) удаляются только после окончания срока устаревания.
+func (s *shard[K, V]) deleteExpired(budget int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for i := 0; i < budget; i++ {
+		ent := s.expiry.Front()
+		if ent == nil || !ent.Dead(now) {
+			break
+		}
+
+		s.removeElement(ent)
+	}
+}
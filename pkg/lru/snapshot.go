@@ -0,0 +1,116 @@
+package lru
+
+import (
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// Record описывает запись кэша вместе с параметрами, с которыми она была сохранена.
+// Используется для сохранения содержимого кэша и его восстановления (см. Export и Import).
+type Record[K comparable, V any] struct {
+	Key          K
+	Value        V
+	ExpiresAt    time.Time     // Дата истечения, нулевая - запись не истекает
+	TTL          time.Duration // TTL, с которым запись была сохранена (для скользящего истечения)
+	Sliding      bool
+	StaleTTL     time.Duration
+	RefreshAhead time.Duration
+	Tags         []string
+}
+
+// Export вызывает fn для всех записей кэша, которые еще можно отдать (в том числе устаревших, см. StaleTTL).
+// Шарды обходятся по очереди, внутри шарда записи следуют от давно не использовавшихся к недавно
+// использованным, поэтому Import записей в том же порядке восстанавливает порядок использования.
+//
+// Записи шарда копируются под блокировкой на чтение, а fn вызывается уже после ее снятия, поэтому
+// медленная запись результата (например, на диск) не блокирует запись в кэш. Ошибка fn прерывает обход
+// и возвращается вызывающему.
+func (c *Cache[K, V]) Export(fn func(rec Record[K, V]) error) error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	var records []Record[K, V]
+	for _, s := range c.shards {
+		records = records[:0]
+
+		s.mu.RLock()
+		now := time.Now()
+		for ent := s.evictList.Back(); ent != nil; ent = ent.PrevEntry() {
+			if ent.Dead(now) {
+				continue
+			}
+			records = append(records, recordOf(ent))
+		}
+		s.mu.RUnlock()
+
+		for _, rec := range records {
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Import записывает запись с сохраненными датой истечения и параметрами, как если бы она была
+// записана через Put в момент сохранения. Запись, срок которой (с учетом StaleTTL) уже прошел,
+// не записывается, а Import возвращает ErrExpired.
+//
+// Записанная последней запись считается недавно использованной, поэтому записи нужно передавать
+// в порядке Export. Фильтр допуска не применяется: восстановленная запись всегда попадает в кэш,
+// при переполнении вытесняя записанные раньше.
+func (c *Cache[K, V]) Import(rec Record[K, V]) error {
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	if rec.TTL < 0 {
+		return ErrInvalidTTL
+	}
+
+	cfg := entrySettings{
+		sliding:      rec.Sliding,
+		staleTTL:     rec.StaleTTL,
+		refreshAhead: rec.RefreshAhead,
+	}
+	if len(rec.Tags) > 0 {
+		cfg.tags = uniqueTags(rec.Tags)
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	if rec.dead(time.Now()) {
+		return ErrExpired
+	}
+
+	cost := c.entryCost(rec.Key, rec.Value)
+
+	s := c.shard(rec.Key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.store(rec.Key, rec.Value, cost, rec.ExpiresAt, rec.TTL, cfg, false)
+	return err
+}
+
+// recordOf возвращает запись кэша, соответствующую элементу ent
+func recordOf[K comparable, V any](ent *list.Entry[K, V]) Record[K, V] {
+	return Record[K, V]{
+		Key:          ent.Key,
+		Value:        ent.Value,
+		ExpiresAt:    ent.ExpiresAt,
+		TTL:          ent.TTL,
+		Sliding:      ent.Sliding,
+		StaleTTL:     ent.StaleTTL,
+		RefreshAhead: ent.RefreshAhead,
+		Tags:         ent.Tags,
+	}
+}
+
+// dead сообщает, прошел ли на момент now срок записи с учетом StaleTTL (см. list.Entry.Dead)
+func (rec Record[K, V]) dead(now time.Time) bool {
+	return !rec.ExpiresAt.IsZero() && now.After(rec.ExpiresAt.Add(rec.StaleTTL))
+}
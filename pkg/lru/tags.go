@@ -0,0 +1,85 @@
+package lru
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitbogit/golang-cache-lru/pkg/lru/list"
+)
+
+// EvictByTag удаляет все записи, помеченные тегом tag (см. Tags), и возвращает количество
+// удаленных не истекших записей. Каждый шард ведет обратный индекс тегов, который очищается
+// при вытеснении, истечении и перезаписи записей, поэтому работа пропорциональна количеству
+// удаляемых записей. Шарды блокируются по очереди, каждый один раз.
+func (c *Cache[K, V]) EvictByTag(tag string) (n int, err error) {
+	if c.closed.Load() {
+		return 0, ErrClosed
+	}
+	if len(tag) == 0 {
+		return 0, fmt.Errorf("%w: tag can not be empty", ErrInvalidOption)
+	}
+
+	var matched []*list.Entry[K, V]
+	for _, s := range c.shards {
+		s.mu.Lock()
+		now := time.Now()
+
+		// Индекс меняется при удалении, поэтому записи сначала собираются
+		matched = matched[:0]
+		for ent := range s.tags[tag] {
+			matched = append(matched, ent)
+		}
+		for _, ent := range matched {
+			if !ent.Dead(now) {
+				n++
+			}
+			s.evict(ent)
+		}
+		s.mu.Unlock()
+	}
+
+	return n, nil
+}
+
+// tag добавляет элемент в индекс тегов шарда
+func (s *shard[K, V]) tag(ent *list.Entry[K, V]) {
+	if len(ent.Tags) == 0 {
+		return
+	}
+	if s.tags == nil {
+		s.tags = make(map[string]map[*list.Entry[K, V]]struct{})
+	}
+	for _, tag := range ent.Tags {
+		entries, ok := s.tags[tag]
+		if !ok {
+			entries = make(map[*list.Entry[K, V]]struct{})
+			s.tags[tag] = entries
+		}
+		entries[ent] = struct{}{}
+	}
+}
+
+// untag удаляет элемент из индекса тегов шарда
+func (s *shard[K, V]) untag(ent *list.Entry[K, V]) {
+	for _, tag := range ent.Tags {
+		entries := s.tags[tag]
+		delete(entries, ent)
+		if len(entries) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+// uniqueTags возвращает теги без повторов, сохраняя порядок
+func uniqueTags(tags []string) []string {
+	unique := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		unique = append(unique, tag)
+	}
+	return unique
+}
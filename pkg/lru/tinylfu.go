@@ -0,0 +1,150 @@
+package lru
+
+const (
+	sketchDepth      = 4  // Количество строк count-min sketch
+	sketchMaxCounter = 15 // Максимальное значение счетчика (4 бита, как в W-TinyLFU)
+	doorkeeperHashes = 3  // Количество хэш-функций doorkeeper
+	agingFactor      = 10 // Сброс частот происходит после agingFactor * capacity обращений
+)
+
+// tinyLFU реализует фильтр допуска W-TinyLFU: при переполнении шарда новая запись допускается,
+// только если по оценке к ней обращаются чаще, чем к записи, которую пришлось бы вытеснить.
+//
+// Частоты оцениваются count-min sketch, перед которым стоит doorkeeper (фильтр Блума), отсекающий
+// ключи, встреченные лишь однажды. Чтобы оценки отражали недавнюю популярность, после заданного
+// количества обращений все счетчики уменьшаются вдвое, а doorkeeper очищается.
+type tinyLFU[K comparable] struct {
+	hasher func(K) uint64
+
+	sketch [sketchDepth][]uint8
+	mask   uint64 // ширина строки sketch минус 1 (ширина - степень двойки)
+
+	door     []uint64 // биты doorkeeper
+	doorMask uint64   // количество битов doorkeeper минус 1
+
+	samples   int // количество обращений, после которого выполняется сброс частот
+	additions int // количество обращений с последнего сброса
+
+	admitted uint64 // количество допущенных новых записей при переполнении
+	rejected uint64 // количество отклоненных новых записей
+}
+
+// newTinyLFU создает фильтр допуска для шарда вместимостью capacity записей
+func newTinyLFU[K comparable](capacity int, hasher func(K) uint64) *tinyLFU[K] {
+	width := nextPowerOfTwo(uint64(capacity))
+	if width < 16 {
+		width = 16
+	}
+
+	t := &tinyLFU[K]{
+		hasher:   hasher,
+		mask:     width - 1,
+		door:     make([]uint64, width/8), // по 8 бит на ожидаемую запись
+		doorMask: width*8 - 1,
+		samples:  agingFactor * capacity,
+	}
+	for i := range t.sketch {
+		t.sketch[i] = make([]uint8, width)
+	}
+
+	return t
+}
+
+// record учитывает обращение к ключу
+func (t *tinyLFU[K]) record(key K) {
+	h := t.hasher(key)
+
+	// Первое обращение только отмечается в doorkeeper, в sketch попадают повторные
+	if t.doorAdd(h) {
+		for i := range t.sketch {
+			idx := t.index(h, i)
+			if t.sketch[i][idx] < sketchMaxCounter {
+				t.sketch[i][idx]++
+			}
+		}
+	}
+
+	t.additions++
+	if t.additions >= t.samples {
+		t.reset()
+	}
+}
+
+// estimate возвращает оценку частоты обращений к ключу
+func (t *tinyLFU[K]) estimate(key K) uint8 {
+	h := t.hasher(key)
+
+	min := uint8(sketchMaxCounter)
+	for i := range t.sketch {
+		if v := t.sketch[i][t.index(h, i)]; v < min {
+			min = v
+		}
+	}
+
+	if t.doorContains(h) {
+		min++
+	}
+	return min
+}
+
+// admit решает, допустить ли новую запись candidate ценой вытеснения victim, и учитывает решение
+func (t *tinyLFU[K]) admit(candidate K, victim K) bool {
+	if t.estimate(candidate) > t.estimate(victim) {
+		t.admitted++
+		return true
+	}
+
+	t.rejected++
+	return false
+}
+
+// reset уменьшает вдвое все счетчики и очищает doorkeeper
+func (t *tinyLFU[K]) reset() {
+	for i := range t.sketch {
+		for j := range t.sketch[i] {
+			t.sketch[i][j] >>= 1
+		}
+	}
+	for i := range t.door {
+		t.door[i] = 0
+	}
+	t.additions = 0
+}
+
+// index возвращает позицию ключа с хэшем h в строке row
+func (t *tinyLFU[K]) index(h uint64, row int) uint64 {
+	return mix(h+uint64(row)*0x9e3779b97f4a7c15) & t.mask
+}
+
+// doorAdd отмечает хэш в doorkeeper и сообщает, был ли он там раньше
+func (t *tinyLFU[K]) doorAdd(h uint64) bool {
+	present := true
+	for i := 0; i < doorkeeperHashes; i++ {
+		bit := mix(h^uint64(i+1)*0xbf58476d1ce4e5b9) & t.doorMask
+		if t.door[bit/64]&(1<<(bit%64)) == 0 {
+			present = false
+			t.door[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return present
+}
+
+// doorContains сообщает, отмечен ли хэш в doorkeeper
+func (t *tinyLFU[K]) doorContains(h uint64) bool {
+	for i := 0; i < doorkeeperHashes; i++ {
+		bit := mix(h^uint64(i+1)*0xbf58476d1ce4e5b9) & t.doorMask
+		if t.door[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nextPowerOfTwo возвращает ближайшую степень двойки, не меньшую x
+func nextPowerOfTwo(x uint64) uint64 {
+	p := uint64(1)
+	for p < x {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,224 @@
+package lru
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TxnOpType задает тип операции транзакции
+type TxnOpType int
+
+const (
+	TxnPut    TxnOpType = iota // Запись значения, как Put
+	TxnEvict                   // Удаление записи, как Evict
+	TxnUpdate                  // Вычисление нового значения по текущему, как Update
+)
+
+// TxnOp описывает одну операцию транзакции
+type TxnOp[K comparable, V any] struct {
+	Type    TxnOpType
+	Key     K
+	Value   V             // Значение для TxnPut
+	TTL     time.Duration // TTL для TxnPut и для записей, создаваемых TxnUpdate
+	Options []PutOption   // Параметры записи для TxnPut и для записей, создаваемых TxnUpdate
+	Update  UpdateFunc[V] // Функция вычисления значения для TxnUpdate
+}
+
+// TxnCondType задает тип условия транзакции
+type TxnCondType int
+
+const (
+	TxnExists  TxnCondType = iota // Запись есть и не истекла
+	TxnAbsent                     // Записи нет или она истекла
+	TxnVersion                    // Запись есть, не истекла и ее версия равна Version
+)
+
+// TxnCond описывает одно условие транзакции
+type TxnCond[K comparable] struct {
+	Type    TxnCondType
+	Key     K
+	Version uint64 // Ожидаемая версия для TxnVersion
+}
+
+// TxnResult описывает результат одной операции транзакции
+type TxnResult[V any] struct {
+	Item Item[V] // Записанная запись для TxnPut и TxnUpdate, удаленное значение для TxnEvict
+	Err  error   // ErrNotFound или ErrExpired для TxnEvict отсутствующей или истекшей записи
+}
+
+// TxnError описывает причину отмены транзакции
+type TxnError struct {
+	Cond int   // Номер невыполненного условия или -1
+	Op   int   // Номер операции, завершившейся ошибкой, или -1
+	Err  error // ErrConditionFailed или ошибка операции
+}
+
+// Error возвращает описание причины отмены транзакции
+func (e *TxnError) Error() string {
+	if e.Cond >= 0 {
+		return fmt.Sprintf("lru: transaction condition %d: %v", e.Cond, e.Err)
+	}
+	return fmt.Sprintf("lru: transaction op %d: %v", e.Op, e.Err)
+}
+
+// Unwrap возвращает ошибку условия или операции
+func (e *TxnError) Unwrap() error {
+	return e.Err
+}
+
+// txnView описывает состояние ключа с учетом уже обработанных операций транзакции
+type txnView[V any] struct {
+	item Item[V]
+	err  error
+}
+
+// Txn атомарно проверяет условия conds и выполняет операции ops по порядку. Возвращает результаты
+// операций на позициях соответствующих операций.
+//
+// Транзакция выполняется целиком или не выполняется вовсе: шарды всех затронутых ключей блокируются
+// на время транзакции (в порядке номеров, как в lockAll), затем проверяются условия и вычисляются все
+// операции, и только если ни одна не завершилась ошибкой, изменения применяются к кэшу. Иначе кэш не
+// меняется, а Txn возвращает *TxnError с номером невыполненного условия (ErrConditionFailed)
+// или операции. Удаление отсутствующей записи не отменяет транзакцию и отражается в ее результате.
+//
+// Записи транзакции не проходят фильтр допуска, но, как и при Put, могут вытеснить другие записи
+// шарда при его переполнении. Функции TxnUpdate не должны обращаться к кэшу.
+func (c *Cache[K, V]) Txn(conds []TxnCond[K], ops []TxnOp[K, V]) ([]TxnResult[V], error) {
+	if c.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	// Блокировка затронутых шардов в порядке номеров
+	touched := make(map[int]struct{})
+	for _, cond := range conds {
+		touched[c.shardIndex(cond.Key)] = struct{}{}
+	}
+	for _, op := range ops {
+		touched[c.shardIndex(op.Key)] = struct{}{}
+	}
+	shardIdxs := make([]int, 0, len(touched))
+	for shardIdx := range touched {
+		shardIdxs = append(shardIdxs, shardIdx)
+	}
+	sort.Ints(shardIdxs)
+
+	for _, shardIdx := range shardIdxs {
+		c.shards[shardIdx].mu.Lock()
+	}
+	defer func() {
+		for i := len(shardIdxs) - 1; i >= 0; i-- {
+			c.shards[shardIdxs[i]].mu.Unlock()
+		}
+	}()
+
+	now := time.Now()
+
+	// Проверка условий
+	for i, cond := range conds {
+		ent, err := c.shard(cond.Key).get(cond.Key, now)
+		alive := err == nil && !ent.Expired(now)
+
+		var ok bool
+		switch cond.Type {
+		case TxnExists:
+			ok = alive
+		case TxnAbsent:
+			ok = !alive
+		case TxnVersion:
+			ok = alive && ent.Version == cond.Version
+		}
+		if !ok {
+			return nil, &TxnError{Cond: i, Op: -1, Err: ErrConditionFailed}
+		}
+	}
+
+	// Вычисление операций без изменения кэша
+	values, costs, cfgs := make([]V, len(ops)), make([]int64, len(ops)), make([]entrySettings, len(ops))
+	views := make(map[K]txnView[V])
+	view := func(key K) txnView[V] {
+		if v, ok := views[key]; ok {
+			return v
+		}
+		ent, err := c.shard(key).get(key, now)
+		if err == nil && ent.Expired(now) {
+			err = ErrExpired
+		}
+		if err != nil {
+			return txnView[V]{err: err}
+		}
+		return txnView[V]{item: itemOf(ent, now)}
+	}
+
+	for i, op := range ops {
+		if op.Type == TxnEvict {
+			views[op.Key] = txnView[V]{err: ErrNotFound}
+			continue
+		}
+
+		cfgs[i] = newEntrySettings(op.Options)
+		if op.TTL < 0 {
+			return nil, &TxnError{Cond: -1, Op: i, Err: ErrInvalidTTL}
+		}
+		if err := cfgs[i].validate(); err != nil {
+			return nil, &TxnError{Cond: -1, Op: i, Err: err}
+		}
+
+		switch op.Type {
+		case TxnPut:
+			values[i] = op.Value
+		case TxnUpdate:
+			current := view(op.Key)
+			value, err := op.Update(current.item, current.err)
+			if err != nil {
+				return nil, &TxnError{Cond: -1, Op: i, Err: err}
+			}
+			values[i] = value
+		default:
+			return nil, &TxnError{Cond: -1, Op: i, Err: ErrInvalidOption}
+		}
+
+		costs[i] = c.entryCost(op.Key, values[i])
+		if s := c.shard(op.Key); s.maxCost > 0 && costs[i] > s.maxCost {
+			return nil, &TxnError{Cond: -1, Op: i, Err: ErrTooLarge}
+		}
+		views[op.Key] = txnView[V]{item: Item[V]{Value: values[i]}}
+	}
+
+	// Применение операций
+	results := make([]TxnResult[V], len(ops))
+	for i, op := range ops {
+		s := c.shard(op.Key)
+
+		if op.Type == TxnEvict {
+			ent, ok := s.items[op.Key]
+			switch {
+			case !ok:
+				results[i].Err = ErrNotFound
+			case ent.Dead(now):
+				s.evict(ent)
+				results[i].Err = ErrExpired
+			default:
+				s.evict(ent)
+				results[i].Item = Item[V]{Value: ent.Value}
+			}
+			continue
+		}
+
+		ttl, cfg := op.TTL, cfgs[i]
+		if ttl == 0 {
+			ttl = c.defaultTTL
+		}
+		expiresAt := c.expiresAt(now, ttl)
+
+		// Как и Update, обновление существующей записи сохраняет ее дату истечения и параметры
+		if ent, err := s.get(op.Key, now); op.Type == TxnUpdate && err == nil && !ent.Expired(now) {
+			expiresAt, ttl, cfg = ent.ExpiresAt, ent.TTL, entrySettingsOf(ent)
+		}
+
+		ent, _ := s.store(op.Key, values[i], costs[i], expiresAt, ttl, cfg, false)
+		results[i].Item = itemOf(ent, now)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,65 @@
+package lru
+
+import "time"
+
+// UpdateFunc вычисляет новое значение записи по текущему. item и err - результат поиска записи:
+// для отсутствующих и истекших записей err равна ErrNotFound или ErrExpired, а item пустой.
+// Ненулевая ошибка отменяет запись и возвращается из Update.
+type UpdateFunc[V any] func(item Item[V], err error) (V, error)
+
+// Update атомарно вычисляет новое значение записи функцией fn и записывает его в кэш,
+// возвращая записанную запись. Чтение, вычисление и запись выполняются под блокировкой шарда,
+// поэтому одновременные обновления одного ключа не теряются; fn не должна обращаться к кэшу.
+//
+// Существующая запись сохраняет дату истечения и параметры, с которыми была записана.
+// ttl и opts применяются, только если записи нет или она истекла (в том числе устаревшие
+// записи, см. StaleTTL), и запись создается заново. Создаваемая запись, как и при Put, может быть
+// отклонена фильтром допуска (см. WithAdmission) с ErrRejected.
+func (c *Cache[K, V]) Update(key K, fn UpdateFunc[V], ttl time.Duration, opts ...PutOption) (item Item[V], err error) {
+	if c.closed.Load() {
+		return item, ErrClosed
+	}
+	if ttl < 0 {
+		return item, ErrInvalidTTL
+	}
+	cfg := newEntrySettings(opts)
+	if err := cfg.validate(); err != nil {
+		return item, err
+	}
+
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	ent, err := s.get(key, now)
+	if err == nil && ent.Expired(now) {
+		ent, err = nil, ErrExpired
+	}
+
+	var current Item[V]
+	if ent != nil {
+		current = itemOf(ent, now)
+	}
+	value, err := fn(current, err)
+	if err != nil {
+		return item, err
+	}
+
+	expiresAt := c.expiresAt(now, ttl)
+	if ent != nil {
+		expiresAt, ttl, cfg = ent.ExpiresAt, ent.TTL, entrySettingsOf(ent)
+	}
+
+	// Новое значение известно только под блокировкой, поэтому и стоимость оценивается под ней
+	ent, err = s.put(key, value, c.entryCost(key, value), expiresAt, ttl, cfg)
+	if ent == nil {
+		return item, err
+	}
+
+	return itemOf(ent, now), nil
+}